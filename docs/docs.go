@@ -15,6 +15,254 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Возвращает открытые ключи (JWKS) для проверки access-токенов. Симметричные ключи не публикуются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Открытые ключи подписи токенов",
+                "responses": {
+                    "200": {
+                        "description": "Набор ключей",
+                        "schema": {
+                            "$ref": "#/definitions/auth.JWKS"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/mail/status": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает число писем в каждом статусе очереди, возраст самого старого неотправленного письма, время последней отправки и последние письма. Тело писем не возвращается.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Состояние исходящей почты",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Количество последних писем, не больше 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Состояние очереди",
+                        "schema": {
+                            "$ref": "#/definitions/response.MailStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный лимит",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении состояния",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/mail/test": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Проверяет почтовый транспорт: для SMTP — подключение, EHLO, STARTTLS и авторизацию на отдельном соединении. Если указан адрес, отправляет на него тестовое письмо в обход очереди. Доступно только администраторам.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Диагностика почты",
+                "parameters": [
+                    {
+                        "description": "Адрес для тестового письма",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/email.MailTestInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Все шаги выполнены успешно",
+                        "schema": {
+                            "$ref": "#/definitions/response.MailDiagnosticsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный адрес",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Один из шагов завершился ошибкой",
+                        "schema": {
+                            "$ref": "#/definitions/response.MailDiagnosticsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/outbox": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает последние сообщения очереди отправки, по умолчанию недоставленные (dead). Тело писем не возвращается.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Сообщения outbox",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "dead",
+                        "description": "Статус: pending, sent или dead",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Количество сообщений, не больше 500",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сообщения",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.OutboxMessageResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный статус или лимит",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении сообщений",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/outbox/{id}/resend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает недоставленное сообщение (dead) в очередь со сброшенным счётчиком попыток",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Повторная отправка сообщения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сообщения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сообщение поставлено в очередь",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Сообщение не в статусе dead",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при постановке в очередь",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/admin/users": {
             "get": {
                 "security": [
@@ -174,9 +422,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/register": {
+        "/auth/logout": {
             "post": {
-                "description": "Регистрирует нового пользователя с указанием имени, почты, пароля и телефона",
+                "description": "Отзывает сессию refresh-токена. Access-токены этой сессии перестают приниматься сразу.",
                 "consumes": [
                     "application/json"
                 ],
@@ -186,23 +434,23 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Регистрация пользователя",
+                "summary": "Выход",
                 "parameters": [
                     {
-                        "description": "Данные для регистрации",
+                        "description": "Refresh-токен",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/auth.RegisterInput"
+                            "$ref": "#/definitions/auth.RefreshInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Регистрация успешна",
+                    "200": {
+                        "description": "Сессия завершена",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "$ref": "#/definitions/response.MessageResponse"
                         }
                     },
                     "400": {
@@ -211,14 +459,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Почта или телефон уже зарегистрированы",
+                    "401": {
+                        "description": "Refresh-токен недействителен или отозван",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Не удалось хешировать пароль или создать пользователя",
+                        "description": "Не удалось завершить сессию",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -226,9 +474,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/reset-password": {
+        "/auth/refresh": {
             "post": {
-                "description": "Обрабатывает запрос на сброс пароля пользователя с использованием токена",
+                "description": "Обменивает refresh-токен на новую пару токенов. Старый refresh-токен перестаёт действовать; его повторное использование отзывает все сессии пользователя.",
                 "consumes": [
                     "application/json"
                 ],
@@ -238,61 +486,49 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Сброс пароля",
+                "summary": "Обновление токенов",
                 "parameters": [
                     {
-                        "description": "Данные для сброса пароля",
+                        "description": "Refresh-токен",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/auth.ResetPasswordInput"
+                            "$ref": "#/definitions/auth.RefreshInput"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Сообщение об успешном сбросе пароля",
+                        "description": "Новая пара токенов",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/response.TokenResponse"
                         }
                     },
                     "400": {
-                        "description": "Ошибка валидации или истекший токен",
+                        "description": "Описание ошибки валидации",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Неверный токен",
+                    "401": {
+                        "description": "Refresh-токен недействителен или отозван",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Внутренняя ошибка сервера",
+                        "description": "Не удалось обновить сессию",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/reset-password-request": {
+        "/auth/register": {
             "post": {
-                "description": "Отправляет письмо со ссылкой для сброса пароля на указанный email",
+                "description": "Регистрирует нового пользователя с указанием имени, почты, пароля и телефона",
                 "consumes": [
                     "application/json"
                 ],
@@ -302,22 +538,138 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Запрос на сброс пароля",
+                "summary": "Регистрация пользователя",
                 "parameters": [
                     {
-                        "description": "Email пользователя",
+                        "description": "Данные для регистрации",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/auth.ResetPasswordRequestInput"
+                            "$ref": "#/definitions/auth.RegisterInput"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Письмо с инструкцией отправлено",
-                        "schema": {
+                    "201": {
+                        "description": "Регистрация успешна",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Описание ошибки валидации",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Почта или телефон уже зарегистрированы",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Не удалось хешировать пароль или создать пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Обрабатывает запрос на сброс пароля пользователя с использованием токена",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Сброс пароля",
+                "parameters": [
+                    {
+                        "description": "Данные для сброса пароля",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ResetPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сообщение об успешном сбросе пароля",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации или истекший токен",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Неверный токен",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password-request": {
+            "post": {
+                "description": "Отправляет письмо со ссылкой для сброса пароля на указанный email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Запрос на сброс пароля",
+                "parameters": [
+                    {
+                        "description": "Email пользователя",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ResetPasswordRequestInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Письмо с инструкцией отправлено",
+                        "schema": {
                             "$ref": "#/definitions/response.MessageResponse"
                         }
                     },
@@ -463,9 +815,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "201": {
-                        "description": "Данные о бранировании",
+                        "description": "Данные о бронировании и ссылка на оплату",
                         "schema": {
-                            "$ref": "#/definitions/response.BookingResponse"
+                            "$ref": "#/definitions/response.CreatedBookingResponse"
                         }
                     },
                     "400": {
@@ -491,6 +843,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
+                    },
+                    "502": {
+                        "description": "Ошибка платежной системы, бронирование отменено",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
                     }
                 }
             }
@@ -584,7 +942,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Ошибка при проверке доступности номера или при создании бронирования",
+                        "description": "Ошибка при поиске пользователя или при создании бронирования",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -599,7 +957,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Отмена бронирования пользователем",
+                "description": "Отмена бронирования пользователем. Неоплаченное бронирование отменяется бесплатно, по оплаченному возвращается сумма по политике отмены отеля или номера.",
                 "produces": [
                     "application/json"
                 ],
@@ -620,11 +978,11 @@ const docTemplate = `{
                     "200": {
                         "description": "Бронирование успешно отменено",
                         "schema": {
-                            "$ref": "#/definitions/response.MessageResponse"
+                            "$ref": "#/definitions/response.CancellationResponse"
                         }
                     },
                     "400": {
-                        "description": "Бронирование уже оплачено и не может быть отменено",
+                        "description": "Бронирование в этом статусе не может быть отменено",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -641,37 +999,46 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Статус бронирования изменился",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Ошибка при отмене бронирования",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
+                    },
+                    "502": {
+                        "description": "Ошибка платежной системы при возврате",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/bookings/{id}/pay": {
-            "post": {
+        "/bookings/{id}/events": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Создает платеж через YooKassa для указанного бронирования и возвращает ссылку для оплаты.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Возвращает журнал переходов бронирования: кто, когда и почему менял статус",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "payments"
+                    "bookings"
                 ],
-                "summary": "Создание платежа для бронирования",
+                "summary": "История статусов бронирования",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Идентификатор бронирования",
+                        "description": "ID бронирования",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -679,13 +1046,16 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Ссылка для оплаты успешно создана",
+                        "description": "Журнал переходов",
                         "schema": {
-                            "$ref": "#/definitions/response.CreatePaymentResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.BookingEventResponse"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Некорректный запрос или бронирование уже оплачено",
+                    "403": {
+                        "description": "Доступ запрещен",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -697,7 +1067,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Внутренняя ошибка сервера или ошибка платежной системы",
+                        "description": "Ошибка при получении истории",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -705,14 +1075,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/bookings/{id}/refund": {
+        "/bookings/{id}/pay": {
             "post": {
                 "security": [
                     {
-                        "ApiKeyAuth": []
+                        "BearerAuth": []
                     }
                 ],
-                "description": "Обрабатывает запрос на возврат платежа за бронирование. Проверяет права доступа пользователя, статус оплаты и выполняет возврат через API YooKassa.",
+                "description": "Создает платеж через платежный провайдер для указанного бронирования и возвращает ссылку для оплаты.",
                 "consumes": [
                     "application/json"
                 ],
@@ -722,11 +1092,11 @@ const docTemplate = `{
                 "tags": [
                     "payments"
                 ],
-                "summary": "Обработка возврата платежа",
+                "summary": "Создание платежа для бронирования",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID бронирования",
+                        "type": "integer",
+                        "description": "Идентификатор бронирования",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -734,19 +1104,19 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Оплата отменена",
+                        "description": "Ссылка для оплаты успешно создана",
                         "schema": {
-                            "$ref": "#/definitions/response.MessageResponse"
+                            "$ref": "#/definitions/response.CreatePaymentResponse"
                         }
                     },
                     "400": {
-                        "description": "Бронирование не оплачено или ID платежа отсутствует",
+                        "description": "Некорректный запрос или бронирование уже оплачено",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "У вас нет прав на отмену этого бронирования",
+                        "description": "Бронирование не принадлежит вам",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -757,18 +1127,35 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Время на оплату бронирования истекло",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
+                    },
+                    "502": {
+                        "description": "Ошибка платежной системы",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/email/test": {
-            "get": {
-                "description": "Отправляет тестовое письмо на указанную почту.",
+        "/bookings/{id}/refund": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отменяет оплаченное бронирование и возвращает сумму по политике отмены отеля или номера. Проверяет права доступа пользователя и статус оплаты.",
                 "consumes": [
                     "application/json"
                 ],
@@ -776,33 +1163,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "email"
+                    "payments"
                 ],
-                "summary": "Отправка тестового письма",
+                "summary": "Обработка возврата платежа",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Email получателя",
-                        "name": "to",
-                        "in": "query",
+                        "description": "ID бронирования",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Тестовое письмо успешно отправлено",
+                        "description": "Оплата возвращена",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "$ref": "#/definitions/response.CancellationResponse"
                         }
                     },
                     "400": {
-                        "description": "Не указан email получателя",
+                        "description": "Бронирование не оплачено, ID платежа отсутствует или возврат не положен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "У вас нет прав на отмену этого бронирования",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Бронирование не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Статус бронирования изменился или возврат уже выполняется",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка отправки письма",
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка платежной системы при возврате",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -931,6 +1342,26 @@ const docTemplate = `{
                 }
             }
         },
+        "/healthz": {
+            "get": {
+                "description": "Отвечает, пока процесс обслуживает запросы. Внешние зависимости не проверяются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Проверка живости",
+                "responses": {
+                    "200": {
+                        "description": "Сервис работает",
+                        "schema": {
+                            "$ref": "#/definitions/response.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/hotels": {
             "get": {
                 "description": "Возвращает список всех отелей, включая связанные номера.",
@@ -1064,14 +1495,22 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Получение бронирований для владельца",
+                "description": "Получение бронирований отелей, которыми пользователь владеет или в штате которых состоит",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "bookings"
                 ],
-                "summary": "Получение бронирований владельца",
+                "summary": "Получение бронирований отелей",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Только бронирования этого отеля",
+                        "name": "hotel_id",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "201": {
                         "description": "Данные о бранировании",
@@ -1097,94 +1536,81 @@ const docTemplate = `{
                 }
             }
         },
-        "/owners/hotels": {
-            "get": {
+        "/owners/bookings/{id}/status": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает список отелей, принадлежащих текущему владельцу",
+                "description": "Заселение, выезд, неявка или отмена бронирования владельцем отеля или менеджером. При отмене оплаченного бронирования гостю возвращается вся оплата.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "hotels"
+                    "bookings"
                 ],
-                "summary": "Получение списка отелей владельца",
-                "responses": {
-                    "200": {
-                        "description": "Список отелей",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/response.HotelResponse"
-                            }
-                        }
-                    },
-                    "403": {
-                        "description": "Доступ запрещен",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Ошибка при получении отелей",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    }
-                }
-            },
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Обрабатывает запрос на создание нового отеля. Только владельцы могут создавать новые отели.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "hotels"
-                ],
-                "summary": "Создание отеля владельцем",
+                "summary": "Изменение статуса бронирования отелем",
                 "parameters": [
                     {
-                        "description": "Данные для создания нового отеля",
+                        "type": "integer",
+                        "description": "ID бронирования",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый статус и причина",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/hotels.CreateHotelInput"
+                            "$ref": "#/definitions/bookings.UpdateBookingStatusInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Новый отель успешно создан",
+                    "200": {
+                        "description": "Бронирование с новым статусом",
                         "schema": {
-                            "$ref": "#/definitions/response.HotelResponse"
+                            "$ref": "#/definitions/response.BookingResponse"
                         }
                     },
                     "400": {
-                        "description": "Ошибка валидации входных данных",
+                        "description": "Недопустимый статус или переход",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "Только владельцы могут создавать отели",
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Бронирование не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Статус бронирования изменился или возврат уже выполняется",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при создании отеля",
+                        "description": "Ошибка при изменении статуса",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Ошибка платежной системы при возврате",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -1192,36 +1618,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/owners/rooms": {
+        "/owners/hotels": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает список всех номеров в отелях, принадлежащих текущему владельцу",
+                "description": "Возвращает список отелей, принадлежащих текущему владельцу",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
-                ],
-                "summary": "Получение списка номеров владельца",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "ID отеля для фильтрации",
-                        "name": "hotel_id",
-                        "in": "query"
-                    }
+                    "hotels"
                 ],
+                "summary": "Получение списка отелей владельца",
                 "responses": {
                     "200": {
-                        "description": "Список номеров",
+                        "description": "Список отелей",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/response.RoomResponse"
+                                "$ref": "#/definitions/response.HotelResponse"
                             }
                         }
                     },
@@ -1232,7 +1650,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Ошибка при получении номеров",
+                        "description": "Ошибка при получении отелей",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -1245,7 +1663,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Создает новый номер в отеле. Доступно только для владельцев.",
+                "description": "Обрабатывает запрос на создание нового отеля. Только владельцы могут создавать новые отели.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1253,47 +1671,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "hotels"
                 ],
-                "summary": "Создание нового номера",
+                "summary": "Создание отеля владельцем",
                 "parameters": [
                     {
-                        "description": "Данные номера",
+                        "description": "Данные для создания нового отеля",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/hotels.CreateRoomInput"
+                            "$ref": "#/definitions/hotels.CreateHotelInput"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Созданный номер",
+                        "description": "Новый отель успешно создан",
                         "schema": {
-                            "$ref": "#/definitions/response.RoomResponse"
+                            "$ref": "#/definitions/response.HotelResponse"
                         }
                     },
                     "400": {
-                        "description": "Ошибка валидации",
+                        "description": "Ошибка валидации входных данных",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "Доступ запрещен",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Отель не найден",
+                        "description": "Только владельцы могут создавать отели",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка сервера",
+                        "description": "Ошибка при создании отеля",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -1301,49 +1713,51 @@ const docTemplate = `{
                 }
             }
         },
-        "/owners/rooms/{id}/images": {
-            "post": {
+        "/owners/hotels/{id}/cancellation-policy": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Загружает изображения для отеля",
+                "description": "Задаёт условия отмены для всех номеров отеля, у которых нет своей политики. Доступно только владельцу.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "images"
+                    "cancellation"
                 ],
-                "summary": "Загрузка изображений для отеля",
+                "summary": "Политика отмены отеля",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID номера",
+                        "description": "ID отеля",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "Изображения",
-                        "name": "images",
-                        "in": "formData",
-                        "required": true
+                        "description": "Условия отмены",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bookings.CancellationPolicyInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Изображения успешно загружены",
+                        "description": "Сохранённая политика",
                         "schema": {
-                            "$ref": "#/definitions/response.MessageResponse"
+                            "$ref": "#/definitions/response.CancellationPolicyResponse"
                         }
                     },
                     "400": {
-                        "description": "Ошибка при загрузке изображений",
+                        "description": "Ошибка валидации",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -1359,71 +1773,121 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Ошибка при сохранении политики отмены",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/owners/rooms/{room_id}/images/{image_id}": {
+            },
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаляет изображение номера",
+                "description": "Удаляет политику отмены отеля, отмена номеров без своей политики становится бесплатной. Доступно только владельцу.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "images"
+                    "cancellation"
                 ],
-                "summary": "Удаление изображения номера",
+                "summary": "Удаление политики отмены отеля",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID номера",
-                        "name": "room_id",
+                        "description": "ID отеля",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Политика отмены удалена",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageResponse"
+                        }
                     },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Политика отмены не задана",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/owners/hotels/{id}/staff": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает сотрудников отеля. Доступно только владельцу отеля.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "staff"
+                ],
+                "summary": "Штат отеля",
+                "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID изображения",
-                        "name": "image_id",
+                        "description": "ID отеля",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Изображение успешно удалено",
+                        "description": "Сотрудники отеля",
                         "schema": {
-                            "$ref": "#/definitions/response.MessageResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.HotelStaffResponse"
+                            }
                         }
                     },
                     "403": {
-                        "description": "Доступ запрещен",
+                        "description": "Отель не принадлежит вам",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Изображение не найдено",
+                        "description": "Отель не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении сотрудников",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/owners/{id}/room": {
-            "put": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Изменяет существующий номер. Доступно только для владельцев.",
+                "description": "Добавляет пользователя с ролью manager в штат отеля. Доступно только владельцу отеля.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1431,107 +1895,125 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "staff"
                 ],
-                "summary": "Изменение номера",
+                "summary": "Назначение сотрудника отеля",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID номера",
+                        "description": "ID отеля",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Данные номера",
+                        "description": "Сотрудник и его роль",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/hotels.CreateRoomInput"
+                            "$ref": "#/definitions/hotels.AddHotelStaffInput"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Номер успешно обновлен",
+                    "201": {
+                        "description": "Сотрудник назначен",
                         "schema": {
-                            "$ref": "#/definitions/response.MessageResponse"
+                            "$ref": "#/definitions/response.HotelStaffResponse"
                         }
                     },
                     "400": {
-                        "description": "Ошибка валидации",
+                        "description": "Ошибка валидации или пользователь не менеджер",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "Доступ запрещен или номер не принадлежит владельцу",
+                        "description": "Отель не принадлежит вам",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Номер не найден",
+                        "description": "Отель или пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Пользователь уже в штате отеля",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при обновлении номера",
+                        "description": "Ошибка при назначении сотрудника",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/owners/hotels/{id}/staff/{user_id}": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаляет существующий номер. Доступно только для владельцев.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Исключает пользователя из штата отеля. Доступно только владельцу отеля.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "staff"
                 ],
-                "summary": "Удаление номера",
+                "summary": "Удаление сотрудника отеля",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID номера",
+                        "description": "ID отеля",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Номер успешно удален",
+                        "description": "Сотрудник удален из штата",
                         "schema": {
                             "$ref": "#/definitions/response.MessageResponse"
                         }
                     },
+                    "400": {
+                        "description": "Неверный ID пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
                     "403": {
-                        "description": "Доступ запрещен или номер не принадлежит владельцу",
+                        "description": "Отель не принадлежит вам",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Номер не найден",
+                        "description": "Отель или сотрудник не найден",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при удалении номера",
+                        "description": "Ошибка при удалении сотрудника",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -1539,118 +2021,108 @@ const docTemplate = `{
                 }
             }
         },
-        "/payments/callback": {
-            "post": {
-                "description": "Обрабатывает уведомления от платежной системы и обновляет статус оплаты для указанного бронирования.",
-                "consumes": [
-                    "application/json"
+        "/owners/rooms": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Возвращает список номеров в отелях, которыми текущий пользователь владеет или в штате которых состоит",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "payments"
+                    "rooms"
                 ],
-                "summary": "Webhook для обработки статуса оплаты",
+                "summary": "Получение списка номеров владельца",
                 "parameters": [
                     {
-                        "description": "Данные вебхука от платежной системы",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/payments.PaymentCallbackRequest"
-                        }
+                        "type": "string",
+                        "description": "ID отеля для фильтрации",
+                        "name": "hotel_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Статус оплаты обновлен",
-                        "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Некорректные данные запроса",
+                        "description": "Список номеров",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.RoomResponse"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Бронирование не найдено",
+                    "403": {
+                        "description": "Доступ запрещен",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Внутренняя ошибка сервера",
+                        "description": "Ошибка при получении номеров",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/rooms": {
-            "get": {
-                "description": "Возвращает отфильтрованный список номеров с возможностью фильтрации по цене, вместимости, датам бронирования и отелю",
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает новый номер в отеле. Доступно только для владельцев.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "rooms"
                 ],
-                "summary": "Получение списка номеров",
+                "summary": "Создание нового номера",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Минимальная цена",
-                        "name": "min_price",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Максимальная цена",
-                        "name": "max_price",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Минимальная вместимость",
-                        "name": "capacity",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Дата начала (YYYY-MM-DD)",
-                        "name": "start_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Дата окончания (YYYY-MM-DD)",
-                        "name": "end_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "ID отеля",
-                        "name": "hotel_id",
-                        "in": "query"
+                        "description": "Данные номера",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hotels.CreateRoomInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Список номеров",
+                    "201": {
+                        "description": "Созданный номер",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/response.RoomResponse"
-                            }
+                            "$ref": "#/definitions/response.RoomResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Отель не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при получении номеров",
+                        "description": "Ошибка сервера",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -1658,16 +2130,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{id}/bookings": {
-            "get": {
-                "description": "Получение бронирований для номера",
+        "/owners/rooms/{id}/cancellation-policy": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Задаёт условия отмены для номера, например невозвратный тариф. Политика номера важнее политики отеля. Доступно только владельцу.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "cancellation"
                 ],
-                "summary": "Получение бронирований для номера",
+                "summary": "Политика отмены номера",
                 "parameters": [
                     {
                         "type": "integer",
@@ -1675,424 +2155,2224 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Условия отмены",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bookings.CancellationPolicyInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Данные о бранировании",
+                    "200": {
+                        "description": "Сохранённая политика",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/response.BookingResponse"
-                            }
+                            "$ref": "#/definitions/response.CancellationPolicyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Номер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при получении списка бронирований",
+                        "description": "Ошибка при сохранении политики отмены",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/rooms/{room_id}/rate": {
-            "get": {
-                "description": "Получает оценки номера",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет политику номера, после чего действует политика отеля. Доступно только владельцу.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "cancellation"
                 ],
-                "summary": "Получить оценки номера",
+                "summary": "Удаление политики отмены номера",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "ID номера",
-                        "name": "room_id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список оценок номера",
+                        "description": "Политика отмены удалена",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "array",
-                                "items": {
-                                    "$ref": "#/definitions/response.RoomRatingResponse"
-                                }
-                            }
+                            "$ref": "#/definitions/response.MessageResponse"
                         }
                     },
-                    "500": {
-                        "description": "Ошибка при получении оценок",
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Политика отмены не задана",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/owners/rooms/{id}/images": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Оценивает номер пользователем",
+                "description": "Загружает изображения для отеля",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "images"
                 ],
-                "summary": "Оценка номера",
+                "summary": "Загрузка изображений для отеля",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "ID номера",
-                        "name": "room_id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Рейтинг и комментарий",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/hotels.RatingInput"
-                        }
+                        "type": "file",
+                        "description": "Изображения",
+                        "name": "images",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Оценка успешно добавлена",
+                        "description": "Изображения успешно загружены",
                         "schema": {
                             "$ref": "#/definitions/response.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Недопусти рейтинг/Вы уже оценили этот номер",
+                        "description": "Ошибка при загрузке изображений",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Номер не найден",
+                        "description": "Отель не найден",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "auth.LoginInput": {
-            "type": "object",
-            "required": [
-                "email",
-                "password"
+        },
+        "/owners/rooms/{id}/price-rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает правила цены номера. Доступно только владельцу.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Правила цены номера",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Правила цены",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.PriceRuleResponse"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Номер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении правил",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет правило цены за ночь для номера: сезон, дни недели, минимальный срок проживания. Доступно только владельцу.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Создание правила цены",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Правило цены",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pricing.PriceRuleInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданное правило",
+                        "schema": {
+                            "$ref": "#/definitions/response.PriceRuleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Номер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при создании правила",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/owners/rooms/{id}/price-rules/{rule_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет правило цены номера. Доступно только владельцу.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Удаление правила цены",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID правила",
+                        "name": "rule_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Правило удалено",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Правило не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/owners/rooms/{id}/prices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает цены, заданные на отдельные даты. Доступно только владельцу.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Календарь цен номера",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Цены по датам",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.PriceOverrideResponse"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Номер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении цен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Задаёт цены на отдельные даты в календаре номера. Цена из календаря важнее правил. Доступно только владельцу.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Цены на конкретные ночи",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Цены по датам",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pricing.PriceOverrideInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Цены сохранены",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Номер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при сохранении цен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/owners/rooms/{id}/prices/{date}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет цену из календаря номера, ночь снова считается по правилам. Доступно только владельцу.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Удаление цены на дату",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Цена удалена",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректная дата",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Цена на эту дату не задана",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/owners/rooms/{room_id}/images/{image_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет изображение номера",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Удаление изображения номера",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "room_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID изображения",
+                        "name": "image_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Изображение успешно удалено",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Изображение не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/owners/{id}/room": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изменяет существующий номер. Доступно владельцу и менеджерам отеля.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Изменение номера",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные номера",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hotels.CreateRoomInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Номер успешно обновлен",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен или номер не принадлежит владельцу",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Номер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при обновлении номера",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет существующий номер. Доступно только для владельцев.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Удаление номера",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Номер успешно удален",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен или номер не принадлежит владельцу",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Номер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при удалении номера",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/callback": {
+            "post": {
+                "description": "Обрабатывает уведомления от платежной системы и обновляет статус оплаты для указанного бронирования. Уведомление принимается только с адресов платежной системы, состояние платежа перезапрашивается у провайдера, повторные уведомления игнорируются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Webhook для обработки статуса оплаты",
+                "parameters": [
+                    {
+                        "description": "Данные вебхука от платежной системы",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payments.PaymentCallbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Статус оплаты обновлен",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные запроса",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недоверенный источник уведомления",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Бронирование не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/fake/{id}": {
+            "get": {
+                "description": "Имитирует страницу оплаты при PAYMENT_PROVIDER=fake. action=succeed подтверждает платёж, action=cancel отменяет его.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Тестовая страница оплаты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID платежа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "succeed или cancel",
+                        "name": "action",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Состояние платежа",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageResponse"
+                        }
+                    },
+                    "302": {
+                        "description": "Перенаправление на return_url"
+                    },
+                    "400": {
+                        "description": "Некорректное действие",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Платёж не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Проверяет базу данных, почтовый сервер и облачное хранилище. Для каждой зависимости возвращает только ok или unavailable, причина ошибки пишется в журнал сервера. Во время остановки сервиса возвращает 503.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Проверка готовности",
+                "responses": {
+                    "200": {
+                        "description": "Все зависимости доступны",
+                        "schema": {
+                            "$ref": "#/definitions/response.ReadinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Зависимость недоступна или сервис останавливается",
+                        "schema": {
+                            "$ref": "#/definitions/response.ReadinessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms": {
+            "get": {
+                "description": "Возвращает отфильтрованный список типов номеров с возможностью фильтрации по цене, вместимости, датам бронирования и отелю. При указании дат возвращаются типы, у которых в каждую ночь периода есть свободный номер.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Получение списка номеров",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Минимальная цена",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Максимальная цена",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Минимальная вместимость",
+                        "name": "capacity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата начала (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата окончания (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID отеля",
+                        "name": "hotel_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список номеров",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.RoomResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный фильтр",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении номеров",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/availability": {
+            "get": {
+                "description": "Возвращает для типа номера количество свободных номеров на каждую ночь периода",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Свободные номера по ночам",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата заезда (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выезда (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Свободные номера по ночам",
+                        "schema": {
+                            "$ref": "#/definitions/response.RoomAvailabilityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные даты",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Номер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при расчёте доступности",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/bookings": {
+            "get": {
+                "description": "Получение бронирований для номера",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Получение бронирований для номера",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Данные о бранировании",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.BookingResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении списка бронирований",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/cancellation-policy": {
+            "get": {
+                "description": "Возвращает действующую политику отмены номера: политику номера или отеля. Если политика не задана, отмена бесплатная и возвращается пустая политика.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cancellation"
+                ],
+                "summary": "Условия отмены номера",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Действующая политика",
+                        "schema": {
+                            "$ref": "#/definitions/response.CancellationPolicyResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Номер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении политики отмены",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/quote": {
+            "get": {
+                "description": "Возвращает цены по ночам, налоги, сборы и итоговую стоимость проживания в номере. Ничего не бронирует; при бронировании используется тот же расчёт.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Расчёт стоимости проживания",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата заезда (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выезда (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество гостей, по умолчанию 1",
+                        "name": "guests",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Расчёт стоимости",
+                        "schema": {
+                            "$ref": "#/definitions/response.QuoteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Номер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при расчёте стоимости",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{room_id}/rate": {
+            "get": {
+                "description": "Получает оценки номера",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Получить оценки номера",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "room_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список оценок номера",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/response.RoomRatingResponse"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении оценок",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Оценивает номер пользователем",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Оценка номера",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID номера",
+                        "name": "room_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Рейтинг и комментарий",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hotels.RatingInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Оценка успешно добавлена",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Недопусти рейтинг/Вы уже оценили этот номер",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Номер не найден",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "auth.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.JWK"
+                    }
+                }
+            }
+        },
+        "auth.LoginInput": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.RefreshInput": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.RegisterInput": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "password",
+                "phone"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "language": {
+                    "description": "Язык писем; по умолчанию из Accept-Language",
+                    "type": "string",
+                    "enum": [
+                        "ru",
+                        "en"
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.ResetPasswordInput": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.ResetPasswordRequestInput": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "bookings.CancellationPolicyInput": {
+            "type": "object",
+            "properties": {
+                "free_until_days": {
+                    "description": "Бесплатная отмена не позднее чем за N дней до заезда",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "non_refundable": {
+                    "description": "Невозвратный тариф",
+                    "type": "boolean"
+                },
+                "penalty_percent": {
+                    "description": "Штраф в % от стоимости при более поздней отмене",
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0
+                }
+            }
+        },
+        "bookings.CreateBookingInput": {
+            "type": "object",
+            "required": [
+                "end_date",
+                "room_id",
+                "start_date"
+            ],
+            "properties": {
+                "adults": {
+                    "description": "Количество взрослых, по умолчанию 1",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "children": {
+                    "description": "Количество детей",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "bookings.CreateOfflineBookingInput": {
+            "type": "object",
+            "required": [
+                "end_date",
+                "name",
+                "phone_number",
+                "room_id",
+                "start_date"
+            ],
+            "properties": {
+                "adults": {
+                    "description": "Количество взрослых, по умолчанию 1",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "children": {
+                    "description": "Количество детей",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "bookings.UpdateBookingStatusInput": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "checked_in",
+                        "checked_out",
+                        "no_show",
+                        "cancelled"
+                    ]
+                }
+            }
+        },
+        "email.MailTestInput": {
+            "type": "object",
+            "properties": {
+                "to": {
+                    "description": "Адрес для тестового письма; без него только проверяется подключение",
+                    "type": "string"
+                }
+            }
+        },
+        "hotels.AddHotelStaffInput": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "role": {
+                    "description": "Роль в отеле, по умолчанию manager",
+                    "type": "string",
+                    "enum": [
+                        "manager"
+                    ]
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "hotels.CreateHotelInput": {
+            "type": "object",
+            "required": [
+                "address",
+                "name"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "service_fee": {
+                    "description": "Сервисный сбор за бронирование",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "tax_percent": {
+                    "description": "Налог, % от стоимости проживания",
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0
+                }
+            }
+        },
+        "hotels.CreateRoomInput": {
+            "type": "object",
+            "required": [
+                "capacity",
+                "hotel_id",
+                "price",
+                "room_type"
+            ],
+            "properties": {
+                "amenities": {
+                    "type": "string"
+                },
+                "base_occupancy": {
+                    "description": "Гостей, включённых в цену; 0 — все",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "capacity": {
+                    "type": "integer"
+                },
+                "extra_guest_price": {
+                    "description": "Доплата за гостя сверх base_occupancy за ночь",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "hotel_id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "room_type": {
+                    "type": "string"
+                },
+                "units": {
+                    "description": "Количество номеров этого типа, по умолчанию 1",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "hotels.RatingInput": {
+            "type": "object",
+            "required": [
+                "rating"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payments.PaymentCallbackRequest": {
+            "type": "object",
+            "properties": {
+                "event": {
+                    "description": "Тип события",
+                    "type": "string",
+                    "example": "payment.succeeded"
+                },
+                "object": {
+                    "description": "Основной объект данных",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payments.PaymentObject"
+                        }
+                    ]
+                }
+            }
+        },
+        "payments.PaymentMetadata": {
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "description": "Уникальный идентификатор бронирования",
+                    "type": "string",
+                    "example": "1"
+                }
+            }
+        },
+        "payments.PaymentObject": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "ID платежа",
+                    "type": "string",
+                    "example": "2d6f0a5c-000f-5000-9000-1b2c3d4e5f60"
+                },
+                "metadata": {
+                    "description": "Метаданные оплаты",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payments.PaymentMetadata"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "Статус оплаты",
+                    "type": "string",
+                    "example": "succeeded"
+                }
+            }
+        },
+        "pricing.PriceOverrideEntry": {
+            "type": "object",
+            "required": [
+                "date",
+                "price"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
+                "date": {
+                    "type": "string",
+                    "example": "2025-12-31"
                 },
-                "password": {
-                    "type": "string"
+                "price": {
+                    "type": "number"
                 }
             }
         },
-        "auth.RegisterInput": {
+        "pricing.PriceOverrideInput": {
             "type": "object",
             "required": [
-                "email",
-                "name",
-                "password",
-                "phone"
+                "prices"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pricing.PriceOverrideEntry"
+                    }
+                }
+            }
+        },
+        "pricing.PriceRuleInput": {
+            "type": "object",
+            "required": [
+                "price"
+            ],
+            "properties": {
+                "days_of_week": {
+                    "description": "Дни недели 1-7 (пн-вс), пусто — все дни",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        5,
+                        6
+                    ]
+                },
+                "end_date": {
+                    "description": "Конец периода включительно (YYYY-MM-DD), необязательно",
+                    "type": "string",
+                    "example": "2025-08-31"
+                },
+                "min_stay": {
+                    "type": "integer",
+                    "minimum": 0
                 },
                 "name": {
                     "type": "string"
                 },
-                "password": {
+                "price": {
+                    "type": "number"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "description": "Начало периода (YYYY-MM-DD), необязательно",
+                    "type": "string",
+                    "example": "2025-06-01"
+                }
+            }
+        },
+        "response.BookingEventResponse": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "actor_type": {
+                    "description": "user, system или payment",
                     "type": "string"
                 },
-                "phone": {
+                "booking_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "to_status": {
                     "type": "string"
                 }
             }
         },
-        "auth.ResetPasswordInput": {
+        "response.BookingResponse": {
             "type": "object",
-            "required": [
-                "password",
-                "token"
-            ],
             "properties": {
-                "password": {
+                "adults": {
+                    "description": "Количество взрослых",
+                    "type": "integer"
+                },
+                "children": {
+                    "description": "Количество детей",
+                    "type": "integer"
+                },
+                "end_date": {
                     "type": "string"
                 },
-                "token": {
+                "expires_at": {
+                    "description": "Срок оплаты",
+                    "type": "string"
+                },
+                "payment_status": {
+                    "description": "Статус оплаты",
+                    "type": "string"
+                },
+                "refund_amount": {
+                    "description": "Сумма к возврату, определённая при отмене",
+                    "type": "number"
+                },
+                "refunded_amount": {
+                    "description": "Сумма возврата при отмене",
+                    "type": "number"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Статус бронирования",
                     "type": "string"
+                },
+                "total_cost": {
+                    "description": "Итоговая стоимость",
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "auth.ResetPasswordRequestInput": {
+        "response.CancellationPolicyResponse": {
             "type": "object",
-            "required": [
-                "email"
-            ],
             "properties": {
-                "email": {
+                "FreeUntilDays": {
+                    "description": "Бесплатная отмена не позднее чем за N дней до заезда",
+                    "type": "integer"
+                },
+                "HotelID": {
+                    "type": "integer"
+                },
+                "ID": {
+                    "type": "integer"
+                },
+                "NonRefundable": {
+                    "description": "Невозвратный тариф",
+                    "type": "boolean"
+                },
+                "PenaltyPercent": {
+                    "description": "Штраф в % от стоимости при более поздней отмене",
+                    "type": "number"
+                },
+                "RoomID": {
+                    "description": "0 — политика всего отеля",
+                    "type": "integer"
+                }
+            }
+        },
+        "response.CancellationResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string"
+                },
+                "refund_amount": {
+                    "description": "Сумма возврата по политике отмены",
+                    "type": "number"
                 }
             }
         },
-        "bookings.CreateBookingInput": {
+        "response.CreatePaymentResponse": {
+            "type": "object",
+            "properties": {
+                "payment_url": {
+                    "description": "Ссылка для оплаты",
+                    "type": "string",
+                    "example": "ссылка на оплату"
+                }
+            }
+        },
+        "response.CreatedBookingResponse": {
+            "type": "object",
+            "properties": {
+                "adults": {
+                    "description": "Количество взрослых",
+                    "type": "integer"
+                },
+                "children": {
+                    "description": "Количество детей",
+                    "type": "integer"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "Срок оплаты",
+                    "type": "string"
+                },
+                "payment_status": {
+                    "description": "Статус оплаты",
+                    "type": "string"
+                },
+                "payment_url": {
+                    "description": "Ссылка для оплаты",
+                    "type": "string",
+                    "example": "ссылка на оплату"
+                },
+                "refund_amount": {
+                    "description": "Сумма к возврату, определённая при отмене",
+                    "type": "number"
+                },
+                "refunded_amount": {
+                    "description": "Сумма возврата при отмене",
+                    "type": "number"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Статус бронирования",
+                    "type": "string"
+                },
+                "total_cost": {
+                    "description": "Итоговая стоимость",
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.ErrorResponse": {
+            "description": "Стандартный ответ при ошибке",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "response.HotelRatingResponse": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "hotel_id": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.HotelResponse": {
             "type": "object",
-            "required": [
-                "end_date",
-                "room_id",
-                "start_date"
-            ],
             "properties": {
-                "end_date": {
+                "address": {
                     "type": "string"
                 },
-                "room_id": {
-                    "type": "integer"
+                "description": {
+                    "type": "string"
                 },
-                "start_date": {
+                "name": {
                     "type": "string"
+                },
+                "owner_id": {
+                    "type": "integer"
+                },
+                "rooms": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.RoomResponse"
+                    }
+                },
+                "service_fee": {
+                    "description": "Сервисный сбор за бронирование",
+                    "type": "number"
+                },
+                "tax_percent": {
+                    "description": "Налог, % от стоимости проживания",
+                    "type": "number"
                 }
             }
         },
-        "bookings.CreateOfflineBookingInput": {
+        "response.HotelStaffResponse": {
             "type": "object",
-            "required": [
-                "end_date",
-                "name",
-                "phone_number",
-                "room_id",
-                "start_date"
-            ],
             "properties": {
-                "end_date": {
-                    "type": "string"
-                },
-                "name": {
+                "CreatedAt": {
                     "type": "string"
                 },
-                "phone_number": {
-                    "type": "string"
+                "HotelID": {
+                    "type": "integer"
                 },
-                "room_id": {
+                "ID": {
                     "type": "integer"
                 },
-                "start_date": {
-                    "type": "string"
+                "Role": {
+                    "type": "string",
+                    "example": "manager"
+                },
+                "UserID": {
+                    "type": "integer"
                 }
             }
         },
-        "hotels.CreateHotelInput": {
+        "response.MailDiagnosticStepResponse": {
             "type": "object",
-            "required": [
-                "address",
-                "name"
-            ],
             "properties": {
-                "address": {
-                    "type": "string"
+                "detail": {
+                    "type": "string",
+                    "example": "TLS 1.3, TLS_AES_128_GCM_SHA256"
                 },
-                "description": {
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
                     "type": "string"
                 },
                 "name": {
-                    "type": "string"
+                    "description": "connect, greeting, ehlo, starttls, auth, send или quit",
+                    "type": "string",
+                    "example": "starttls"
+                },
+                "ok": {
+                    "type": "boolean"
                 }
             }
         },
-        "hotels.CreateRoomInput": {
+        "response.MailDiagnosticsResponse": {
             "type": "object",
-            "required": [
-                "capacity",
-                "hotel_id",
-                "price",
-                "room_type"
-            ],
             "properties": {
-                "amenities": {
+                "error": {
+                    "description": "Ошибка первого неудачного шага",
                     "type": "string"
                 },
-                "capacity": {
-                    "type": "integer"
+                "from": {
+                    "type": "string"
                 },
-                "hotel_id": {
-                    "type": "integer"
+                "ok": {
+                    "type": "boolean"
                 },
-                "price": {
-                    "type": "number"
+                "server": {
+                    "type": "string",
+                    "example": "smtp.example.com:587"
                 },
-                "room_type": {
-                    "type": "string"
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.MailDiagnosticStepResponse"
+                    }
+                },
+                "tls_mode": {
+                    "type": "string",
+                    "example": "starttls"
+                },
+                "transport": {
+                    "description": "smtp, file или memory",
+                    "type": "string",
+                    "example": "smtp"
                 }
             }
         },
-        "hotels.RatingInput": {
+        "response.MailStatusResponse": {
             "type": "object",
-            "required": [
-                "rating"
-            ],
             "properties": {
-                "comment": {
+                "counts": {
+                    "description": "Число писем в статусах pending, sent и dead",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "last_sent_at": {
+                    "description": "Последняя успешная отправка",
                     "type": "string"
                 },
-                "rating": {
-                    "type": "integer"
+                "oldest_pending": {
+                    "description": "Самое старое неотправленное письмо",
+                    "type": "string"
+                },
+                "recent": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.OutboxMessageResponse"
+                    }
                 }
             }
         },
-        "payments.PaymentCallbackRequest": {
+        "response.MessageResponse": {
             "type": "object",
             "properties": {
-                "object": {
-                    "description": "Основной объект данных",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/payments.PaymentObject"
-                        }
-                    ]
+                "message": {
+                    "type": "string"
                 }
             }
         },
-        "payments.PaymentMetadata": {
+        "response.NightAvailabilityEntry": {
             "type": "object",
             "properties": {
-                "booking_id": {
-                    "description": "Уникальный идентификатор бронирования",
+                "available": {
+                    "description": "Свободных номеров на эту ночь",
+                    "type": "integer"
+                },
+                "date": {
                     "type": "string",
-                    "example": "1"
+                    "example": "2025-01-31"
                 }
             }
         },
-        "payments.PaymentObject": {
+        "response.NightPriceResponse": {
             "type": "object",
             "properties": {
-                "metadata": {
-                    "description": "Метаданные оплаты",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/payments.PaymentMetadata"
-                        }
-                    ]
+                "date": {
+                    "type": "string",
+                    "example": "2025-06-01T00:00:00Z"
                 },
-                "status": {
-                    "description": "Статус оплаты",
+                "extra_guest": {
+                    "description": "Доплата за гостей сверх base_occupancy",
+                    "type": "number"
+                },
+                "price": {
+                    "description": "Цена ночи, включая доплату за гостей",
+                    "type": "number"
+                },
+                "rule_id": {
+                    "description": "ID правила, если цена взята из правила",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "base, rule или override",
                     "type": "string",
-                    "example": "succeeded"
+                    "example": "rule"
                 }
             }
         },
-        "response.BookingResponse": {
+        "response.OutboxMessageResponse": {
             "type": "object",
             "properties": {
-                "end_date": {
-                    "type": "string"
+                "attempts": {
+                    "type": "integer"
                 },
-                "payment_status": {
-                    "description": "Статус оплаты",
+                "created_at": {
                     "type": "string"
                 },
-                "room_id": {
+                "id": {
                     "type": "integer"
                 },
-                "start_date": {
+                "kind": {
+                    "type": "string",
+                    "example": "email"
+                },
+                "last_error": {
+                    "description": "Ошибка последней неудачной попытки",
                     "type": "string"
                 },
-                "total_cost": {
-                    "description": "Итоговая стоимость",
-                    "type": "number"
+                "next_attempt_at": {
+                    "type": "string"
                 },
-                "user_id": {
-                    "type": "integer"
-                }
-            }
-        },
-        "response.CreatePaymentResponse": {
-            "type": "object",
-            "properties": {
-                "payment_url": {
-                    "description": "Ссылка для оплаты",
+                "recipient": {
+                    "type": "string"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending, sent или dead",
                     "type": "string",
-                    "example": "ссылка на оплату"
+                    "example": "dead"
+                },
+                "subject": {
+                    "type": "string"
                 }
             }
         },
-        "response.ErrorResponse": {
-            "description": "Стандартный ответ при ошибке",
+        "response.PriceOverrideResponse": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string"
+                "Date": {
+                    "type": "string",
+                    "example": "2025-12-31T00:00:00Z"
+                },
+                "ID": {
+                    "type": "integer"
+                },
+                "Price": {
+                    "type": "number"
+                },
+                "RoomID": {
+                    "type": "integer"
                 }
             }
         },
-        "response.HotelRatingResponse": {
+        "response.PriceRuleResponse": {
             "type": "object",
             "properties": {
-                "comment": {
-                    "type": "string"
+                "DaysOfWeek": {
+                    "description": "Дни недели 1-7 (пн-вс)",
+                    "type": "string",
+                    "example": "5,6"
                 },
-                "hotel_id": {
+                "EndDate": {
+                    "type": "string",
+                    "example": "2025-08-31T00:00:00Z"
+                },
+                "ID": {
                     "type": "integer"
                 },
-                "rating": {
+                "MinStay": {
+                    "type": "integer"
+                },
+                "Name": {
+                    "type": "string"
+                },
+                "Price": {
                     "type": "number"
                 },
-                "user_id": {
+                "Priority": {
+                    "type": "integer"
+                },
+                "RoomID": {
                     "type": "integer"
+                },
+                "StartDate": {
+                    "type": "string",
+                    "example": "2025-06-01T00:00:00Z"
                 }
             }
         },
-        "response.HotelResponse": {
+        "response.QuoteResponse": {
             "type": "object",
             "properties": {
-                "address": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
+                "fees": {
+                    "description": "Сервисный сбор отеля",
+                    "type": "number"
                 },
-                "owner_id": {
+                "guests": {
                     "type": "integer"
                 },
-                "rooms": {
+                "nightly_prices": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/response.RoomResponse"
+                        "$ref": "#/definitions/response.NightPriceResponse"
+                    }
+                },
+                "nights": {
+                    "type": "integer"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "subtotal": {
+                    "description": "Сумма цен за ночи",
+                    "type": "number"
+                },
+                "taxes": {
+                    "description": "Налог отеля",
+                    "type": "number"
+                },
+                "total": {
+                    "description": "Итого к оплате",
+                    "type": "number"
+                }
+            }
+        },
+        "response.ReadinessResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "description": "ok или unavailable для database, smtp и storage",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
                     }
+                },
+                "status": {
+                    "description": "ok, unavailable или draining",
+                    "type": "string",
+                    "example": "ok"
                 }
             }
         },
-        "response.MessageResponse": {
+        "response.RoomAvailabilityResponse": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "nights": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.NightAvailabilityEntry"
+                    }
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "units": {
+                    "description": "Количество номеров этого типа",
+                    "type": "integer"
                 }
             }
         },
@@ -2124,10 +4404,18 @@ const docTemplate = `{
                     "description": "Наличие",
                     "type": "boolean"
                 },
+                "base_occupancy": {
+                    "description": "Гостей, включённых в цену; 0 — все",
+                    "type": "integer"
+                },
                 "capacity": {
                     "description": "Количество гостей",
                     "type": "integer"
                 },
+                "extra_guest_price": {
+                    "description": "Доплата за гостя сверх base_occupancy за ночь",
+                    "type": "number"
+                },
                 "hotel_id": {
                     "description": "ID отеля",
                     "type": "integer"
@@ -2139,6 +4427,10 @@ const docTemplate = `{
                 "room_type": {
                     "description": "Тип номера (стандартный, люкс и т.д.)",
                     "type": "string"
+                },
+                "units": {
+                    "description": "Количество номеров этого типа",
+                    "type": "integer"
                 }
             }
         },
@@ -2154,7 +4446,17 @@ const docTemplate = `{
         "response.TokenResponse": {
             "type": "object",
             "properties": {
+                "refresh_token": {
+                    "description": "Обменивается на новую пару через /auth/refresh",
+                    "type": "string",
+                    "example": "Ваш refresh-токен"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "client"
+                },
                 "token": {
+                    "description": "Access-токен, действует 15 минут",
                     "type": "string",
                     "example": "Ваш токен"
                 }
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"hotel-booking/internal/bookings"
+	"hotel-booking/internal/users"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bookingResponse — поля бронирования из ответов API
+type bookingResponse struct {
+	ID             uint
+	Status         string
+	PaymentStatus  string
+	PaymentID      string
+	TotalCost      float64
+	RefundedAmount float64
+	PaymentURL     string `json:"payment_url"`
+}
+
+var verificationToken = regexp.MustCompile(`/auth/verify\?token=([0-9a-f]+)`)
+
+// registerVerified регистрирует пользователя, подтверждает почту по ссылке из письма и входит
+func registerVerified(app *testApp, user testUser) string {
+	app.t.Helper()
+
+	app.expect("регистрация", app.do(http.MethodPost, "/auth/register", "", user, nil), http.StatusCreated)
+
+	token := app.login(user)
+	app.expect("запрос письма", app.do(http.MethodPost, "/auth/send-verification", token, nil, nil), http.StatusOK)
+
+	mails := app.deliverMail(user.Email)
+	if len(mails) != 1 {
+		app.t.Fatalf("писем с подтверждением: %d, ожидалось 1", len(mails))
+	}
+	match := verificationToken.FindStringSubmatch(mails[0].Text)
+	if match == nil {
+		app.t.Fatalf("в письме нет ссылки подтверждения: %q", mails[0].Text)
+	}
+	app.expect("подтверждение почты", app.do(http.MethodGet, "/auth/verify?token="+match[1], "", nil, nil), http.StatusOK)
+
+	var stored users.User
+	if err := app.db.Where("email = ?", user.Email).First(&stored).Error; err != nil || !stored.IsVerified {
+		app.t.Fatalf("почта не подтверждена: %v", err)
+	}
+	return app.login(user)
+}
+
+// createOwnerRoom регистрирует владельца, создаёт отель и номер с фотографией и возвращает ID номера
+func createOwnerRoom(app *testApp, units int) uint {
+	app.t.Helper()
+
+	owner := newTestUser("owner")
+	app.expect("регистрация владельца", app.do(http.MethodPost, "/auth/register", "", owner, nil), http.StatusCreated)
+	// Роль владельца выдаёт администратор; здесь она задаётся напрямую в базе
+	if err := app.db.Model(&users.User{}).Where("email = ?", owner.Email).Update("role", "owner").Error; err != nil {
+		app.t.Fatal(err)
+	}
+	token := app.login(owner)
+
+	var hotel struct{ ID uint }
+	code := app.do(http.MethodPost, "/owners/hotels", token, gin.H{"name": "Отель", "address": "Адрес"}, &hotel)
+	app.expect("создание отеля", code, http.StatusCreated)
+
+	var room struct{ ID uint }
+	code = app.do(http.MethodPost, "/owners/rooms", token, gin.H{
+		"hotel_id":  hotel.ID,
+		"room_type": "standard",
+		"price":     1000,
+		"capacity":  2,
+		"units":     units,
+	}, &room)
+	app.expect("создание номера", code, http.StatusCreated)
+
+	path := fmt.Sprintf("/owners/rooms/%d/images", room.ID)
+	app.expect("загрузка фото", app.upload(path, token, "images", "room.jpg", []byte("jpeg"), nil), http.StatusOK)
+	return room.ID
+}
+
+// book бронирует номер на две ночи через три дня
+func book(app *testApp, token string, roomID uint) bookingResponse {
+	app.t.Helper()
+
+	start := time.Now().Add(72 * time.Hour).Truncate(24 * time.Hour)
+	var booking bookingResponse
+	code := app.do(http.MethodPost, "/bookings", token, gin.H{
+		"room_id":    roomID,
+		"start_date": start,
+		"end_date":   start.Add(48 * time.Hour),
+	}, &booking)
+	app.expect("бронирование", code, http.StatusCreated)
+	if booking.PaymentURL == "" || booking.Status != "pending_payment" {
+		app.t.Fatalf("неожиданное бронирование: %+v", booking)
+	}
+	return booking
+}
+
+// myBooking возвращает бронирование id из списка бронирований гостя
+func myBooking(app *testApp, token string, id uint) bookingResponse {
+	app.t.Helper()
+
+	var list []bookingResponse
+	app.expect("мои бронирования", app.do(http.MethodGet, "/bookings/my", token, nil, &list), http.StatusOK)
+	for _, booking := range list {
+		if booking.ID == id {
+			return booking
+		}
+	}
+	app.t.Fatalf("бронирование %d не найдено среди бронирований гостя", id)
+	return bookingResponse{}
+}
+
+func TestE2EBookPayRefund(t *testing.T) {
+	app := newTestApp(t)
+	roomID := createOwnerRoom(app, 1)
+	guest := newTestUser("guest")
+	token := registerVerified(app, guest)
+	app.mailer.Reset()
+
+	booking := book(app, token, roomID)
+	if booking.TotalCost != 2000 {
+		t.Fatalf("стоимость %.2f, ожидалось 2000", booking.TotalCost)
+	}
+	if mails := app.deliverMail(guest.Email); len(mails) != 1 {
+		t.Fatalf("писем о бронировании: %d, ожидалось 1", len(mails))
+	}
+
+	// Гость оплачивает, платёжная система присылает уведомление
+	if _, err := app.payments.Confirm(booking.PaymentID); err != nil {
+		t.Fatal(err)
+	}
+	callback := gin.H{"event": "payment.succeeded", "object": gin.H{"id": booking.PaymentID}}
+	app.expect("уведомление об оплате", app.do(http.MethodPost, "/payments/callback", "", callback, nil), http.StatusOK)
+	// Повторное уведомление ничего не меняет
+	app.expect("повторное уведомление", app.do(http.MethodPost, "/payments/callback", "", callback, nil), http.StatusOK)
+
+	paid := myBooking(app, token, booking.ID)
+	if paid.Status != "confirmed" || paid.PaymentStatus != "succeeded" {
+		t.Fatalf("после оплаты: %+v", paid)
+	}
+
+	var refund struct {
+		RefundAmount float64 `json:"refund_amount"`
+	}
+	path := fmt.Sprintf("/bookings/%d/refund", booking.ID)
+	app.expect("возврат", app.do(http.MethodPost, path, token, nil, &refund), http.StatusOK)
+	if refund.RefundAmount != 2000 {
+		t.Fatalf("возвращено %.2f, ожидалось 2000", refund.RefundAmount)
+	}
+
+	refunded := myBooking(app, token, booking.ID)
+	if refunded.Status != "refunded" || refunded.RefundedAmount != 2000 {
+		t.Fatalf("после возврата: %+v", refunded)
+	}
+
+	// Номер снова свободен
+	book(app, token, roomID)
+}
+
+func TestE2ECancelledPaymentReleasesRoom(t *testing.T) {
+	app := newTestApp(t)
+	roomID := createOwnerRoom(app, 1)
+	token := registerVerified(app, newTestUser("guest"))
+
+	booking := book(app, token, roomID)
+
+	// Единственный номер занят до оплаты или отмены
+	start := time.Now().Add(72 * time.Hour).Truncate(24 * time.Hour)
+	code := app.do(http.MethodPost, "/bookings", token, gin.H{
+		"room_id":    roomID,
+		"start_date": start,
+		"end_date":   start.Add(24 * time.Hour),
+	}, nil)
+	app.expect("бронирование занятого номера", code, http.StatusConflict)
+
+	// Гость отказывается от оплаты на странице платёжной системы
+	var checkout struct{ Message string }
+	app.expect("отказ от оплаты", app.do(http.MethodGet, "/payments/fake/"+booking.PaymentID+"?action=cancel", "", nil, &checkout), http.StatusOK)
+
+	cancelled := myBooking(app, token, booking.ID)
+	if cancelled.Status != "cancelled" || cancelled.PaymentStatus != "canceled" {
+		t.Fatalf("после отказа от оплаты: %+v", cancelled)
+	}
+
+	book(app, token, roomID)
+}
+
+func TestE2EPaymentAfterExpiryIsRefunded(t *testing.T) {
+	app := newTestApp(t)
+	roomID := createOwnerRoom(app, 1)
+	token := registerVerified(app, newTestUser("guest"))
+
+	booking := book(app, token, roomID)
+
+	// Срок оплаты вышел, пока гость был на странице оплаты
+	if err := app.db.Model(&bookings.Booking{}).Where("id = ?", booking.ID).Update("status", bookings.StatusExpired).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.payments.Confirm(booking.PaymentID); err != nil {
+		t.Fatal(err)
+	}
+	callback := gin.H{"event": "payment.succeeded", "object": gin.H{"id": booking.PaymentID}}
+	app.expect("уведомление об оплате", app.do(http.MethodPost, "/payments/callback", "", callback, nil), http.StatusOK)
+
+	late := myBooking(app, token, booking.ID)
+	if late.Status != "expired" || late.PaymentStatus != "refunded" || late.RefundedAmount != booking.TotalCost {
+		t.Fatalf("после поздней оплаты: %+v", late)
+	}
+	if refunded := app.payments.Refunded(booking.PaymentID); refunded != booking.TotalCost {
+		t.Fatalf("возвращено %.2f, ожидалось %.2f", refunded, booking.TotalCost)
+	}
+}
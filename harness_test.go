@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hotel-booking/internal/auth"
+	"hotel-booking/internal/config"
+	"hotel-booking/internal/email"
+	"hotel-booking/internal/health"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/migrations"
+	"hotel-booking/internal/outbox"
+	"hotel-booking/internal/payments"
+	"hotel-booking/internal/storage"
+	"hotel-booking/internal/testdb"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/webdav"
+	"gorm.io/gorm"
+)
+
+// testApp — приложение целиком: маршруты из main поверх тестовой базы PostgreSQL.
+// Почта, облачное хранилище и платёжная система заменены подделками в памяти.
+type testApp struct {
+	t        *testing.T
+	server   *httptest.Server
+	db       *gorm.DB
+	mailer   *email.MemoryMailer
+	payments *payments.FakeProvider
+	outbox   *outbox.Dispatcher
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(testdb.Run(m))
+}
+
+// newTestApp поднимает приложение на базе из testdb: TEST_DATABASE_DSN или временный PostgreSQL.
+// Глобальные настройки пакетов (storage.DB, почта, провайдер) подменяются на время теста.
+func newTestApp(t *testing.T) *testApp {
+	t.Helper()
+
+	db := testdb.Open(t)
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatalf("загрузка миграций: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("миграция: %v", err)
+	}
+	storage.DB = db
+
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewUnstartedServer(nil)
+	baseURL := "http://" + server.Listener.Addr().String()
+
+	tokens, err := auth.TokenServiceFromSpec("", "integration-test-secret", "")
+	if err != nil {
+		t.Fatalf("ключ JWT: %v", err)
+	}
+	auth.SetTokenService(tokens)
+	auth.SetLinkURLs(baseURL, baseURL)
+
+	fake := payments.NewFakeProvider(baseURL)
+
+	mailer := email.NewMemoryMailer()
+	email.SetMailer(mailer)
+
+	// Облачное хранилище — WebDAV-сервер с файлами в памяти
+	storageServer := httptest.NewServer(&webdav.Handler{FileSystem: webdav.NewMemFS(), LockSystem: webdav.NewMemLS()})
+	t.Cleanup(storageServer.Close)
+	hotels.SetWebDAVConfig(hotels.WebDAVConfig{
+		Endpoint:  storageServer.URL,
+		Username:  "test",
+		Password:  "test",
+		PublicURL: storageServer.URL,
+	})
+
+	checker := health.NewChecker(time.Second)
+	checker.Add("database", storage.Ping)
+	handlers := newHandlers(db, checker, fake, payments.Config{Provider: "fake", BackendURL: baseURL})
+
+	r, err := newRouter(config.ServerConfig{CORSOrigins: []string{baseURL}}, handlers)
+	if err != nil {
+		t.Fatalf("сборка маршрутов: %v", err)
+	}
+	server.Config.Handler = r
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return &testApp{
+		t:        t,
+		server:   server,
+		db:       db,
+		mailer:   mailer,
+		payments: fake,
+		outbox:   outbox.NewDispatcher(handlers.outbox.Messages, email.SendEmail, outbox.Config{Interval: time.Hour, MaxAttempts: 1}),
+	}
+}
+
+// do отправляет JSON-запрос с токеном token (пустой — без входа) и разбирает ответ в out
+func (a *testApp) do(method, path, token string, body, out interface{}) int {
+	a.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			a.t.Fatalf("%s %s: %v", method, path, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, a.server.URL+path, reader)
+	if err != nil {
+		a.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return a.send(req, token, out)
+}
+
+// upload отправляет файл в поле field multipart-формы
+func (a *testApp) upload(path, token, field, filename string, data []byte, out interface{}) int {
+	a.t.Helper()
+
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	part, err := form.CreateFormFile(field, filename)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	part.Write(data)
+	form.Close()
+
+	req, err := http.NewRequest(http.MethodPost, a.server.URL+path, &buf)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return a.send(req, token, out)
+}
+
+func (a *testApp) send(req *http.Request, token string, out interface{}) int {
+	a.t.Helper()
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := a.server.Client().Do(req)
+	if err != nil {
+		a.t.Fatalf("%s %s: %v", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		a.t.Fatalf("%s %s: %v", req.Method, req.URL.Path, err)
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			a.t.Fatalf("%s %s: ответ %d %q: %v", req.Method, req.URL.Path, resp.StatusCode, data, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// expect проверяет код ответа
+func (a *testApp) expect(step string, got, want int) {
+	a.t.Helper()
+	if got != want {
+		a.t.Fatalf("%s: код %d, ожидался %d", step, got, want)
+	}
+}
+
+// testUser — данные для регистрации уникального пользователя
+type testUser struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Password string `json:"password"`
+}
+
+func newTestUser(name string) testUser {
+	suffix := time.Now().UnixNano() % 1_000_000_000
+	return testUser{
+		Name:     name,
+		Email:    fmt.Sprintf("%s%d@example.com", name, suffix),
+		Phone:    fmt.Sprintf("+7%010d", suffix),
+		Password: "password123",
+	}
+}
+
+// login входит под пользователем и возвращает access-токен
+func (a *testApp) login(user testUser) string {
+	a.t.Helper()
+
+	var tokens struct {
+		Token string `json:"token"`
+	}
+	code := a.do(http.MethodPost, "/auth/login", "", gin.H{"email": user.Email, "password": user.Password}, &tokens)
+	a.expect("вход "+user.Email, code, http.StatusOK)
+	return tokens.Token
+}
+
+// deliverMail отправляет письма из outbox и возвращает письма, полученные адресатом to
+func (a *testApp) deliverMail(to string) []email.SentMessage {
+	a.t.Helper()
+
+	a.outbox.DispatchDue(time.Now())
+	var received []email.SentMessage
+	for _, msg := range a.mailer.Messages() {
+		if msg.To == to {
+			received = append(received, msg)
+		}
+	}
+	return received
+}
@@ -3,19 +3,28 @@ package auth
 import (
 	"crypto/rand"
 	"encoding/hex"
-	"fmt"
+	"errors"
 	"hotel-booking/internal/email"
-	"hotel-booking/internal/storage"
 	"hotel-booking/internal/users"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+// Handler — обработчики регистрации, входа и сессий
+type Handler struct {
+	Users    users.UserRepo
+	Sessions SessionRepo
+}
+
+func NewHandler(userRepo users.UserRepo, sessions SessionRepo) *Handler {
+	return &Handler{Users: userRepo, Sessions: sessions}
+}
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
@@ -25,6 +34,7 @@ type RegisterInput struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
 	Phone    string `json:"phone" binding:"required"`
+	Language string `json:"language" binding:"omitempty,oneof=ru en" enums:"ru,en"` // Язык писем; по умолчанию из Accept-Language
 }
 
 // RegisterHandler godoc
@@ -39,7 +49,7 @@ type RegisterInput struct {
 // @Failure 409 {object} response.ErrorResponse "Почта или телефон уже зарегистрированы"
 // @Failure 500 {object} response.ErrorResponse "Не удалось хешировать пароль или создать пользователя"
 // @Router /auth/register [post]
-func RegisterHandler(c *gin.Context) {
+func (h *Handler) RegisterHandler(c *gin.Context) {
 	var input RegisterInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -47,11 +57,18 @@ func RegisterHandler(c *gin.Context) {
 	}
 
 	// Проверка уникальности почты и телефона
-	var existingUser users.User
-	if err := storage.DB.Where("email = ? or phone = ?", input.Email, input.Phone).First(&existingUser).Error; err == nil {
+	_, err := h.Users.ByEmail(input.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_, err = h.Users.ByPhone(input.Phone)
+	}
+	if err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "Почта или телефон уже зарегистрированы"})
 		return
 	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось создать пользователя"})
+		return
+	}
 
 	// Хешируем пароль
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
@@ -69,6 +86,11 @@ func RegisterHandler(c *gin.Context) {
 
 	token := hex.EncodeToString(verificationToken)
 
+	language := input.Language
+	if language == "" {
+		language = email.Language(c.GetHeader("Accept-Language"))
+	}
+
 	// Создаём пользователя
 	user := users.User{
 		Name:              input.Name,
@@ -78,9 +100,10 @@ func RegisterHandler(c *gin.Context) {
 		Role:              "client",
 		IsVerified:        false,
 		VerificationToken: token,
+		Language:          language,
 	}
 
-	if err := storage.DB.Create(&user).Error; err != nil {
+	if err := h.Users.Create(&user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось создать пользователя"})
 		return
 	}
@@ -100,23 +123,20 @@ func RegisterHandler(c *gin.Context) {
 // @Failure 404 {object} response.ErrorResponse "Неверный токен"
 // @Failure 500 {object} response.ErrorResponse "Не удалось обновить пользователя"
 // @Router /auth/verify [get]
-func VerifyHandler(c *gin.Context) {
+func (h *Handler) VerifyHandler(c *gin.Context) {
 	token := c.Query("token")
 	if token == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Токен не предоставлен"})
 		return
 	}
 
-	var user users.User
-	if err := storage.DB.Where("verification_token = ?", token).First(&user).Error; err != nil {
+	user, err := h.Users.ByVerificationToken(token)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Неверный токен"})
 		return
 	}
 
-	user.IsVerified = true
-	user.VerificationToken = ""
-
-	if err := storage.DB.Save(&user).Error; err != nil {
+	if err := h.Users.Verify(&user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось обновить пользователя"})
 		return
 	}
@@ -136,11 +156,11 @@ func VerifyHandler(c *gin.Context) {
 // @Failure 404 {object} response.ErrorResponse "Пользователь не найден"
 // @Failure 500 {object} response.ErrorResponse "Не удалось отправить письмо"
 // @Router /auth/send-verification [post]
-func SendVerifiHandler(c *gin.Context) {
+func (h *Handler) SendVerifiHandler(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
-	var user users.User
-	if err := storage.DB.First(&user, userID).Error; err != nil {
+	user, err := h.Users.ByID(userID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
 		return
 	}
@@ -150,89 +170,25 @@ func SendVerifiHandler(c *gin.Context) {
 		return
 	}
 
-	veridicationLink := fmt.Sprintf("http://localhost:8080/auth/verify?token=%s", user.VerificationToken)
-	emailTemplate := `<!DOCTYPE html>
-<html>
-<head>
-    <style>
-        body {
-            font-family: Arial, sans-serif;
-            line-height: 1.6;
-            background-color: #f4f4f9;
-            margin: 0;
-            padding: 0;
-        }
-        .email-container {
-            max-width: 600px;
-            margin: 20px auto;
-            background: #ffffff;
-            border: 1px solid #dddddd;
-            border-radius: 8px;
-            overflow: hidden;
-            box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);
-        }
-        .email-header {
-            background-color: #4CAF50;
-            color: #ffffff;
-            text-align: center;
-            padding: 20px;
-        }
-        .email-body {
-            padding: 20px;
-            color: #333333;
-        }
-        .email-body h2 {
-            color: #4CAF50;
-        }
-        .email-body p {
-            margin: 10px 0;
-        }
-        .email-footer {
-            text-align: center;
-            padding: 10px;
-            font-size: 12px;
-            color: #777777;
-            background-color: #f9f9f9;
-            border-top: 1px solid #dddddd;
-        }
-        .confirm-button {
-            display: inline-block;
-            margin: 20px 0;
-            padding: 10px 20px;
-            background-color: #4CAF50;
-            color: #ffffff;
-            text-decoration: none;
-            font-size: 16px;
-            border-radius: 4px;
-        }
-        .confirm-button:hover {
-            background-color: #45a049;
-        }
-    </style>
-</head>
-<body>
-    <div class="email-container">
-        <div class="email-header">
-            <h1>Подтверждение регистрации</h1>
-        </div>
-        <div class="email-body">
-            <h2>Здравствуйте, %s</h2>
-            <p>Благодарим вас за регистрацию! Для подтверждения вашей учетной записи, пожалуйста, перейдите по ссылке ниже:</p>
-            <a href="%s" class="confirm-button">Подтвердить регистрацию</a>
-            <p>Если вы не регистрировались на нашем сайте, просто проигнорируйте это письмо.</p>
-        </div>
-        <div class="email-footer">
-            <p>© 2025 Ваша Компания. Все права защищены.</p>
-        </div>
-    </div>
-</body>
-</html>
-`
-
-	body := fmt.Sprintf(emailTemplate, user.Name, veridicationLink)
-	subject := "Подтверждение регистрации"
-
-	if err := email.SendEmail(user.Email, subject, body); err != nil {
+	// Каждое письмо получает новый токен, ссылки из прежних писем перестают действовать
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось создать токен"})
+		return
+	}
+	verificationToken := hex.EncodeToString(token)
+
+	msg, err := email.Render(email.TemplateVerification, user.Language, email.VerificationData{
+		Name: user.Name,
+		Link: verificationLink(verificationToken),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось отправить письмо"})
+		return
+	}
+
+	// Токен и письмо с ним сохраняются вместе: письмо уйдёт, только если токен записан
+	if err := h.Users.SetVerificationToken(c.Request.Context(), &user, verificationToken, msg); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось отправить письмо"})
 		return
 	}
@@ -240,8 +196,6 @@ func SendVerifiHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Письмо с подтверждением отправлено"})
 }
 
-var jwtSecret = []byte(os.Getenv("JWT_KEY"))
-
 type LoginInput struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
@@ -258,7 +212,7 @@ type LoginInput struct {
 // @Failure 400 {object} response.ErrorResponse "Описание ошибки валидации"
 // @Failure 401 {object} response.ErrorResponse "Неверный email или пароль"
 // @Router /auth/login [post]
-func LoginHandler(c *gin.Context) {
+func (h *Handler) LoginHandler(c *gin.Context) {
 	var input LoginInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -266,8 +220,8 @@ func LoginHandler(c *gin.Context) {
 	}
 
 	// Проверяем пользователя
-	var user users.User
-	if err := storage.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
+	user, err := h.Users.ByEmail(input.Email)
+	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный email или пароль"})
 		return
 	}
@@ -278,20 +232,105 @@ func LoginHandler(c *gin.Context) {
 		return
 	}
 
-	// Генерируем JWT
-	token := GenerateJWT(user.ID, user.Role)
+	// Создаём сессию и выдаём токены
+	_, tokens, err := h.startSession(user, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось создать сессию"})
+		return
+	}
 	role := user.Role
-	c.JSON(http.StatusOK, gin.H{"token": token, "role": role})
+	c.JSON(http.StatusOK, gin.H{"token": tokens.AccessToken, "refresh_token": tokens.RefreshToken, "role": role})
 }
 
-func GenerateJWT(userID uint, role string) string {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+// GenerateJWT выдаёт короткоживущий access-токен сессии sessionID.
+// tokenVersion сверяется с users.token_version при каждом запросе.
+func GenerateJWT(userID uint, role string, sessionID uint, tokenVersion int) (string, error) {
+	return Tokens().Sign(jwt.MapClaims{
 		"user_id": userID,
 		"role":    role,
-		"exp":     time.Now().Add(72 * time.Hour).Unix(),
+		"sid":     sessionID,
+		"tv":      tokenVersion,
+		"exp":     time.Now().Add(accessTokenTTL).Unix(),
 	})
-	tokenString, _ := token.SignedString(jwtSecret)
-	return tokenString
+}
+
+// JWKSHandler godoc
+// @Summary Открытые ключи подписи токенов
+// @Description Возвращает открытые ключи (JWKS) для проверки access-токенов. Симметричные ключи не публикуются.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} auth.JWKS "Набор ключей"
+// @Router /.well-known/jwks.json [get]
+func JWKSHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, Tokens().JWKS())
+}
+
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshHandler godoc
+// @Summary Обновление токенов
+// @Description Обменивает refresh-токен на новую пару токенов. Старый refresh-токен перестаёт действовать; его повторное использование отзывает все сессии пользователя.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body RefreshInput true "Refresh-токен"
+// @Success 200 {object} response.TokenResponse "Новая пара токенов"
+// @Failure 400 {object} response.ErrorResponse "Описание ошибки валидации"
+// @Failure 401 {object} response.ErrorResponse "Refresh-токен недействителен или отозван"
+// @Failure 500 {object} response.ErrorResponse "Не удалось обновить сессию"
+// @Router /auth/refresh [post]
+func (h *Handler) RefreshHandler(c *gin.Context) {
+	var input RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, tokens, err := h.rotateSession(input.RefreshToken, c.Request.UserAgent(), c.ClientIP())
+	if errors.Is(err, ErrInvalidRefreshToken) || errors.Is(err, ErrSessionRevoked) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh-токен недействителен или отозван"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось обновить сессию"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": tokens.AccessToken, "refresh_token": tokens.RefreshToken, "role": user.Role})
+}
+
+// LogoutHandler godoc
+// @Summary Выход
+// @Description Отзывает сессию refresh-токена. Access-токены этой сессии перестают приниматься сразу.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body RefreshInput true "Refresh-токен"
+// @Success 200 {object} response.MessageResponse "Сессия завершена"
+// @Failure 400 {object} response.ErrorResponse "Описание ошибки валидации"
+// @Failure 401 {object} response.ErrorResponse "Refresh-токен недействителен или отозван"
+// @Failure 500 {object} response.ErrorResponse "Не удалось завершить сессию"
+// @Router /auth/logout [post]
+func (h *Handler) LogoutHandler(c *gin.Context) {
+	var input RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.Sessions.RevokeByTokenHash(hashToken(input.RefreshToken))
+	if errors.Is(err, ErrInvalidRefreshToken) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh-токен недействителен или отозван"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось завершить сессию"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Сессия завершена"})
 }
 
 type ResetPasswordRequestInput struct {
@@ -310,7 +349,7 @@ type ResetPasswordRequestInput struct {
 // @Failure 404 {object} response.ErrorResponse "Пользователь не найден"
 // @Failure 500 {object} response.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /auth/reset-password-request [post]
-func ResetPasswordRequestHandler(c *gin.Context) {
+func (h *Handler) ResetPasswordRequestHandler(c *gin.Context) {
 	var input ResetPasswordRequestInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -318,15 +357,14 @@ func ResetPasswordRequestHandler(c *gin.Context) {
 		return
 	}
 
-	var user users.User
-	if err := storage.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
+	user, err := h.Users.ByEmail(input.Email)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
 		return
 	}
 
 	token := make([]byte, 32)
-	_, err := rand.Read(token)
-	if err != nil {
+	if _, err := rand.Read(token); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка генерации токена"})
 		return
 	}
@@ -334,99 +372,18 @@ func ResetPasswordRequestHandler(c *gin.Context) {
 	resetToken := hex.EncodeToString(token)
 	expiration := time.Now().Add(10 * time.Minute)
 
-	user.ResetPasswordToken = resetToken
-	user.ResetTokenExpiry = &expiration
-
-	if err := storage.DB.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении токена"})
+	msg, err := email.Render(email.TemplatePasswordReset, user.Language, email.PasswordResetData{
+		Name: user.Name,
+		Link: resetPasswordLink(resetToken),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отправке письма"})
 		return
 	}
 
-	resetLink := fmt.Sprintf("http://localhost:8080/auth/reset-password?token=%s", resetToken)
-	emailTemplate := `<!DOCTYPE html>
-<html lang="ru">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Сброс пароля</title>
-    <style>
-        body {
-            font-family: Arial, sans-serif;
-            background-color: #f9f9f9;
-            margin: 0;
-            padding: 0;
-        }
-        .email-container {
-            max-width: 600px;
-            margin: 20px auto;
-            background: #ffffff;
-            border: 1px solid #ddd;
-            border-radius: 8px;
-            overflow: hidden;
-        }
-        .email-header {
-            background-color: #007bff;
-            color: #ffffff;
-            padding: 20px;
-            text-align: center;
-        }
-        .email-header h1 {
-            margin: 0;
-            font-size: 24px;
-        }
-        .email-body {
-            padding: 20px;
-            color: #333333;
-        }
-        .email-body p {
-            margin: 0 0 15px;
-            line-height: 1.5;
-        }
-        .email-footer {
-            background-color: #f4f4f9;
-            text-align: center;
-            padding: 10px;
-            font-size: 12px;
-            color: #777;
-        }
-        .reset-button {
-            display: inline-block;
-            margin-top: 20px;
-            padding: 10px 20px;
-            background-color: #007bff;
-            color: #ffffff;
-            text-decoration: none;
-            border-radius: 5px;
-            font-size: 16px;
-        }
-        .reset-button:hover {
-            background-color: #0056b3;
-        }
-    </style>
-</head>
-<body>
-    <div class="email-container">
-        <div class="email-header">
-            <h1>Сброс пароля</h1>
-        </div>
-        <div class="email-body">
-            <p>Здравствуйте,</p>
-            <p>Вы запросили сброс пароля для вашей учетной записи. Чтобы продолжить, нажмите на кнопку ниже:</p>
-            <a href="%s" class="reset-button">Сбросить пароль</a>
-            <p>Если вы не запрашивали сброс пароля, просто проигнорируйте это письмо.</p>
-            <p>С уважением,<br>Команда поддержки</p>
-        </div>
-        <div class="email-footer">
-            Это письмо было отправлено автоматически. Пожалуйста, не отвечайте на него.
-        </div>
-    </div>
-</body>
-</html>`
-
-	subject := "Восстановление пароля"
-	body := fmt.Sprintf(emailTemplate, resetLink)
-	if err := email.SendEmail(user.Email, subject, body); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отправке письма"})
+	// Токен и письмо с ним сохраняются вместе: письмо уйдёт, только если токен записан
+	if err := h.Users.SetResetToken(c.Request.Context(), &user, resetToken, expiration, msg); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении токена"})
 		return
 	}
 
@@ -450,15 +407,15 @@ type ResetPasswordInput struct {
 // @Failure 404 {object} map[string]string "Неверный токен"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /auth/reset-password [post]
-func ResetPasswordHandler(c *gin.Context) {
+func (h *Handler) ResetPasswordHandler(c *gin.Context) {
 	var input ResetPasswordInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	var user users.User
-	if err := storage.DB.Where("reset_password_token = ?", input.Token).First(&user).Error; err != nil {
+	user, err := h.Users.ByResetToken(input.Token)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Неверный токен"})
 		return
 	}
@@ -474,11 +431,8 @@ func ResetPasswordHandler(c *gin.Context) {
 		return
 	}
 
-	user.Password = string(hashedPassword)
-	user.ResetPasswordToken = ""
-	user.ResetTokenExpiry = nil
-
-	if err := storage.DB.Save(&user).Error; err != nil {
+	// Новый пароль завершает все сессии пользователя
+	if err := h.Sessions.ResetPassword(&user, string(hashedPassword)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении пароля"})
 		return
 	}
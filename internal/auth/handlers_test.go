@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"hotel-booking/internal/users"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestHandler собирает обработчики поверх репозиториев в памяти и HS256-ключа
+func newTestHandler(t *testing.T) (*Handler, *users.MemoryUserRepo, *gin.Engine) {
+	t.Helper()
+
+	service, err := TokenServiceFromSpec("", "test-secret", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetTokenService(service)
+	t.Cleanup(func() { SetTokenService(nil) })
+
+	userRepo := users.NewMemoryUserRepo()
+	h := NewHandler(userRepo, NewMemorySessionRepo(userRepo))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/auth/register", h.RegisterHandler)
+	r.GET("/auth/verify", h.VerifyHandler)
+	r.POST("/auth/login", h.LoginHandler)
+	r.POST("/auth/refresh", h.RefreshHandler)
+	r.POST("/auth/reset-password-request", h.ResetPasswordRequestHandler)
+	r.POST("/auth/reset-password", h.ResetPasswordHandler)
+	r.POST("/auth/send-verification", AuthMiddleware(h.Sessions), h.SendVerifiHandler)
+	r.GET("/me", AuthMiddleware(h.Sessions), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetUint("user_id")})
+	})
+	return h, userRepo, r
+}
+
+// call выполняет запрос с телом body в JSON и разбирает ответ в out
+func call(r *gin.Engine, method, path, token string, body, out interface{}) int {
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if out != nil {
+		json.Unmarshal(w.Body.Bytes(), out)
+	}
+	return w.Code
+}
+
+type tokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func TestRegisterAndVerify(t *testing.T) {
+	_, userRepo, r := newTestHandler(t)
+
+	input := RegisterInput{Name: "Иван", Email: "ivan@example.com", Password: "secret", Phone: "+79990000001"}
+	if code := call(r, http.MethodPost, "/auth/register", "", input, nil); code != http.StatusCreated {
+		t.Fatalf("регистрация: код %d", code)
+	}
+
+	// Почта или телефон уже заняты
+	duplicate := input
+	duplicate.Phone = "+79990000002"
+	if code := call(r, http.MethodPost, "/auth/register", "", duplicate, nil); code != http.StatusConflict {
+		t.Errorf("повтор почты: код %d, ожидался 409", code)
+	}
+	duplicate = input
+	duplicate.Email = "other@example.com"
+	if code := call(r, http.MethodPost, "/auth/register", "", duplicate, nil); code != http.StatusConflict {
+		t.Errorf("повтор телефона: код %d, ожидался 409", code)
+	}
+
+	user, err := userRepo.ByEmail(input.Email)
+	if err != nil || user.IsVerified || user.VerificationToken == "" {
+		t.Fatalf("после регистрации: %+v, %v", user, err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)) != nil {
+		t.Fatal("пароль сохранён без хеширования")
+	}
+
+	// Письмо с подтверждением получает новый токен, сохранённый вместе с письмом
+	var tokens tokenResponse
+	if code := call(r, http.MethodPost, "/auth/login", "", LoginInput{Email: input.Email, Password: input.Password}, &tokens); code != http.StatusOK {
+		t.Fatalf("вход: код %d", code)
+	}
+	if code := call(r, http.MethodPost, "/auth/send-verification", tokens.Token, nil, nil); code != http.StatusOK {
+		t.Fatalf("запрос письма: код %d", code)
+	}
+	registered := user.VerificationToken
+	user, _ = userRepo.ByID(user.ID)
+	emails := userRepo.Emails()
+	if user.VerificationToken == registered || len(emails) != 1 || !strings.Contains(emails[0].Message.Text, "token="+user.VerificationToken) {
+		t.Fatalf("письмо с подтверждением: %+v, токен %q", emails, user.VerificationToken)
+	}
+
+	if code := call(r, http.MethodGet, "/auth/verify?token=wrong", "", nil, nil); code != http.StatusNotFound {
+		t.Errorf("неверный токен: код %d, ожидался 404", code)
+	}
+	if code := call(r, http.MethodGet, "/auth/verify?token="+registered, "", nil, nil); code != http.StatusNotFound {
+		t.Errorf("токен из прежнего письма: код %d, ожидался 404", code)
+	}
+	if code := call(r, http.MethodGet, "/auth/verify?token="+user.VerificationToken, "", nil, nil); code != http.StatusOK {
+		t.Fatalf("подтверждение: код %d", code)
+	}
+	if user, _ = userRepo.ByID(user.ID); !user.IsVerified || user.VerificationToken != "" {
+		t.Fatalf("после подтверждения: %+v", user)
+	}
+}
+
+func TestResetPasswordEndsSessions(t *testing.T) {
+	_, userRepo, r := newTestHandler(t)
+
+	input := RegisterInput{Name: "Иван", Email: "ivan@example.com", Password: "old-secret", Phone: "+79990000001"}
+	if code := call(r, http.MethodPost, "/auth/register", "", input, nil); code != http.StatusCreated {
+		t.Fatalf("регистрация: код %d", code)
+	}
+	var tokens tokenResponse
+	if code := call(r, http.MethodPost, "/auth/login", "", LoginInput{Email: input.Email, Password: input.Password}, &tokens); code != http.StatusOK {
+		t.Fatalf("вход: код %d", code)
+	}
+
+	if code := call(r, http.MethodPost, "/auth/reset-password-request", "", ResetPasswordRequestInput{Email: input.Email}, nil); code != http.StatusOK {
+		t.Fatalf("запрос сброса: код %d", code)
+	}
+	user, _ := userRepo.ByEmail(input.Email)
+	if emails := userRepo.Emails(); len(emails) != 1 || emails[0].To != input.Email || user.ResetPasswordToken == "" {
+		t.Fatalf("письмо со ссылкой для сброса: %+v, токен %q", emails, user.ResetPasswordToken)
+	}
+
+	reset := ResetPasswordInput{Token: user.ResetPasswordToken, Password: "new-secret"}
+	if code := call(r, http.MethodPost, "/auth/reset-password", "", reset, nil); code != http.StatusOK {
+		t.Fatalf("сброс пароля: код %d", code)
+	}
+	// Токен сброса одноразовый
+	if code := call(r, http.MethodPost, "/auth/reset-password", "", reset, nil); code != http.StatusNotFound {
+		t.Errorf("повторный сброс: код %d, ожидался 404", code)
+	}
+
+	if code := call(r, http.MethodGet, "/me", tokens.Token, nil, nil); code != http.StatusUnauthorized {
+		t.Errorf("access-токен после сброса: код %d, ожидался 401", code)
+	}
+	if code := call(r, http.MethodPost, "/auth/refresh", "", RefreshInput{RefreshToken: tokens.RefreshToken}, nil); code != http.StatusUnauthorized {
+		t.Errorf("refresh-токен после сброса: код %d, ожидался 401", code)
+	}
+	if code := call(r, http.MethodPost, "/auth/login", "", LoginInput{Email: input.Email, Password: input.Password}, nil); code != http.StatusUnauthorized {
+		t.Errorf("вход со старым паролем: код %d, ожидался 401", code)
+	}
+	if code := call(r, http.MethodPost, "/auth/login", "", LoginInput{Email: input.Email, Password: reset.Password}, nil); code != http.StatusOK {
+		t.Errorf("вход с новым паролем: код %d", code)
+	}
+}
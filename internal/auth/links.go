@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var (
+	linksMu     sync.RWMutex
+	backendURL  = "http://localhost:8080"
+	frontendURL = "http://localhost:3000"
+)
+
+// SetLinkURLs задаёт адреса, из которых строятся ссылки в письмах:
+// подтверждение почты ведёт на API, сброс пароля — на страницу фронтенда
+func SetLinkURLs(backend, frontend string) {
+	linksMu.Lock()
+	defer linksMu.Unlock()
+	backendURL = strings.TrimRight(backend, "/")
+	frontendURL = strings.TrimRight(frontend, "/")
+}
+
+func verificationLink(token string) string {
+	linksMu.RLock()
+	defer linksMu.RUnlock()
+	return backendURL + "/auth/verify?token=" + url.QueryEscape(token)
+}
+
+func resetPasswordLink(token string) string {
+	linksMu.RLock()
+	defer linksMu.RUnlock()
+	return frontendURL + "/auth/reset-password?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"errors"
+	"hotel-booking/internal/users"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemorySessionRepo хранит сессии в памяти; версии токенов берёт из репозитория пользователей.
+// Используется в тестах обработчиков.
+type MemorySessionRepo struct {
+	mu       sync.Mutex
+	users    users.UserRepo
+	sessions map[uint]Session
+	nextID   uint
+}
+
+func NewMemorySessionRepo(userRepo users.UserRepo) *MemorySessionRepo {
+	return &MemorySessionRepo{users: userRepo, sessions: map[uint]Session{}}
+}
+
+func (r *MemorySessionRepo) Create(session *Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.create(session)
+	return nil
+}
+
+func (r *MemorySessionRepo) create(session *Session) {
+	r.nextID++
+	session.ID = r.nextID
+	session.CreatedAt = time.Now()
+	r.sessions[session.ID] = *session
+}
+
+func (r *MemorySessionRepo) ByTokenHash(hash string) (Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, session := range r.sessions {
+		if session.TokenHash == hash {
+			return session, nil
+		}
+	}
+	return Session{}, gorm.ErrRecordNotFound
+}
+
+func (r *MemorySessionRepo) Rotate(old, next *Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.sessions[old.ID]
+	if !ok || stored.RevokedAt != nil {
+		return ErrSessionRevoked
+	}
+	r.create(next)
+	now := time.Now()
+	stored.RevokedAt, stored.ReplacedBy = &now, &next.ID
+	r.sessions[old.ID] = stored
+	*old = stored
+	return nil
+}
+
+func (r *MemorySessionRepo) RevokeByTokenHash(hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, session := range r.sessions {
+		if session.TokenHash == hash && session.RevokedAt == nil {
+			now := time.Now()
+			session.RevokedAt = &now
+			r.sessions[id] = session
+			return nil
+		}
+	}
+	return ErrInvalidRefreshToken
+}
+
+func (r *MemorySessionRepo) RevokeUser(userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for id, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			r.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func (r *MemorySessionRepo) Active(userID, sessionID uint, tokenVersion int) (bool, error) {
+	r.mu.Lock()
+	session, ok := r.sessions[sessionID]
+	r.mu.Unlock()
+	if !ok || session.UserID != userID || session.RevokedAt != nil || !time.Now().Before(session.ExpiresAt) {
+		return false, nil
+	}
+
+	user, err := r.users.ByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return user.TokenVersion == tokenVersion, nil
+}
+
+func (r *MemorySessionRepo) ResetPassword(user *users.User, passwordHash string) error {
+	if err := r.users.SetPassword(user, passwordHash); err != nil {
+		return err
+	}
+	return r.RevokeUser(user.ID)
+}
@@ -2,14 +2,13 @@ package auth
 
 import (
 	"net/http"
-	"os"
 	"strings"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware проверяет access-токен и по sessions — что его сессия ещё действует
+func AuthMiddleware(sessions SessionRepo) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -19,37 +18,40 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
-			// Check if it's a system token
-			systemToken, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				return []byte(os.Getenv("SYSTEM_TOKEN_SECRET")), nil
-			})
-
-			if err != nil || !systemToken.Valid {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный токен"})
-				c.Abort()
-				return
-			}
-
-			claims := systemToken.Claims.(jwt.MapClaims)
-			if claims["system"] != true {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный системный токен"})
-				c.Abort()
-				return
-			}
-
-			c.Set("system", true)
-			c.Next()
+		claims, err := Tokens().Parse(tokenString)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный токен"})
+			c.Abort()
+			return
+		}
+
+		userIDClaim, _ := claims["user_id"].(float64)
+		sessionIDClaim, hasSession := claims["sid"].(float64)
+		tokenVersionClaim, hasVersion := claims["tv"].(float64)
+		if !hasSession || !hasVersion {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный токен"})
+			c.Abort()
+			return
+		}
+
+		userID := uint(userIDClaim)
+		sessionID := uint(sessionIDClaim)
+
+		// Сессия могла быть отозвана, а роль или пароль — измениться после выдачи токена
+		active, err := sessions.Active(userID, sessionID, int(tokenVersionClaim))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка проверки сессии"})
+			c.Abort()
+			return
+		}
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Сессия завершена, войдите снова"})
+			c.Abort()
 			return
 		}
 
-		claims := token.Claims.(jwt.MapClaims)
-		userID := uint(claims["user_id"].(float64))
 		c.Set("user_id", userID)
+		c.Set("session_id", sessionID)
 		c.Set("role", claims["role"])
 
 		c.Next()
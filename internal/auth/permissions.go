@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Роли пользователей
+const (
+	RoleClient  = "client"
+	RoleOwner   = "owner"
+	RoleManager = "manager"
+	RoleAdmin   = "admin"
+)
+
+// Permission — действие, доступ к которому определяется ролью
+type Permission string
+
+const (
+	PermOfflineBookings Permission = "bookings:offline" // Офлайн-бронирования для гостей без аккаунта
+	PermHotelBookings   Permission = "bookings:hotel"   // Просмотр бронирований своих отелей
+	PermAllBookings     Permission = "bookings:all"     // Просмотр истории любого бронирования
+	PermBookingStatus   Permission = "bookings:status"  // Заселение, выезд, неявка и отмена отелем
+	PermManageHotels    Permission = "hotels:manage"    // Создание отелей и их политик отмены
+	PermManageStaff     Permission = "hotels:staff"     // Назначение менеджеров в штат отеля
+	PermManageRooms     Permission = "rooms:manage"     // Создание и удаление номеров, цены и политики отмены номеров
+	PermEditRooms       Permission = "rooms:edit"       // Изменение номеров и их фото
+	PermManageUsers     Permission = "users:manage"     // Список пользователей и смена ролей
+	PermManageOutbox    Permission = "outbox:manage"    // Просмотр и повторная отправка писем из outbox
+	PermMailDiagnostics Permission = "mail:diagnostics" // Проверка почтового транспорта и тестовые письма
+)
+
+// rolePermissions — матрица прав. Маршруты, доступные любому вошедшему пользователю,
+// разрешений не требуют; проверка владения отелем или членства в его штате
+// (hotels.CanManageHotel) остаётся в обработчиках.
+var rolePermissions = map[string][]Permission{
+	RoleClient:  {},
+	RoleOwner:   {PermOfflineBookings, PermHotelBookings, PermBookingStatus, PermManageHotels, PermManageStaff, PermManageRooms, PermEditRooms},
+	RoleManager: {PermOfflineBookings, PermHotelBookings, PermBookingStatus, PermEditRooms},
+	RoleAdmin:   {PermAllBookings, PermManageUsers, PermManageOutbox, PermMailDiagnostics},
+}
+
+// HasPermission проверяет, есть ли у роли разрешение
+func HasPermission(role string, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermission пропускает запрос, только если роль пользователя имеет разрешение.
+// Ставится после AuthMiddleware.
+func RequirePermission(permission Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasPermission(c.GetString("role"), permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole пропускает запрос, только если у пользователя одна из ролей roles.
+// Ставится после AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав"})
+		c.Abort()
+	}
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"hotel-booking/internal/users"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SessionRepo — доступ к сессиям входа. Не найденная сессия возвращается
+// как gorm.ErrRecordNotFound в обеих реализациях.
+type SessionRepo interface {
+	Create(session *Session) error
+	ByTokenHash(hash string) (Session, error)
+	// Rotate сохраняет сессию next и закрывает old со ссылкой на next в одной транзакции.
+	// Если old уже закрыта параллельным запросом, next не сохраняется и возвращается ErrSessionRevoked.
+	Rotate(old, next *Session) error
+	// RevokeByTokenHash закрывает действующую сессию; ErrInvalidRefreshToken, если такой нет
+	RevokeByTokenHash(hash string) error
+	// RevokeUser закрывает все действующие сессии пользователя
+	RevokeUser(userID uint) error
+	// Active проверяет, что сессия не отозвана и не истекла, а версия токенов
+	// пользователя не менялась после выдачи access-токена
+	Active(userID, sessionID uint, tokenVersion int) (bool, error)
+	// ResetPassword меняет пароль пользователя и закрывает все его сессии в одной транзакции
+	ResetPassword(user *users.User, passwordHash string) error
+}
+
+// GormSessionRepo хранит сессии в базе данных
+type GormSessionRepo struct {
+	db *gorm.DB
+}
+
+func NewGormSessionRepo(db *gorm.DB) *GormSessionRepo {
+	return &GormSessionRepo{db: db}
+}
+
+func (r *GormSessionRepo) Create(session *Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *GormSessionRepo) ByTokenHash(hash string) (Session, error) {
+	var session Session
+	err := r.db.Where("token_hash = ?", hash).First(&session).Error
+	return session, err
+}
+
+func (r *GormSessionRepo) Rotate(old, next *Session) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+
+		// Условие revoked_at IS NULL не даёт двум параллельным запросам обменять один токен
+		now := time.Now()
+		result := tx.Model(&Session{}).
+			Where("id = ? AND revoked_at IS NULL", old.ID).
+			Updates(map[string]interface{}{"revoked_at": now, "replaced_by": next.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSessionRevoked
+		}
+		old.RevokedAt, old.ReplacedBy = &now, &next.ID
+		return nil
+	})
+}
+
+func (r *GormSessionRepo) RevokeByTokenHash(hash string) error {
+	result := r.db.Model(&Session{}).
+		Where("token_hash = ? AND revoked_at IS NULL", hash).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidRefreshToken
+	}
+	return nil
+}
+
+func (r *GormSessionRepo) RevokeUser(userID uint) error {
+	return revokeUserSessions(r.db, userID)
+}
+
+func (r *GormSessionRepo) Active(userID, sessionID uint, tokenVersion int) (bool, error) {
+	var count int64
+	err := r.db.Table("sessions").
+		Joins("JOIN users ON users.id = sessions.user_id").
+		Where("sessions.id = ? AND sessions.user_id = ? AND sessions.revoked_at IS NULL AND sessions.expires_at > ?",
+			sessionID, userID, time.Now()).
+		Where("users.token_version = ? AND users.deleted_at IS NULL", tokenVersion).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *GormSessionRepo) ResetPassword(user *users.User, passwordHash string) error {
+	stored := *user
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := users.NewGormUserRepo(tx).SetPassword(&stored, passwordHash); err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+	*user = stored
+	return nil
+}
+
+// revokeUserSessions отзывает все действующие сессии пользователя
+func revokeUserSessions(tx *gorm.DB, userID uint) error {
+	return tx.Model(&Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
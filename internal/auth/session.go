@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hotel-booking/internal/users"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute    // Время жизни access-токена
+	refreshTokenTTL = 30 * 24 * time.Hour // Время жизни refresh-токена
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh-токен недействителен")
+	ErrSessionRevoked      = errors.New("сессия отозвана")
+)
+
+// Session — сессия входа пользователя. Хранит хеш refresh-токена;
+// при каждом обновлении сессия закрывается и вместо неё создаётся новая.
+type Session struct {
+	ID         uint       `gorm:"primarykey"`
+	UserID     uint       `gorm:"not null;index"`
+	TokenHash  string     `gorm:"type:varchar(64);not null;uniqueIndex"` // SHA-256 refresh-токена
+	ExpiresAt  time.Time  `gorm:"not null"`
+	RevokedAt  *time.Time // Время отзыва: выход, обновление токена или сброс пароля
+	ReplacedBy *uint      // Сессия, созданная при обновлении токена
+	UserAgent  string     `gorm:"type:varchar(255)"`
+	IP         string     `gorm:"type:varchar(45)"`
+	CreatedAt  time.Time
+}
+
+// TokenPair — access- и refresh-токены, выдаваемые при входе и обновлении
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// newSession готовит сессию пользователя и refresh-токен для неё; сессия ещё не сохранена
+func newSession(user users.User, userAgent, ip string) (*Session, string, error) {
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	return &Session{
+		UserID:    user.ID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+		UserAgent: userAgent,
+		IP:        ip,
+	}, refreshToken, nil
+}
+
+// startSession создаёт сессию пользователя и выдаёт для неё пару токенов
+func (h *Handler) startSession(user users.User, userAgent, ip string) (*Session, TokenPair, error) {
+	session, refreshToken, err := newSession(user, userAgent, ip)
+	if err != nil {
+		return nil, TokenPair{}, err
+	}
+	if err := h.Sessions.Create(session); err != nil {
+		return nil, TokenPair{}, err
+	}
+
+	accessToken, err := GenerateJWT(user.ID, user.Role, session.ID, user.TokenVersion)
+	if err != nil {
+		return nil, TokenPair{}, err
+	}
+	return session, TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// rotateSession обменивает refresh-токен на новую пару токенов.
+// Повторное использование уже обменянного токена означает его утечку:
+// тогда отзываются все сессии пользователя.
+func (h *Handler) rotateSession(refreshToken, userAgent, ip string) (users.User, TokenPair, error) {
+	session, err := h.Sessions.ByTokenHash(hashToken(refreshToken))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return users.User{}, TokenPair{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return users.User{}, TokenPair{}, err
+	}
+	if session.RevokedAt != nil {
+		if session.ReplacedBy != nil {
+			if err := h.Sessions.RevokeUser(session.UserID); err != nil {
+				return users.User{}, TokenPair{}, err
+			}
+		}
+		return users.User{}, TokenPair{}, ErrSessionRevoked
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return users.User{}, TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	user, err := h.Users.ByID(session.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return users.User{}, TokenPair{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return users.User{}, TokenPair{}, err
+	}
+
+	next, nextToken, err := newSession(user, userAgent, ip)
+	if err != nil {
+		return users.User{}, TokenPair{}, err
+	}
+	if err := h.Sessions.Rotate(&session, next); err != nil {
+		return users.User{}, TokenPair{}, err
+	}
+
+	accessToken, err := GenerateJWT(user.ID, user.Role, next.ID, user.TokenVersion)
+	if err != nil {
+		return users.User{}, TokenPair{}, err
+	}
+	return user, TokenPair{AccessToken: accessToken, RefreshToken: nextToken}, nil
+}
@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"errors"
+	"hotel-booking/internal/users"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// loggedIn создаёт пользователя и открывает для него сессию
+func loggedIn(t *testing.T, h *Handler) (users.User, TokenPair) {
+	t.Helper()
+
+	user := users.User{Name: "Иван", Email: "ivan@example.com", Phone: "+79990000001", Password: "hash"}
+	if err := h.Users.Create(&user); err != nil {
+		t.Fatal(err)
+	}
+	_, tokens, err := h.startSession(user, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user, tokens
+}
+
+func TestRotateSessionIssuesNewPair(t *testing.T) {
+	h, _, r := newTestHandler(t)
+	_, first := loggedIn(t, h)
+
+	_, second, err := h.rotateSession(first.RefreshToken, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Fatal("обновление вернуло прежние токены")
+	}
+
+	// Access-токен обменянной сессии больше не принимается, новый — принимается
+	if code := call(r, http.MethodGet, "/me", first.AccessToken, nil, nil); code != http.StatusUnauthorized {
+		t.Errorf("access-токен обменянной сессии: код %d, ожидался 401", code)
+	}
+	if code := call(r, http.MethodGet, "/me", second.AccessToken, nil, nil); code != http.StatusOK {
+		t.Errorf("новый access-токен: код %d", code)
+	}
+
+	if _, _, err := h.rotateSession("unknown", "test", "127.0.0.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("неизвестный refresh-токен: %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesAllSessions(t *testing.T) {
+	h, _, r := newTestHandler(t)
+	user, stolen := loggedIn(t, h)
+
+	// Вторая сессия того же пользователя, например на другом устройстве
+	_, other, err := h.startSession(user, "phone", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, rotated, err := h.rotateSession(stolen.RefreshToken, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Уже обменянный токен предъявлен снова — считаем его украденным
+	if _, _, err := h.rotateSession(stolen.RefreshToken, "attacker", "192.0.2.1"); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("повторное использование: %v, ожидалась ErrSessionRevoked", err)
+	}
+
+	for name, tokens := range map[string]TokenPair{"после обмена": rotated, "другое устройство": other} {
+		if code := call(r, http.MethodGet, "/me", tokens.AccessToken, nil, nil); code != http.StatusUnauthorized {
+			t.Errorf("%s: access-токен принят, код %d", name, code)
+		}
+		if _, _, err := h.rotateSession(tokens.RefreshToken, "test", "127.0.0.1"); !errors.Is(err, ErrSessionRevoked) {
+			t.Errorf("%s: refresh-токен: %v, ожидалась ErrSessionRevoked", name, err)
+		}
+	}
+}
+
+func TestConcurrentRotationIssuesOnePair(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	_, tokens := loggedIn(t, h)
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	results := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := h.rotateSession(tokens.RefreshToken, "test", "127.0.0.1")
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrSessionRevoked):
+			t.Errorf("неожиданная ошибка: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("успешных обменов %d, ожидался один", succeeded)
+	}
+}
+
+func TestRotateGuardKeepsRevokedSession(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	_, tokens := loggedIn(t, h)
+
+	session, err := h.Sessions.ByTokenHash(hashToken(tokens.RefreshToken))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Параллельный запрос уже закрыл сессию между чтением и обменом
+	stale := session
+	if err := h.Sessions.RevokeByTokenHash(hashToken(tokens.RefreshToken)); err != nil {
+		t.Fatal(err)
+	}
+
+	next := &Session{UserID: session.UserID, TokenHash: hashToken("next"), ExpiresAt: session.ExpiresAt}
+	if err := h.Sessions.Rotate(&stale, next); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("обмен закрытой сессии: %v, ожидалась ErrSessionRevoked", err)
+	}
+	if _, err := h.Sessions.ByTokenHash(next.TokenHash); err == nil {
+		t.Fatal("сессия для отклонённого обмена сохранена")
+	}
+}
+
+func TestTokenVersionChangeEndsAccessTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(h *Handler, user *users.User) error
+	}{
+		{"смена роли", func(h *Handler, user *users.User) error {
+			return h.Users.UpdateRole(user, RoleOwner)
+		}},
+		{"сброс пароля", func(h *Handler, user *users.User) error {
+			return h.Sessions.ResetPassword(user, "new-hash")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, r := newTestHandler(t)
+			user, tokens := loggedIn(t, h)
+			if code := call(r, http.MethodGet, "/me", tokens.AccessToken, nil, nil); code != http.StatusOK {
+				t.Fatalf("до изменения: код %d", code)
+			}
+
+			if err := tt.change(h, &user); err != nil {
+				t.Fatal(err)
+			}
+			if code := call(r, http.MethodGet, "/me", tokens.AccessToken, nil, nil); code != http.StatusUnauthorized {
+				t.Errorf("access-токен со старой версией: код %d, ожидался 401", code)
+			}
+
+			// Новый вход выдаёт токен с текущей версией
+			_, fresh, err := h.startSession(user, "test", "127.0.0.1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code := call(r, http.MethodGet, "/me", fresh.AccessToken, nil, nil); code != http.StatusOK {
+				t.Errorf("новый access-токен: код %d", code)
+			}
+		})
+	}
+}
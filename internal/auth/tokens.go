@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("неверный токен")
+
+// SigningKey — ключ подписи токенов. Ключ без Private (только публичный)
+// используется лишь для проверки токенов, выданных до ротации.
+type SigningKey struct {
+	ID      string // kid в заголовке токена
+	Method  jwt.SigningMethod
+	Private interface{} // []byte для HS256, *rsa.PrivateKey или ed25519.PrivateKey
+	Public  interface{} // []byte для HS256, *rsa.PublicKey или ed25519.PublicKey
+}
+
+// TokenService подписывает и проверяет JWT. Подписывает активным ключом,
+// проверяет любым известным ключом по kid; алгоритм токена должен совпадать с алгоритмом ключа.
+type TokenService struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+	algs   []string
+}
+
+// NewTokenService создаёт сервис с ключами keys; activeID — kid ключа для подписи новых токенов
+func NewTokenService(activeID string, keys ...*SigningKey) (*TokenService, error) {
+	s := &TokenService{keys: make(map[string]*SigningKey, len(keys))}
+	seenAlgs := map[string]bool{}
+	for _, key := range keys {
+		if _, ok := s.keys[key.ID]; ok {
+			return nil, fmt.Errorf("ключ %q указан дважды", key.ID)
+		}
+		s.keys[key.ID] = key
+		if alg := key.Method.Alg(); !seenAlgs[alg] {
+			seenAlgs[alg] = true
+			s.algs = append(s.algs, alg)
+		}
+	}
+
+	active, ok := s.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("активный ключ %q не найден", activeID)
+	}
+	if active.Private == nil {
+		return nil, fmt.Errorf("для активного ключа %q нет закрытого ключа", activeID)
+	}
+	s.active = active
+	return s, nil
+}
+
+// Sign подписывает claims активным ключом и указывает его kid в заголовке
+func (s *TokenService) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(s.active.Method, claims)
+	token.Header["kid"] = s.active.ID
+	return token.SignedString(s.active.Private)
+}
+
+// Parse проверяет подпись и срок действия токена и возвращает его claims
+func (s *TokenService) Parse(tokenString string) (jwt.MapClaims, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods(s.algs))
+	token, err := parser.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := s.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("неизвестный ключ %q", kid)
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("алгоритм %s не соответствует ключу %q", token.Method.Alg(), kid)
+		}
+		return key.Public, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// JWK — открытый ключ в формате RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS возвращает открытые ключи сервиса. Симметричные HS256-ключи не публикуются.
+func (s *TokenService) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range s.keys {
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// LoadSigningKey читает ключ kid алгоритма alg из файла path.
+// Для HS256 файл содержит секрет, для RS256 и EdDSA — PEM закрытого или открытого ключа.
+func LoadSigningKey(kid, alg, path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ключ %q: %w", kid, err)
+	}
+
+	key := &SigningKey{ID: kid}
+	switch alg {
+	case "HS256":
+		secret := []byte(strings.TrimSpace(string(data)))
+		if len(secret) == 0 {
+			return nil, fmt.Errorf("ключ %q: пустой секрет", kid)
+		}
+		key.Method, key.Private, key.Public = jwt.SigningMethodHS256, secret, secret
+	case "RS256":
+		key.Method = jwt.SigningMethodRS256
+		if private, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			key.Private, key.Public = private, &private.PublicKey
+		} else if public, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+			key.Public = public
+		} else {
+			return nil, fmt.Errorf("ключ %q: не удалось разобрать RSA-ключ", kid)
+		}
+	case "EdDSA":
+		key.Method = jwt.SigningMethodEdDSA
+		if private, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			key.Private, key.Public = private, private.(crypto.Signer).Public()
+		} else if public, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+			key.Public = public
+		} else {
+			return nil, fmt.Errorf("ключ %q: не удалось разобрать Ed25519-ключ", kid)
+		}
+	default:
+		return nil, fmt.Errorf("ключ %q: неподдерживаемый алгоритм %q", kid, alg)
+	}
+	return key, nil
+}
+
+// TokenServiceFromSpec собирает сервис из настроек:
+//   - spec — ключи через запятую в формате kid:alg:путь (alg: HS256, RS256, EdDSA);
+//   - activeID — kid ключа для подписи, по умолчанию первый из spec;
+//   - secret — HS256-секрет с kid "default", если spec пуст.
+func TokenServiceFromSpec(spec, secret, activeID string) (*TokenService, error) {
+	if spec == "" {
+		if secret == "" {
+			return nil, errors.New("не задан JWT_KEYS или JWT_KEY")
+		}
+		key := &SigningKey{ID: "default", Method: jwt.SigningMethodHS256, Private: []byte(secret), Public: []byte(secret)}
+		return NewTokenService(key.ID, key)
+	}
+
+	var keys []*SigningKey
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("JWT_KEYS: ожидается kid:alg:путь, получено %q", entry)
+		}
+		key, err := LoadSigningKey(parts[0], parts[1], parts[2])
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if activeID == "" {
+		activeID = keys[0].ID
+	}
+	return NewTokenService(activeID, keys...)
+}
+
+var (
+	tokensMu sync.RWMutex
+	tokens   *TokenService
+)
+
+// SetTokenService задаёт сервис, которым auth подписывает и проверяет токены
+func SetTokenService(s *TokenService) {
+	tokensMu.Lock()
+	defer tokensMu.Unlock()
+	tokens = s
+}
+
+// Tokens возвращает текущий сервис токенов
+func Tokens() *TokenService {
+	tokensMu.RLock()
+	defer tokensMu.RUnlock()
+	return tokens
+}
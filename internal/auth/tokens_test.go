@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKeys — ключи всех поддерживаемых алгоритмов с закрытой частью
+type testKeys struct {
+	hs  *SigningKey
+	rsa *SigningKey
+	ed  *SigningKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("hs-secret")
+	return testKeys{
+		hs:  &SigningKey{ID: "hs", Method: jwt.SigningMethodHS256, Private: secret, Public: secret},
+		rsa: &SigningKey{ID: "rsa", Method: jwt.SigningMethodRS256, Private: rsaKey, Public: &rsaKey.PublicKey},
+		ed:  &SigningKey{ID: "ed", Method: jwt.SigningMethodEdDSA, Private: edPrivate, Public: edPublic},
+	}
+}
+
+// publicOnly возвращает копию ключа без закрытой части, как после ротации
+func publicOnly(key *SigningKey) *SigningKey {
+	return &SigningKey{ID: key.ID, Method: key.Method, Public: key.Public}
+}
+
+// signWith подписывает claims методом method и секретом secret с заголовком kid в обход сервиса
+func signWith(t *testing.T, method jwt.SigningMethod, kid string, secret interface{}) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, jwt.MapClaims{"user_id": 1, "exp": time.Now().Add(time.Minute).Unix()})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestTokenServiceParse(t *testing.T) {
+	keys := newTestKeys(t)
+
+	// Старый RSA-ключ выведен из подписи, но токены, выданные им, ещё проверяются
+	old, err := NewTokenService("rsa", keys.rsa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuedBeforeRotation, err := old.Sign(jwt.MapClaims{"user_id": 1, "exp": time.Now().Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := NewTokenService("ed", keys.hs, publicOnly(keys.rsa), keys.ed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := service.Sign(jwt.MapClaims{"user_id": 1, "exp": time.Now().Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Открытый ключ RSA в PEM: его можно использовать как HMAC-секрет, если не сверять алгоритм
+	publicDER, err := x509.MarshalPKIXPublicKey(keys.rsa.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"активный ключ", current, true},
+		{"ключ до ротации без закрытой части", issuedBeforeRotation, true},
+		{"HS256-ключ по своему kid", signWith(t, jwt.SigningMethodHS256, "hs", []byte("hs-secret")), true},
+		{"HS256 с kid RSA-ключа", signWith(t, jwt.SigningMethodHS256, "rsa", publicPEM), false},
+		{"HS256 с kid Ed25519-ключа", signWith(t, jwt.SigningMethodHS256, "ed", []byte("hs-secret")), false},
+		{"HS256 с секретом HS-ключа, но kid RSA", signWith(t, jwt.SigningMethodHS256, "rsa", []byte("hs-secret")), false},
+		{"неизвестный kid", signWith(t, jwt.SigningMethodHS256, "unknown", []byte("hs-secret")), false},
+		{"без kid", signWith(t, jwt.SigningMethodHS256, "", []byte("hs-secret")), false},
+		{"чужой секрет", signWith(t, jwt.SigningMethodHS256, "hs", []byte("other-secret")), false},
+		{"alg none", signWith(t, jwt.SigningMethodNone, "hs", jwt.UnsafeAllowNoneSignatureType), false},
+		{"испорченная подпись", current[:len(current)-4] + "AAAA", false},
+		{"не JWT", "not-a-token", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := service.Parse(tt.token)
+			if tt.valid {
+				if err != nil || claims["user_id"] != float64(1) {
+					t.Fatalf("токен отклонён: %v, %v", claims, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("токен принят: %v, %v", claims, err)
+			}
+		})
+	}
+}
+
+func TestTokenServiceRejectsExpiredToken(t *testing.T) {
+	keys := newTestKeys(t)
+	service, err := NewTokenService("hs", keys.hs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := service.Sign(jwt.MapClaims{"user_id": 1, "exp": time.Now().Add(-time.Minute).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Parse(expired); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("истёкший токен: %v", err)
+	}
+}
+
+func TestNewTokenService(t *testing.T) {
+	keys := newTestKeys(t)
+
+	tests := []struct {
+		name     string
+		activeID string
+		keys     []*SigningKey
+		wantErr  string
+	}{
+		{"активный ключ с закрытой частью", "rsa", []*SigningKey{keys.rsa, publicOnly(keys.ed)}, ""},
+		{"активный ключ без закрытой части", "rsa", []*SigningKey{publicOnly(keys.rsa), keys.ed}, "нет закрытого ключа"},
+		{"активный ключ не указан среди ключей", "missing", []*SigningKey{keys.hs}, "не найден"},
+		{"kid повторяется", "hs", []*SigningKey{keys.hs, keys.hs}, "указан дважды"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, err := NewTokenService(tt.activeID, tt.keys...)
+			if tt.wantErr == "" {
+				if err != nil || service == nil {
+					t.Fatalf("ошибка: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ошибка %v, ожидалась с текстом %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	keys := newTestKeys(t)
+	service, err := NewTokenService("rsa", keys.hs, keys.rsa, publicOnly(keys.ed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := service.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("ключей %d, ожидалось 2 (HS256 не публикуется): %+v", len(set.Keys), set.Keys)
+	}
+	decode := func(value string) []byte {
+		t.Helper()
+		b, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil {
+			t.Fatalf("%q: %v", value, err)
+		}
+		return b
+	}
+
+	// Ключи отсортированы по kid
+	ed, rsaJWK := set.Keys[0], set.Keys[1]
+
+	rsaPublic := keys.rsa.Public.(*rsa.PublicKey)
+	if rsaJWK.Kty != "RSA" || rsaJWK.Kid != "rsa" || rsaJWK.Alg != "RS256" || rsaJWK.Use != "sig" || rsaJWK.Crv != "" || rsaJWK.X != "" {
+		t.Errorf("RSA-ключ: %+v", rsaJWK)
+	}
+	if new(big.Int).SetBytes(decode(rsaJWK.N)).Cmp(rsaPublic.N) != 0 || new(big.Int).SetBytes(decode(rsaJWK.E)).Int64() != int64(rsaPublic.E) {
+		t.Errorf("RSA-ключ: n или e не совпадают с открытым ключом")
+	}
+
+	if ed.Kty != "OKP" || ed.Kid != "ed" || ed.Alg != "EdDSA" || ed.Use != "sig" || ed.Crv != "Ed25519" || ed.N != "" || ed.E != "" {
+		t.Errorf("Ed25519-ключ: %+v", ed)
+	}
+	if string(decode(ed.X)) != string(keys.ed.Public.(ed25519.PublicKey)) {
+		t.Errorf("Ed25519-ключ: x не совпадает с открытым ключом")
+	}
+}
@@ -0,0 +1,55 @@
+package bookings
+
+import (
+	"errors"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/pricing"
+	"time"
+)
+
+var ErrRoomUnavailable = errors.New("номер уже забронирован в этот период")
+
+// normalizeDates приводит даты заезда и выезда к календарным датам (pricing.Date),
+// чтобы ночи нового бронирования и сохранённых считались в одном поясе
+func normalizeDates(booking *Booking) {
+	booking.StartDate = pricing.Date(booking.StartDate)
+	booking.EndDate = pricing.Date(booking.EndDate)
+}
+
+// nightlyOccupancy считает, сколько номеров занято бронированиями overlapping в каждую ночь периода
+func nightlyOccupancy(overlapping []Booking, start, end time.Time) map[time.Time]int {
+	occupancy := make(map[time.Time]int)
+	for _, night := range pricing.Nights(start, end) {
+		occupancy[night] = 0
+	}
+	for _, booking := range overlapping {
+		for _, night := range pricing.Nights(booking.StartDate, booking.EndDate) {
+			if _, ok := occupancy[night]; ok {
+				occupancy[night]++
+			}
+		}
+	}
+	return occupancy
+}
+
+// freeUnits возвращает число свободных номеров типа room в каждую ночь по занятости occupancy
+func freeUnits(room hotels.Room, occupancy map[time.Time]int) map[time.Time]int {
+	available := make(map[time.Time]int, len(occupancy))
+	for night, occupied := range occupancy {
+		free := room.Units - occupied
+		if free < 0 {
+			free = 0
+		}
+		available[night] = free
+	}
+	return available
+}
+
+// AvailableUnits возвращает число свободных номеров типа room в каждую ночь периода
+func AvailableUnits(repo BookingRepo, room hotels.Room, start, end time.Time) (map[time.Time]int, error) {
+	overlapping, err := repo.Overlapping(room.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return freeUnits(room, nightlyOccupancy(overlapping, start, end)), nil
+}
@@ -0,0 +1,241 @@
+package bookings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/migrations"
+	"hotel-booking/internal/pricing"
+	"hotel-booking/internal/storage"
+	"hotel-booking/internal/testdb"
+	"hotel-booking/internal/users"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const parallelRequests = 20
+
+func TestMain(m *testing.M) {
+	os.Exit(testdb.Run(m))
+}
+
+// setupTestDB подключается к базе из testdb и применяет миграции
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	db := testdb.Open(t)
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatalf("загрузка миграций: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("миграция: %v", err)
+	}
+	storage.DB = db
+}
+
+// createTestRoom создаёт владельца, отель и тип номера с units номерами
+func createTestRoom(t *testing.T, units int) (users.User, hotels.Room) {
+	t.Helper()
+
+	suffix := time.Now().UnixNano() % 1_000_000_000
+	owner := users.User{
+		Name:     "Владелец",
+		Email:    fmt.Sprintf("owner%d@example.com", suffix),
+		Password: "hash",
+		Phone:    fmt.Sprintf("+7%010d", suffix),
+		Role:     "owner",
+	}
+	if err := storage.DB.Create(&owner).Error; err != nil {
+		t.Fatalf("создание владельца: %v", err)
+	}
+
+	hotel := hotels.Hotel{Name: "Отель", Address: "Адрес", OwnerID: owner.ID}
+	if err := storage.DB.Create(&hotel).Error; err != nil {
+		t.Fatalf("создание отеля: %v", err)
+	}
+
+	room := hotels.Room{HotelID: hotel.ID, RoomType: "standard", Price: 1000, Capacity: 2, Units: units}
+	if err := storage.DB.Create(&room).Error; err != nil {
+		t.Fatalf("создание номера: %v", err)
+	}
+	return owner, room
+}
+
+func countActiveBookings(t *testing.T, roomID uint) int64 {
+	t.Helper()
+
+	var count int64
+	if err := storage.DB.Model(&Booking{}).Where("room_id = ? AND status IN ?", roomID, ActiveStatuses).Count(&count).Error; err != nil {
+		t.Fatalf("подсчёт бронирований: %v", err)
+	}
+	return count
+}
+
+func TestCreateBookingConcurrent(t *testing.T) {
+	setupTestDB(t)
+
+	for _, units := range []int{1, 3} {
+		t.Run(fmt.Sprintf("units=%d", units), func(t *testing.T) {
+			owner, room := createTestRoom(t, units)
+			start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+
+			var (
+				wg          sync.WaitGroup
+				mu          sync.Mutex
+				created     int
+				unavailable int
+			)
+			for i := 0; i < parallelRequests; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+
+					// Все периоды пересекаются в первую ночь
+					booking := Booking{
+						RoomID:    room.ID,
+						UserID:    owner.ID,
+						StartDate: start,
+						EndDate:   start.Add(time.Duration(i+1) * 24 * time.Hour),
+						TotalCost: 1000,
+						Status:    StatusPendingPayment,
+						CreatedAt: time.Now(),
+					}
+					err := NewGormBookingRepo(storage.DB).Create(&booking, UserActor(owner.ID), "тест")
+
+					mu.Lock()
+					defer mu.Unlock()
+					switch {
+					case err == nil:
+						created++
+					case errors.Is(err, ErrRoomUnavailable):
+						unavailable++
+					default:
+						t.Errorf("неожиданная ошибка: %v", err)
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			if created != units || unavailable != parallelRequests-units {
+				t.Fatalf("создано %d, отказано %d; ожидалось %d и %d", created, unavailable, units, parallelRequests-units)
+			}
+			if count := countActiveBookings(t, room.ID); count != int64(units) {
+				t.Fatalf("активных бронирований в базе %d, ожидалось %d", count, units)
+			}
+		})
+	}
+}
+
+func TestCreateOfflineBookingHandlerConcurrent(t *testing.T) {
+	setupTestDB(t)
+	owner, room := createTestRoom(t, 1)
+	gin.SetMode(gin.TestMode)
+
+	h := NewHandler(NewGormBookingRepo(storage.DB), hotels.NewGormHotelRepo(storage.DB), hotels.NewGormRoomRepo(storage.DB),
+		users.NewGormUserRepo(storage.DB), pricing.Quoter(pricing.NewGormPriceRepo(storage.DB), hotels.NewGormHotelRepo(storage.DB)))
+	r := gin.New()
+	r.POST("/booking/offline", func(c *gin.Context) {
+		c.Set("user_id", owner.ID)
+		c.Set("role", "owner")
+	}, h.CreateOfflineBookingHandler)
+
+	// Гостя с этим телефоном ещё нет: все запросы пытаются его создать
+	phone := fmt.Sprintf("+8%010d", time.Now().UnixNano()%1_000_000_000)
+	start := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	body, _ := json.Marshal(CreateOfflineBookingInput{
+		RoomID:      room.ID,
+		StartDate:   start,
+		EndDate:     start.Add(48 * time.Hour),
+		PhoneNumber: phone,
+		Name:        "Гость",
+	})
+
+	codes := make(chan int, parallelRequests)
+	var wg sync.WaitGroup
+	for i := 0; i < parallelRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/booking/offline", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			codes <- w.Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	counts := map[int]int{}
+	for code := range codes {
+		counts[code]++
+	}
+	if counts[http.StatusCreated] != 1 || counts[http.StatusConflict] != parallelRequests-1 {
+		t.Fatalf("ответы %v; ожидался один 201 и %d ответов 409", counts, parallelRequests-1)
+	}
+	if count := countActiveBookings(t, room.ID); count != 1 {
+		t.Fatalf("активных бронирований в базе %d, ожидалось 1", count)
+	}
+	var guests int64
+	if err := storage.DB.Model(&users.User{}).Where("phone = ?", phone).Count(&guests).Error; err != nil || guests != 1 {
+		t.Fatalf("гостей с телефоном %s: %d, %v; ожидался один", phone, guests, err)
+	}
+}
+
+func TestCreateCountsNightsAcrossTimeZones(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	newYork := time.FixedZone("EST", -5*60*60)
+
+	store := hotels.NewMemory()
+	room := hotels.Room{HotelID: 1, Price: 1000, Capacity: 2, Units: 1}
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewMemoryBookingRepo(store.Rooms(), users.NewMemoryUserRepo())
+
+	// Клиент из Москвы бронирует ночь на 2 июня
+	first := Booking{RoomID: room.ID, UserID: 1,
+		StartDate: time.Date(2030, 6, 2, 0, 0, 0, 0, moscow), EndDate: time.Date(2030, 6, 3, 0, 0, 0, 0, moscow)}
+	if err := repo.Create(&first, SystemActor, "тест"); err != nil {
+		t.Fatal(err)
+	}
+	if !first.StartDate.Equal(time.Date(2030, 6, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("дата заезда сохранена как %s, ожидалась полночь 2 июня UTC", first.StartDate)
+	}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		err        error
+	}{
+		{"та же ночь в UTC", time.Date(2030, 6, 2, 0, 0, 0, 0, time.UTC), time.Date(2030, 6, 3, 0, 0, 0, 0, time.UTC), ErrRoomUnavailable},
+		{"та же ночь в поясе -05:00", time.Date(2030, 6, 2, 0, 0, 0, 0, newYork), time.Date(2030, 6, 3, 0, 0, 0, 0, newYork), ErrRoomUnavailable},
+		{"следующая ночь", time.Date(2030, 6, 3, 0, 0, 0, 0, newYork), time.Date(2030, 6, 4, 0, 0, 0, 0, newYork), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := Booking{RoomID: room.ID, UserID: 2, StartDate: tt.start, EndDate: tt.end}
+			if err := repo.Create(&booking, SystemActor, "тест"); !errors.Is(err, tt.err) {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.err)
+			}
+		})
+	}
+
+	// Бронирование из базы приходит в поясе сервера: полночь UTC там — ещё предыдущий день
+	loaded := first
+	loaded.StartDate, loaded.EndDate = first.StartDate.In(newYork), first.EndDate.In(newYork)
+	night := time.Date(2030, 6, 2, 0, 0, 0, 0, time.UTC)
+	occupancy := nightlyOccupancy([]Booking{loaded}, night, night.AddDate(0, 0, 1))
+	if occupancy[night] != 1 {
+		t.Fatalf("занятость ночи 2 июня: %v, ожидался 1 номер", occupancy)
+	}
+}
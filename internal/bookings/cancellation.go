@@ -0,0 +1,164 @@
+package bookings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/logging"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// Статусы оплаты бронирования (см. payments.StatusSucceeded и payments.StatusRefundPending)
+const (
+	paymentSucceeded     = "succeeded"      // деньги можно вернуть
+	paymentRefundPending = "refund_pending" // возврат уже выполняется
+)
+
+var (
+	ErrNotRefundable = errors.New("по условиям отмены возврат не положен")
+	ErrRefundFailed  = errors.New("платёжная система не выполнила возврат")
+	// ErrRefundInProgress — возврат по бронированию уже выполняет другой запрос
+	ErrRefundInProgress = errors.New("возврат по бронированию уже выполняется")
+)
+
+// PaymentGateway — операции платёжной системы, которые нужны бронированиям.
+// Реализуется пакетом payments и подключается в main через Handler.Payments.
+type PaymentGateway interface {
+	// CreatePayment создаёт платёж за новое бронирование и возвращает его ID и ссылку на оплату.
+	// ID в бронировании не сохраняется: это делает вызывающий вместе с письмом гостю.
+	CreatePayment(ctx context.Context, booking *Booking) (paymentID, paymentURL string, err error)
+	// Refund возвращает amount по платежу paymentID. Повтор с тем же idempotenceKey
+	// не возвращает деньги второй раз, а отдаёт результат первого запроса.
+	Refund(ctx context.Context, paymentID string, amount float64, idempotenceKey string) error
+}
+
+// RefundKey — ключ идемпотентности возврата по бронированию: по одному бронированию
+// деньги возвращаются один раз, сколько бы раз ни повторялся запрос
+func RefundKey(bookingID uint) string {
+	return fmt.Sprintf("refund-%d", bookingID)
+}
+
+// RefundableAmount считает, сколько из total вернуть гостю при отмене в момент now
+// для заезда startDate. Политика nil означает бесплатную отмену.
+func (p *CancellationPolicy) RefundableAmount(total float64, startDate, now time.Time) float64 {
+	if p == nil {
+		return total
+	}
+	if p.NonRefundable {
+		return 0
+	}
+	if !now.Add(time.Duration(p.FreeUntilDays) * 24 * time.Hour).After(startDate) {
+		return total
+	}
+	return math.Round(total*(100-p.PenaltyPercent)) / 100
+}
+
+// RefundableAmount считает сумму возврата по бронированию по текущей политике отмены номера.
+// Если сумма уже определена при отмене, возвращает её: повтор возврата не зависит от now.
+func RefundableAmount(repo BookingRepo, rooms hotels.RoomRepo, booking Booking, now time.Time) (float64, error) {
+	if booking.RefundAmount != nil {
+		return *booking.RefundAmount, nil
+	}
+	room, err := rooms.ByIDUnscoped(booking.RoomID)
+	if err != nil {
+		return 0, err
+	}
+	policy, err := repo.PolicyFor(room)
+	if err != nil {
+		return 0, err
+	}
+	return policy.RefundableAmount(booking.TotalCost, booking.StartDate, now), nil
+}
+
+// CancelWithRefund отменяет бронирование гостем и возвращает через g сумму по политике отмены.
+// Сумма определяется в момент отмены и сохраняется в бронировании вместе со сменой статуса.
+// Перед обращением к платёжной системе возврат занимается в базе (оплата переходит
+// в refund_pending), поэтому параллельный запрос получает ErrRefundInProgress и деньги
+// не уходят дважды. Если возврат не прошёл, бронирование остаётся отменённым, оплата
+// снова succeeded и возврат можно повторить с тем же ключом идемпотентности.
+// Возвращает сумму возврата; при нулевой сумме бронирование только отменяется.
+func CancelWithRefund(ctx context.Context, g PaymentGateway, repo BookingRepo, rooms hotels.RoomRepo, booking *Booking, actor Actor, reason string) (float64, error) {
+	return cancelAndRefund(ctx, g, repo, booking, actor, reason, func() (float64, error) {
+		return RefundableAmount(repo, rooms, *booking, time.Now())
+	})
+}
+
+// CancelWithFullRefund отменяет бронирование по решению отеля и возвращает гостю всю оплату:
+// политика отмены защищает отель от отказа гостя, а не гостя от отказа отеля.
+// Повтор после неудачного возврата работает как у CancelWithRefund.
+func CancelWithFullRefund(ctx context.Context, g PaymentGateway, repo BookingRepo, booking *Booking, actor Actor, reason string) (float64, error) {
+	return cancelAndRefund(ctx, g, repo, booking, actor, reason, func() (float64, error) {
+		if booking.RefundAmount != nil {
+			return *booking.RefundAmount, nil
+		}
+		return booking.TotalCost, nil
+	})
+}
+
+// cancelAndRefund отменяет бронирование и возвращает сумму, которую определяет refundable.
+// Неоплаченное бронирование только отменяется. Уже отменённое оплаченное бронирование не
+// отменяется повторно: возврат повторяется на сумму, сохранённую при отмене.
+func cancelAndRefund(ctx context.Context, g PaymentGateway, repo BookingRepo, booking *Booking, actor Actor, reason string, refundable func() (float64, error)) (float64, error) {
+	if booking.PaymentStatus != paymentSucceeded || booking.PaymentID == "" {
+		if booking.Status == StatusCancelled {
+			return 0, nil
+		}
+		return 0, repo.Transition(booking, StatusCancelled, actor, reason)
+	}
+
+	amount, err := refundable()
+	if err != nil {
+		return 0, err
+	}
+	if booking.Status != StatusCancelled {
+		if err := repo.CancelPaid(booking, amount, actor, reason); err != nil {
+			return 0, err
+		}
+	}
+	if amount <= 0 {
+		return 0, nil
+	}
+
+	if g == nil {
+		return 0, fmt.Errorf("%w: платёжная система не подключена", ErrRefundFailed)
+	}
+	if err := repo.ClaimRefund(booking); err != nil {
+		return 0, err
+	}
+	if err := g.Refund(ctx, booking.PaymentID, amount, RefundKey(booking.ID)); err != nil {
+		if releaseErr := repo.ReleaseRefund(booking); releaseErr != nil {
+			slog.ErrorContext(ctx, "Возврат не выполнен, оплата осталась в refund_pending", "booking_id", booking.ID, logging.Err(releaseErr))
+		}
+		return 0, fmt.Errorf("%w: %v", ErrRefundFailed, err)
+	}
+
+	if err := repo.Refunded(booking, amount, actor, fmt.Sprintf("Возврат %.2f", amount)); err != nil {
+		// Оплата остаётся в refund_pending: повторный возврат не будет отправлен
+		slog.ErrorContext(ctx, "Возврат по бронированию проведён, но статус не обновлён", "booking_id", booking.ID, "amount", amount, logging.Err(err))
+		return amount, err
+	}
+	return amount, nil
+}
+
+// RefundLatePayment возвращает всю оплату, пришедшую, когда бронирование уже истекло или
+// отменено: номер гостю не достаётся, поэтому политика отмены не применяется.
+// Возврат проводится через g и записывается в журнал бронирования, где его видит администратор.
+func RefundLatePayment(ctx context.Context, g PaymentGateway, repo BookingRepo, booking *Booking) error {
+	if g == nil {
+		return fmt.Errorf("%w: платёжная система не подключена", ErrRefundFailed)
+	}
+	if err := repo.ClaimRefund(booking); err != nil {
+		return err
+	}
+	if err := g.Refund(ctx, booking.PaymentID, booking.TotalCost, RefundKey(booking.ID)); err != nil {
+		if releaseErr := repo.ReleaseRefund(booking); releaseErr != nil {
+			slog.ErrorContext(ctx, "Возврат не выполнен, оплата осталась в refund_pending", "booking_id", booking.ID, logging.Err(releaseErr))
+		}
+		return fmt.Errorf("%w: %v", ErrRefundFailed, err)
+	}
+	reason := fmt.Sprintf("Оплата пришла, когда бронирование было в статусе %s; возвращено %.2f", booking.Status, booking.TotalCost)
+	return repo.Refunded(booking, booking.TotalCost, PaymentActor, reason)
+}
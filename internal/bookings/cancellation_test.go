@@ -0,0 +1,222 @@
+package bookings
+
+import (
+	"context"
+	"errors"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/users"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingGateway запоминает ключи и суммы возвратов; пока fail не пуст, возврат завершается этой ошибкой
+type recordingGateway struct {
+	stubGateway
+	mu      sync.Mutex
+	keys    []string
+	amounts []float64
+	fail    error
+	delay   time.Duration
+}
+
+func (g *recordingGateway) Refund(ctx context.Context, paymentID string, amount float64, idempotenceKey string) error {
+	time.Sleep(g.delay)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.keys = append(g.keys, idempotenceKey)
+	g.amounts = append(g.amounts, amount)
+	return g.fail
+}
+
+// paidCancelledBooking создаёт оплаченное бронирование, которое уже отменено, но деньги не вернулись
+func paidCancelledBooking(t *testing.T) (*MemoryBookingRepo, hotels.RoomRepo, Booking) {
+	t.Helper()
+	return paidBooking(t, StatusCancelled)
+}
+
+// paidBooking создаёт оплаченное бронирование в статусе status с заездом через 30 дней
+func paidBooking(t *testing.T, status BookingStatus) (*MemoryBookingRepo, hotels.RoomRepo, Booking) {
+	t.Helper()
+
+	store := hotels.NewMemory()
+	room := hotels.Room{HotelID: 1, Price: 1000, Capacity: 2, Units: 1}
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewMemoryBookingRepo(store.Rooms(), users.NewMemoryUserRepo())
+	start := time.Now().AddDate(0, 0, 30)
+	booking := Booking{RoomID: room.ID, UserID: 7, StartDate: start, EndDate: start.AddDate(0, 0, 2), TotalCost: 2000,
+		Status: status, PaymentStatus: paymentSucceeded, PaymentID: "pay-1"}
+	if err := repo.Create(&booking, SystemActor, "тест"); err != nil {
+		t.Fatal(err)
+	}
+	return repo, store.Rooms(), booking
+}
+
+func TestCancelWithRefundRefundsOnce(t *testing.T) {
+	repo, rooms, booking := paidCancelledBooking(t)
+	g := &recordingGateway{delay: 10 * time.Millisecond}
+
+	var wg sync.WaitGroup
+	results := make(chan error, 5)
+	for i := 0; i < cap(results); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b := booking
+			_, err := CancelWithRefund(context.Background(), g, repo, rooms, &b, UserActor(7), "тест")
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrRefundInProgress) && !errors.Is(err, ErrStaleBooking):
+			t.Errorf("неожиданная ошибка: %v", err)
+		}
+	}
+	if succeeded != 1 || len(g.keys) != 1 {
+		t.Fatalf("успешных возвратов %d, обращений к платёжной системе %d; ожидалось по одному", succeeded, len(g.keys))
+	}
+
+	stored, _ := repo.ByID(booking.ID)
+	if stored.Status != StatusRefunded || stored.PaymentStatus != "refunded" || stored.RefundedAmount != 2000 {
+		t.Fatalf("после возврата: %+v", stored)
+	}
+}
+
+func TestCancelWithRefundRetryUsesSameKey(t *testing.T) {
+	repo, rooms, booking := paidCancelledBooking(t)
+	g := &recordingGateway{fail: errors.New("таймаут")}
+
+	b := booking
+	if _, err := CancelWithRefund(context.Background(), g, repo, rooms, &b, UserActor(7), "тест"); !errors.Is(err, ErrRefundFailed) {
+		t.Fatalf("первый возврат: %v, ожидалась ErrRefundFailed", err)
+	}
+	// Неудачный возврат освобождается, его можно повторить
+	if stored, _ := repo.ByID(booking.ID); stored.PaymentStatus != paymentSucceeded {
+		t.Fatalf("после неудачного возврата оплата в статусе %s", stored.PaymentStatus)
+	}
+
+	g.fail = nil
+	b = booking
+	if amount, err := CancelWithRefund(context.Background(), g, repo, rooms, &b, UserActor(7), "тест"); err != nil || amount != 2000 {
+		t.Fatalf("повтор возврата: %.2f, %v", amount, err)
+	}
+	if len(g.keys) != 2 || g.keys[0] != g.keys[1] || g.keys[0] != RefundKey(booking.ID) {
+		t.Fatalf("ключи идемпотентности: %v", g.keys)
+	}
+}
+
+func TestCancelWithRefundRetryKeepsDecidedAmount(t *testing.T) {
+	repo, rooms, booking := paidBooking(t, StatusConfirmed)
+	// До заезда меньше FreeUntilDays: при отмене удерживается штраф
+	if err := repo.SavePolicy(&CancellationPolicy{HotelID: 1, FreeUntilDays: 60, PenaltyPercent: 25}); err != nil {
+		t.Fatal(err)
+	}
+	g := &recordingGateway{fail: errors.New("таймаут")}
+
+	if _, err := CancelWithRefund(context.Background(), g, repo, rooms, &booking, UserActor(7), "тест"); !errors.Is(err, ErrRefundFailed) {
+		t.Fatalf("первый возврат: %v, ожидалась ErrRefundFailed", err)
+	}
+	stored, _ := repo.ByID(booking.ID)
+	if stored.Status != StatusCancelled || stored.RefundAmount == nil || *stored.RefundAmount != 1500 {
+		t.Fatalf("после отмены: статус %s, сумма возврата %v", stored.Status, stored.RefundAmount)
+	}
+
+	// К повтору условия стали хуже, но гость получает сумму, определённую при отмене
+	if err := repo.SavePolicy(&CancellationPolicy{HotelID: 1, NonRefundable: true}); err != nil {
+		t.Fatal(err)
+	}
+	if amount, err := RefundableAmount(repo, rooms, stored, time.Now()); err != nil || amount != 1500 {
+		t.Fatalf("RefundableAmount после отмены: %.2f, %v", amount, err)
+	}
+	g.fail = nil
+	if amount, err := CancelWithRefund(context.Background(), g, repo, rooms, &stored, UserActor(7), "тест"); err != nil || amount != 1500 {
+		t.Fatalf("повтор возврата: %.2f, %v", amount, err)
+	}
+	if len(g.amounts) != 2 || g.amounts[0] != 1500 || g.amounts[1] != 1500 {
+		t.Fatalf("суммы возвратов: %v", g.amounts)
+	}
+}
+
+func TestCancelWithFullRefundIgnoresPolicy(t *testing.T) {
+	repo, _, booking := paidBooking(t, StatusConfirmed)
+	if err := repo.SavePolicy(&CancellationPolicy{HotelID: 1, NonRefundable: true}); err != nil {
+		t.Fatal(err)
+	}
+	g := &recordingGateway{}
+
+	amount, err := CancelWithFullRefund(context.Background(), g, repo, &booking, UserActor(1), "Отель закрыт на ремонт")
+	if err != nil || amount != booking.TotalCost {
+		t.Fatalf("возврат: %.2f, %v", amount, err)
+	}
+	stored, _ := repo.ByID(booking.ID)
+	if stored.Status != StatusRefunded || stored.RefundedAmount != booking.TotalCost || *stored.RefundAmount != booking.TotalCost {
+		t.Fatalf("после возврата: %+v", stored)
+	}
+}
+
+func TestRefundLatePaymentReturnsFullAmount(t *testing.T) {
+	repo, _, booking := paidCancelledBooking(t)
+	g := &recordingGateway{}
+
+	// Политика отмены не применяется: гость платил за бронирование, которого уже нет
+	if err := repo.SavePolicy(&CancellationPolicy{HotelID: 1, NonRefundable: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RefundLatePayment(context.Background(), g, repo, &booking); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, _ := repo.ByID(booking.ID)
+	if stored.PaymentStatus != "refunded" || stored.RefundedAmount != booking.TotalCost || len(g.keys) != 1 {
+		t.Fatalf("после возврата: %+v, обращений %d", stored, len(g.keys))
+	}
+	events, _ := repo.Events(booking.ID)
+	if last := events[len(events)-1]; last.ActorType != ActorPayment || last.ToStatus != StatusRefunded {
+		t.Fatalf("журнал: %+v", events)
+	}
+}
+
+func TestRefundableAmount(t *testing.T) {
+	start := time.Date(2025, 6, 10, 14, 0, 0, 0, time.UTC)
+	// Последний момент бесплатной отмены при FreeUntilDays = 3
+	deadline := start.Add(-3 * 24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		policy *CancellationPolicy
+		total  float64
+		now    time.Time
+		want   float64
+	}{
+		{"без политики", nil, 2000, start.Add(-time.Hour), 2000},
+		{"без политики после заезда", nil, 2000, start.Add(time.Hour), 2000},
+		{"невозвратный тариф", &CancellationPolicy{NonRefundable: true, FreeUntilDays: 30}, 2000, start.AddDate(0, -1, 0), 0},
+		{"до срока бесплатной отмены", &CancellationPolicy{FreeUntilDays: 3, PenaltyPercent: 50}, 2000, deadline.Add(-time.Minute), 2000},
+		{"ровно в срок бесплатной отмены", &CancellationPolicy{FreeUntilDays: 3, PenaltyPercent: 50}, 2000, deadline, 2000},
+		{"сразу после срока", &CancellationPolicy{FreeUntilDays: 3, PenaltyPercent: 50}, 2000, deadline.Add(time.Nanosecond), 1000},
+		{"без бесплатного срока до заезда", &CancellationPolicy{PenaltyPercent: 50}, 2000, start, 2000},
+		{"без бесплатного срока после заезда", &CancellationPolicy{PenaltyPercent: 50}, 2000, start.Add(time.Second), 1000},
+		{"штраф 100%", &CancellationPolicy{FreeUntilDays: 3, PenaltyPercent: 100}, 2000, start, 0},
+		{"без штрафа после срока", &CancellationPolicy{FreeUntilDays: 3}, 2000, start, 2000},
+		// 1999.99 * 85% = 1699.9915
+		{"возврат округляется до копеек", &CancellationPolicy{FreeUntilDays: 1, PenaltyPercent: 15}, 1999.99, start, 1699.99},
+		// 333.33 * 66.5% = 221.66445
+		{"дробный штраф", &CancellationPolicy{FreeUntilDays: 1, PenaltyPercent: 33.5}, 333.33, start, 221.66},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.RefundableAmount(tt.total, start, tt.now); got != tt.want {
+				t.Errorf("RefundableAmount = %.4f, ожидалось %.2f", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package bookings
+
+import (
+	"context"
+	"errors"
+	"hotel-booking/internal/email"
+	"hotel-booking/internal/logging"
+	"hotel-booking/internal/outbox"
+	"hotel-booking/internal/users"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHoldDuration   = 30 * time.Minute // Сколько неоплаченное бронирование держит номер
+	DefaultExpiryInterval = time.Minute      // Как часто планировщик ищет просроченные бронирования
+)
+
+var (
+	holdMu       sync.RWMutex
+	holdDuration = DefaultHoldDuration
+)
+
+// SetHoldDuration задаёт время на оплату для новых онлайн-бронирований
+func SetHoldDuration(d time.Duration) {
+	holdMu.Lock()
+	defer holdMu.Unlock()
+	holdDuration = d
+}
+
+func getHoldDuration() time.Duration {
+	holdMu.RLock()
+	defer holdMu.RUnlock()
+	return holdDuration
+}
+
+// ExpiryScheduler периодически переводит неоплаченные бронирования с истёкшим ExpiresAt в expired.
+// Запускается из main через Start и останавливается через Stop при завершении сервера.
+type ExpiryScheduler struct {
+	bookings  BookingRepo
+	users     users.UserRepo
+	interval  time.Duration
+	stop      chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+	started   bool
+}
+
+func NewExpiryScheduler(bookings BookingRepo, userRepo users.UserRepo, interval time.Duration) *ExpiryScheduler {
+	return &ExpiryScheduler{
+		bookings: bookings,
+		users:    userRepo,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (s *ExpiryScheduler) Start() {
+	s.startOnce.Do(func() {
+		s.started = true
+		slog.Info("Запуск планировщика просроченных бронирований", "interval", s.interval.String())
+		go s.run()
+	})
+}
+
+func (s *ExpiryScheduler) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.ExpireDue(time.Now())
+
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop останавливает планировщик и ждёт окончания текущего прохода, но не дольше ctx
+func (s *ExpiryScheduler) Stop(ctx context.Context) error {
+	if !s.started {
+		return nil
+	}
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ExpireDue переводит в expired онлайн-бронирования, не оплаченные к моменту now, и уведомляет гостей.
+// Бронирования без ExpiresAt (созданные до его появления) истекают через время на оплату от создания.
+// Каждый проход получает свой ID, под которым его записи видны в журнале.
+// Возвращает число истёкших бронирований.
+func (s *ExpiryScheduler) ExpireDue(now time.Time) int {
+	ctx := logging.WithRequestID(context.Background(), logging.NewRequestID())
+	logger := slog.With("worker", "expiry")
+
+	due, err := s.bookings.DueForExpiry(now, now.Add(-getHoldDuration()))
+	if err != nil {
+		logger.ErrorContext(ctx, "Ошибка при обнаружении просроченных бронирований", logging.Err(err))
+		return 0
+	}
+
+	expired := 0
+	for _, booking := range due {
+		err := s.expire(ctx, &booking)
+		if errors.Is(err, ErrStaleBooking) {
+			// Бронирование успели оплатить или отменить
+			continue
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "Ошибка при отмене бронирования с истекшим сроком оплаты", "booking_id", booking.ID, logging.Err(err))
+			continue
+		}
+
+		expired++
+		logger.InfoContext(ctx, "Бронирование отменено: истёк срок оплаты", "booking_id", booking.ID)
+	}
+	return expired
+}
+
+// expire переводит бронирование в expired и в той же транзакции ставит в очередь письмо гостю
+// о том, что бронирование снято из-за неоплаты
+func (s *ExpiryScheduler) expire(ctx context.Context, booking *Booking) error {
+	const reason = "Время на оплату истекло"
+
+	user, err := s.users.ByID(booking.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return s.bookings.Transition(booking, StatusExpired, SystemActor, reason)
+	}
+
+	msg, err := email.Render(email.TemplateBookingExpired, user.Language, email.BookingExpiredData{
+		Name:      user.Name,
+		BookingID: booking.ID,
+		StartDate: booking.StartDate,
+		EndDate:   booking.EndDate,
+	})
+	if err != nil {
+		return err
+	}
+	return s.bookings.TransitionWithEmail(ctx, booking, StatusExpired, SystemActor, reason, outbox.Email{To: user.Email, Message: msg})
+}
@@ -0,0 +1,124 @@
+package bookings
+
+import (
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/users"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// paidBeforeExpiry имитирует оплату, пришедшую между выборкой просроченных бронирований и их отменой
+type paidBeforeExpiry struct {
+	*MemoryBookingRepo
+}
+
+func (r paidBeforeExpiry) DueForExpiry(now, createdBefore time.Time) ([]Booking, error) {
+	due, err := r.MemoryBookingRepo.DueForExpiry(now, createdBefore)
+	for _, booking := range due {
+		if err := r.Transition(&booking, StatusConfirmed, PaymentActor, "payment.succeeded"); err != nil {
+			return nil, err
+		}
+	}
+	return due, err
+}
+
+// newExpiryTest создаёт гостя с почтой и репозиторий бронирований для ExpiryScheduler
+func newExpiryTest(t *testing.T) (*MemoryBookingRepo, users.UserRepo, users.User) {
+	t.Helper()
+	store := hotels.NewMemory()
+	room := hotels.Room{HotelID: 1, Price: 1000, Capacity: 2, Units: 5}
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatal(err)
+	}
+	userRepo := users.NewMemoryUserRepo()
+	guest := users.User{Name: "Гость", Email: "guest@example.com", Phone: "+79990000001"}
+	if err := userRepo.Create(&guest); err != nil {
+		t.Fatal(err)
+	}
+	return NewMemoryBookingRepo(store.Rooms(), userRepo), userRepo, guest
+}
+
+// holdBooking создаёт онлайн-бронирование номера 1 в статусе status со сроком оплаты expiresAt
+func holdBooking(t *testing.T, repo *MemoryBookingRepo, userID uint, status BookingStatus, expiresAt time.Time) Booking {
+	t.Helper()
+	start := time.Now().AddDate(0, 0, 10)
+	booking := Booking{RoomID: 1, UserID: userID, StartDate: start, EndDate: start.AddDate(0, 0, 1), TotalCost: 1000,
+		Status: status, ExpiresAt: &expiresAt}
+	if err := repo.Create(&booking, UserActor(userID), "тест"); err != nil {
+		t.Fatal(err)
+	}
+	return booking
+}
+
+func TestExpireDueExpiresOverdueBookingOnce(t *testing.T) {
+	repo, userRepo, guest := newExpiryTest(t)
+	now := time.Now()
+	overdue := holdBooking(t, repo, guest.ID, StatusPendingPayment, now.Add(-time.Minute))
+	scheduler := NewExpiryScheduler(repo, userRepo, time.Minute)
+
+	if n := scheduler.ExpireDue(now); n != 1 {
+		t.Fatalf("истекло %d бронирований, ожидалось 1", n)
+	}
+	if n := scheduler.ExpireDue(now.Add(time.Minute)); n != 0 {
+		t.Fatalf("повторный проход: истекло %d бронирований", n)
+	}
+
+	stored, _ := repo.ByID(overdue.ID)
+	if stored.Status != StatusExpired {
+		t.Fatalf("статус %s, ожидался %s", stored.Status, StatusExpired)
+	}
+	events, _ := repo.Events(overdue.ID)
+	if len(events) != 2 || events[1].ToStatus != StatusExpired || events[1].ActorType != ActorSystem {
+		t.Fatalf("журнал: %+v", events)
+	}
+
+	// Письмо поставлено вместе со сменой статуса и только один раз
+	emails := repo.Emails()
+	if len(emails) != 1 || emails[0].To != guest.Email || !strings.Contains(emails[0].Message.HTML, strconv.Itoa(int(overdue.ID))) {
+		t.Fatalf("письма: %+v", emails)
+	}
+}
+
+func TestExpireDueSkipsBookingsNotDue(t *testing.T) {
+	repo, userRepo, guest := newExpiryTest(t)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		booking Booking
+	}{
+		{"оплаченное бронирование", holdBooking(t, repo, guest.ID, StatusConfirmed, now.Add(-time.Minute))},
+		{"срок оплаты не наступил", holdBooking(t, repo, guest.ID, StatusPendingPayment, now.Add(time.Minute))},
+		{"отменённое бронирование", holdBooking(t, repo, guest.ID, StatusCancelled, now.Add(-time.Minute))},
+	}
+
+	if n := NewExpiryScheduler(repo, userRepo, time.Minute).ExpireDue(now); n != 0 {
+		t.Fatalf("истекло %d бронирований, ожидалось 0", n)
+	}
+	for _, tt := range tests {
+		if stored, _ := repo.ByID(tt.booking.ID); stored.Status != tt.booking.Status {
+			t.Errorf("%s: статус %s, ожидался %s", tt.name, stored.Status, tt.booking.Status)
+		}
+	}
+	if emails := repo.Emails(); len(emails) != 0 {
+		t.Fatalf("письма: %+v", emails)
+	}
+}
+
+func TestExpireDueNoEmailWhenTransitionFails(t *testing.T) {
+	repo, userRepo, guest := newExpiryTest(t)
+	now := time.Now()
+	booking := holdBooking(t, repo, guest.ID, StatusPendingPayment, now.Add(-time.Minute))
+
+	if n := NewExpiryScheduler(paidBeforeExpiry{repo}, userRepo, time.Minute).ExpireDue(now); n != 0 {
+		t.Fatalf("истекло %d бронирований, ожидалось 0", n)
+	}
+	if stored, _ := repo.ByID(booking.ID); stored.Status != StatusConfirmed {
+		t.Fatalf("статус %s, ожидался %s", stored.Status, StatusConfirmed)
+	}
+	if emails := repo.Emails(); len(emails) != 0 {
+		t.Fatalf("письмо об истечении поставлено без смены статуса: %+v", emails)
+	}
+}
@@ -1,25 +1,76 @@
 package bookings
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"hotel-booking/internal/auth"
 	"hotel-booking/internal/email"
 	"hotel-booking/internal/hotels"
-	"hotel-booking/internal/storage"
+	"hotel-booking/internal/logging"
+	"hotel-booking/internal/outbox"
+	"hotel-booking/internal/pricing"
 	"hotel-booking/internal/users"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
+	"slices"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// QuoteFunc рассчитывает стоимость проживания в номере, см. pricing.QuoteRoom
+type QuoteFunc func(room hotels.Room, start, end time.Time, guests int) (*pricing.Quote, error)
+
+// Handler — обработчики бронирований и политик отмены.
+// Данные читаются только через репозитории, которые подключаются в main.
+type Handler struct {
+	Bookings BookingRepo
+	Hotels   hotels.HotelRepo
+	Rooms    hotels.RoomRepo
+	Users    users.UserRepo
+	Quote    QuoteFunc
+	// Payments — платёжная система для оплаты и возврата, см. payments.BookingGateway
+	Payments PaymentGateway
+}
+
+func NewHandler(bookings BookingRepo, hotelRepo hotels.HotelRepo, rooms hotels.RoomRepo, userRepo users.UserRepo, quote QuoteFunc) *Handler {
+	return &Handler{Bookings: bookings, Hotels: hotelRepo, Rooms: rooms, Users: userRepo, Quote: quote}
+}
+
+// parseID разбирает ID из параметра пути
+func parseID(value string) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 64)
+	return uint(id), err
+}
+
+// booking загружает бронирование по ID из параметра пути
+func (h *Handler) booking(param string) (Booking, error) {
+	id, err := parseID(param)
+	if err != nil {
+		return Booking{}, err
+	}
+	return h.Bookings.ByID(id)
+}
+
+// room загружает номер по ID из параметра пути
+func (h *Handler) room(param string) (hotels.Room, error) {
+	id, err := parseID(param)
+	if err != nil {
+		return hotels.Room{}, err
+	}
+	return h.Rooms.ByID(id)
+}
+
 type CreateBookingInput struct {
 	RoomID    uint      `json:"room_id" binding:"required"`
 	StartDate time.Time `json:"start_date" binding:"required"`
 	EndDate   time.Time `json:"end_date" binding:"required"`
+	Adults    int       `json:"adults" binding:"omitempty,min=1"` // Количество взрослых, по умолчанию 1
+	Children  int       `json:"children" binding:"min=0"`         // Количество детей
 }
 
 // @Security BearerAuth
@@ -29,13 +80,14 @@ type CreateBookingInput struct {
 // @Tags bookings
 // @Produce json
 // @Param input body CreateBookingInput true "Данные для бронирования"
-// @Success 201 {object} response.BookingResponse "Данные о бранировании"
+// @Success 201 {object} response.CreatedBookingResponse "Данные о бронировании и ссылка на оплату"
 // @Failure 400 {object} response.ErrorResponse "Ошибка валидации"
 // @Failure 404 {object} response.ErrorResponse "Номер не найден"
 // @Failure 409 {object} response.ErrorResponse "Номер уже забронирован в этот период"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при проверке доступности номера или при создании бронирования"
+// @Failure 502 {object} response.ErrorResponse "Ошибка платежной системы, бронирование отменено"
 // @Router /bookings [post]
-func CreateBookingHandler(c *gin.Context) {
+func (h *Handler) CreateBookingHandler(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
 	var input CreateBookingInput
@@ -45,196 +97,152 @@ func CreateBookingHandler(c *gin.Context) {
 	}
 
 	// Проверка номера
-	var room hotels.Room
-	if err := storage.DB.First(&room, input.RoomID).Error; err != nil {
+	room, err := h.Rooms.ByID(input.RoomID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
 		return
 	}
 
+	if input.StartDate.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Дата заезда не может быть в прошлом"})
+		return
+	}
+
+	// Ночи считаются по календарным датам, которые указал клиент, независимо от его часового пояса
+	input.StartDate, input.EndDate = pricing.Date(input.StartDate), pricing.Date(input.EndDate)
+
 	if input.StartDate.After(input.EndDate) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Дата заезда не может быть позже даты выезда"})
 		return
 	}
 
-	if input.StartDate.Before(time.Now()) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Дата заезда не может быть в прошлом"})
+	if len(pricing.Nights(input.StartDate, input.EndDate)) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Бронирование должно быть минимум на одну ночь"})
 		return
 	}
 
-	// доступность номера
-	var overlappingBookings []Booking
-	if err := storage.DB.Where("room_id = ? AND NOT (end_date <= ? OR start_date >= ?)", input.RoomID, input.StartDate, input.EndDate).Find(&overlappingBookings).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке доступности номера"})
+	if input.Adults == 0 {
+		input.Adults = 1
+	}
+	if !checkCapacity(c, room, input.Adults, input.Children) {
 		return
 	}
 
-	if len(overlappingBookings) > 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "Номер уже забронирован в этот период"})
+	quote, ok := h.quoteBooking(c, room, input.StartDate, input.EndDate, input.Adults+input.Children)
+	if !ok {
 		return
 	}
 
-	days := input.EndDate.Sub(input.StartDate).Hours() / 24
-	totalPrice := days * float64(room.Price)
-
-	// создание бронирования
+	expiresAt := time.Now().Add(getHoldDuration())
 	booking := Booking{
 		RoomID:    input.RoomID,
 		UserID:    userID,
 		StartDate: input.StartDate,
 		EndDate:   input.EndDate,
-		TotalCost: totalPrice,
+		Adults:    input.Adults,
+		Children:  input.Children,
+		TotalCost: quote.Total,
+		Status:    StatusPendingPayment,
+		ExpiresAt: &expiresAt,
 		CreatedAt: time.Now(),
 	}
 
-	if err := storage.DB.Create(&booking).Error; err != nil {
+	// создание бронирования с проверкой доступности в одной транзакции
+	err = h.Bookings.Create(&booking, UserActor(userID), "Бронирование создано")
+	if errors.Is(err, ErrRoomUnavailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Номер уже забронирован в этот период"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании бронирования"})
 		return
 	}
-	NotificationCreateBooking(userID, booking)
 
-	c.JSON(http.StatusCreated, booking)
-}
+	paymentID, paymentURL, err := h.createPayment(c.Request.Context(), &booking)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Ошибка при создании платежа", "booking_id", booking.ID, logging.Err(err))
+		h.releaseBooking(c.Request.Context(), &booking, "Не удалось создать платёж")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Ошибка платежной системы, бронирование отменено"})
+		return
+	}
 
-func NotificationCreateBooking(userID uint, booking Booking) {
-	var user users.User
-	if err := storage.DB.First(&user, userID).Error; err != nil {
-		log.Printf("Ошибка при получении пользователя: %v", err)
+	// ID платежа и письмо гостю со ссылкой на оплату сохраняются в одной транзакции
+	if err := h.savePayment(c.Request.Context(), userID, &booking, paymentID, paymentURL); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Ошибка при сохранении платежа", "booking_id", booking.ID, logging.Err(err))
+		h.releaseBooking(c.Request.Context(), &booking, "Не удалось сохранить платёж")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании бронирования"})
 		return
 	}
 
-	name := user.Name
-	emailUs := user.Email
+	c.JSON(http.StatusCreated, CreatedBooking{Booking: booking, PaymentURL: paymentURL})
+}
 
-	// Использование системного токена
-	systemToken := auth.GetSystemToken()
+// CreatedBooking — созданное бронирование вместе со ссылкой на его оплату
+type CreatedBooking struct {
+	Booking
+	PaymentURL string `json:"payment_url"`
+}
 
-	createPayUrl := fmt.Sprintf(os.Getenv("URL_BACKEND")+"/bookings/%d/pay", booking.ID)
-	req, err := http.NewRequest("POST", createPayUrl, nil)
-	if err != nil {
-		log.Printf("Ошибка при создании запроса: %v", err)
-		return
+// createPayment создаёт платёж за бронирование через подключённую платёжную систему
+func (h *Handler) createPayment(ctx context.Context, booking *Booking) (string, string, error) {
+	if h.Payments == nil {
+		return "", "", errors.New("платёжная система не подключена")
 	}
-	req.Header.Set("Authorization", "Bearer "+systemToken)
-	req.Header.Set("Content-Type", "application/json")
+	return h.Payments.CreatePayment(ctx, booking)
+}
 
-	// Отправка запроса
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("Ошибка при отправке запроса: %v", err)
+// releaseBooking отменяет бронирование, оставшееся без платежа: иначе оно занимало бы номер
+// до истечения срока оплаты
+func (h *Handler) releaseBooking(ctx context.Context, booking *Booking, reason string) {
+	if err := h.Bookings.Transition(booking, StatusCancelled, SystemActor, reason); err != nil {
+		slog.ErrorContext(ctx, "Ошибка при отмене бронирования", "booking_id", booking.ID, logging.Err(err))
 	}
-	defer resp.Body.Close()
+}
 
-	// Проверка статуса ответа
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("Ошибка: статус ответа %d", resp.StatusCode)
-	}
+var (
+	bookingsURLMu sync.RWMutex
+	bookingsURL   = "http://localhost:3000/my-bookings"
+)
 
-	// Парсинг ответа
-	var responseBody map[string]string
-	if err := json.NewDecoder(resp.Body).Decode(&responseBody); err != nil {
-		log.Printf("Ошибка при чтении ответа: %v", err)
-	}
+// SetBookingsURL задаёт страницу «Мои бронирования», на которую ведут письма о бронированиях
+func SetBookingsURL(url string) {
+	bookingsURLMu.Lock()
+	defer bookingsURLMu.Unlock()
+	bookingsURL = url
+}
 
-	// Получение ссылки
-	paymentURL, ok := responseBody["payment_url"]
-	if !ok {
-		log.Printf("Поле 'payment_url' отсутствует в ответе")
-	}
-
-	emailTemplate := `<!DOCTYPE html>
-<html lang="ru">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Вами было создано бронирование</title>
-    <style>
-        body {
-            font-family: Arial, sans-serif;
-            background-color: #f9f9f9;
-            margin: 0;
-            padding: 0;
-        }
-        .email-container {
-            max-width: 600px;
-            margin: 20px auto;
-            background: #ffffff;
-            border: 1px solid #ddd;
-            border-radius: 8px;
-            overflow: hidden;
-        }
-        .email-header {
-            background-color: #007bff;
-            color: #ffffff;
-            padding: 20px;
-            text-align: center;
-        }
-        .email-header h1 {
-            margin: 0;
-            font-size: 24px;
-        }
-        .email-body {
-            padding: 20px;
-            color: #333333;
-        }
-        .email-body p {
-            margin: 0 0 15px;
-            line-height: 1.5;
-        }
-        .email-footer {
-            background-color: #f4f4f9;
-            text-align: center;
-            padding: 10px;
-            font-size: 12px;
-            color: #777;
-        }
-        .reset-button {
-            display: inline-block;
-            margin-top: 20px;
-            padding: 10px 20px;
-            background-color:rgb(0, 255, 94);
-            color: #ffffff;
-            text-decoration: none;
-            border-radius: 5px;
-            font-size: 16px;
-        }
-        .reset-button:hover {
-            background-color: #0056b3;
-        }
-    </style>
-</head>
-<body>
-    <div class="email-container">
-        <div class="email-header">
-            <h1>Вами было создано бронирование</h1>
-        </div>
-        <div class="email-body">
-            <p>Здравствуйте, %v</p>
-            <p>Вы только что забронировали номер в отеле.</p>
-						<p>Подробности бронирования:</p>
-						<p>Номер: %d</p>
-						<p>Дата заезда: %s</p>
-						<p>Дата выезда: %s</p>
-						<p>Стоимость: %f</p>
-						<p> Пожалуйста, оплатите его в течении 30 минут с момента отправки письма. Оплатить можно по кнопке снизу, или в вашем списке бронирований: </p>
-            <p>https://hotel-booking-sandy.vercel.app/my-bookings</p>
-            <a href="%s" class="reset-button">Перейти к оплате</a>
-            <p>Если если это были не вы, или бронь было оформленна случайно, то отмените её в списках своих бронирований, или бронь удалиться сама через 30 минут.</p>
-            <p>С уважением,<br>Команда поддержки</p>
-        </div>
-        <div class="email-footer">
-            Это письмо было отправлено автоматически. Пожалуйста, не отвечайте на него.
-        </div>
-    </div>
-</body>
-</html>`
-
-	subject := "Вами было создано бронирование"
-	body := fmt.Sprintf(emailTemplate, name, booking.RoomID, booking.StartDate.Format("02.01.2006"), booking.EndDate.Format("02.01.2006"), booking.TotalCost, paymentURL)
-	if err := email.SendEmail(emailUs, subject, body); err != nil {
-		log.Printf("Ошибка при отправке письма: %v", err)
-	}
+func getBookingsURL() string {
+	bookingsURLMu.RLock()
+	defer bookingsURLMu.RUnlock()
+	return bookingsURL
+}
 
+// savePayment сохраняет ID платежа за бронирование и ставит в очередь письмо гостю
+// со ссылкой на оплату; гостю без почты сохраняется только ID
+func (h *Handler) savePayment(ctx context.Context, userID uint, booking *Booking, paymentID, paymentURL string) error {
+	user, err := h.Users.ByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return h.Bookings.SetPaymentID(booking, paymentID)
+	}
+
+	msg, err := email.Render(email.TemplateBookingCreated, user.Language, email.BookingCreatedData{
+		Name:        user.Name,
+		BookingID:   booking.ID,
+		RoomID:      booking.RoomID,
+		StartDate:   booking.StartDate,
+		EndDate:     booking.EndDate,
+		Total:       booking.TotalCost,
+		PaymentURL:  paymentURL,
+		BookingsURL: getBookingsURL(),
+		HoldMinutes: int(getHoldDuration().Minutes()),
+	})
+	if err != nil {
+		return err
+	}
+	return h.Bookings.SetPaymentIDWithEmail(ctx, booking, paymentID, outbox.Email{To: user.Email, Message: msg})
 }
 
 type CreateOfflineBookingInput struct {
@@ -243,6 +251,8 @@ type CreateOfflineBookingInput struct {
 	EndDate     time.Time `json:"end_date" binding:"required"`
 	PhoneNumber string    `json:"phone_number" binding:"required"`
 	Name        string    `json:"name" binding:"required"`
+	Adults      int       `json:"adults" binding:"omitempty,min=1"` // Количество взрослых, по умолчанию 1
+	Children    int       `json:"children" binding:"min=0"`         // Количество детей
 }
 
 // @Security BearerAuth
@@ -258,91 +268,90 @@ type CreateOfflineBookingInput struct {
 // @Failure 400 {object} response.ErrorResponse "Ошибка валидации"
 // @Failure 404 {object} response.ErrorResponse "Номер не найден"
 // @Failure 409 {object} response.ErrorResponse "Номер уже забронирован в этот период"
-// @Failure 500 {object} response.ErrorResponse "Ошибка при проверке доступности номера или при создании бронирования"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при поиске пользователя или при создании бронирования"
 // @Router /bookings/offline [post]
-func CreateOfflineBookingHandler(c *gin.Context) {
-	// Проверка роли
-	role := c.GetString("role")
-	if role != "owner" && role != "manager" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Только менеджеры и владельцы могут создавать офлайн бронирования"})
-		return
-	}
-
+func (h *Handler) CreateOfflineBookingHandler(c *gin.Context) {
 	var input CreateOfflineBookingInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if input.StartDate.After(input.EndDate) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Дата заезда не может быть позже даты выезда"})
-		return
-	}
-
 	if input.StartDate.Before(time.Now()) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Дата заезда не может быть в прошлом"})
 		return
 	}
 
-	// Поиск существующего пользователя по телефону
-	var user users.User
-	result := storage.DB.Where("phone = ?", input.PhoneNumber).First(&user)
+	// Ночи считаются по календарным датам, которые указал клиент, независимо от его часового пояса
+	input.StartDate, input.EndDate = pricing.Date(input.StartDate), pricing.Date(input.EndDate)
 
-	if result.Error != nil {
-		// Создаем нового пользователя
-		user = users.User{
-			Phone: input.PhoneNumber,
-			Name:  input.Name,
-			Role:  "client",
-			// Генерируем временный пароль или оставляем пустым
-		}
-		if err := storage.DB.Create(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании пользователя"})
-			return
-		}
+	if input.StartDate.After(input.EndDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Дата заезда не может быть позже даты выезда"})
+		return
+	}
+
+	if len(pricing.Nights(input.StartDate, input.EndDate)) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Бронирование должно быть минимум на одну ночь"})
+		return
 	}
 
 	// Проверка номера
-	var room hotels.Room
-	if err := storage.DB.First(&room, input.RoomID).Error; err != nil {
+	room, err := h.Rooms.ByID(input.RoomID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
 		return
 	}
-
-	// Проверка доступности
-	var overlappingBookings []Booking
-	if err := storage.DB.Where(
-		"room_id = ? AND NOT (end_date <= ? OR start_date >= ?)",
-		input.RoomID,
-		input.StartDate,
-		input.EndDate,
-	).Find(&overlappingBookings).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке доступности номера"})
+	if !h.canManageBookingsOf(c, room) {
 		return
 	}
 
-	if len(overlappingBookings) > 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "Номер уже забронирован в этот период"})
+	if input.Adults == 0 {
+		input.Adults = 1
+	}
+	if !checkCapacity(c, room, input.Adults, input.Children) {
 		return
 	}
 
 	// Расчет стоимости
-	days := input.EndDate.Sub(input.StartDate).Hours() / 24
-	totalPrice := days * float64(room.Price)
+	quote, ok := h.quoteBooking(c, room, input.StartDate, input.EndDate, input.Adults+input.Children)
+	if !ok {
+		return
+	}
+
+	// Поиск существующего пользователя по телефону; нового гостя создаст Bookings вместе с бронированием
+	user, err := h.Users.ByPhone(input.PhoneNumber)
+	guest := &user
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		guest = &users.User{
+			Phone: input.PhoneNumber,
+			Name:  input.Name,
+			Role:  "client",
+		}
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при поиске пользователя"})
+		return
+	}
 
-	// Создание бронирования
 	booking := Booking{
 		RoomID:           input.RoomID,
-		UserID:           user.ID,
 		StartDate:        input.StartDate,
 		EndDate:          input.EndDate,
-		TotalCost:        totalPrice,
+		Adults:           input.Adults,
+		Children:         input.Children,
+		TotalCost:        quote.Total,
 		CreatedAt:        time.Now(),
-		PaymentStatus:    "pending", // Офлайн бронирования считаются оплаченными
+		Status:           StatusConfirmed, // Офлайн бронирования подтверждаются сразу, оплата на месте
+		PaymentStatus:    "pending",
 		IsOfflineBooking: true,
 	}
 
-	if err := storage.DB.Create(&booking).Error; err != nil {
+	// Создание гостя и бронирования с проверкой доступности в одной транзакции
+	err = h.Bookings.CreateWithGuest(&booking, guest, UserActor(c.GetUint("user_id")), "Офлайн бронирование")
+	if errors.Is(err, ErrRoomUnavailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Номер уже забронирован в этот период"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании бронирования"})
 		return
 	}
@@ -359,11 +368,11 @@ func CreateOfflineBookingHandler(c *gin.Context) {
 // @Success 201 {array} response.BookingResponse "Данные о бранировании"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при получении списка бронирований"
 // @Router /rooms/{id}/bookings [get]
-func GetRoomBookingsHandler(c *gin.Context) {
-	roomID := c.Param("id")
+func (h *Handler) GetRoomBookingsHandler(c *gin.Context) {
+	roomID, _ := parseID(c.Param("id"))
 
-	var bookings []Booking
-	if err := storage.DB.Where("room_id = ?", roomID).Find(&bookings).Error; err != nil {
+	bookings, err := h.Bookings.ByRoom(roomID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении списка бронирований"})
 		return
 	}
@@ -371,36 +380,87 @@ func GetRoomBookingsHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, bookings)
 }
 
+// GetRoomAvailabilityHandler godoc
+// @Summary Свободные номера по ночам
+// @Description Возвращает для типа номера количество свободных номеров на каждую ночь периода
+// @Tags rooms
+// @Produce json
+// @Param id path int true "ID номера"
+// @Param start_date query string true "Дата заезда (YYYY-MM-DD)"
+// @Param end_date query string true "Дата выезда (YYYY-MM-DD)"
+// @Success 200 {object} response.RoomAvailabilityResponse "Свободные номера по ночам"
+// @Failure 400 {object} response.ErrorResponse "Некорректные даты"
+// @Failure 404 {object} response.ErrorResponse "Номер не найден"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при расчёте доступности"
+// @Router /rooms/{id}/availability [get]
+func (h *Handler) GetRoomAvailabilityHandler(c *gin.Context) {
+	startDate, err := time.Parse("2006-01-02", c.Query("start_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректная дата заезда"})
+		return
+	}
+	endDate, err := time.Parse("2006-01-02", c.Query("end_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректная дата выезда"})
+		return
+	}
+
+	nights := pricing.Nights(startDate, endDate)
+	if len(nights) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Дата выезда должна быть позже даты заезда"})
+		return
+	}
+
+	room, err := h.room(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
+		return
+	}
+
+	available, err := AvailableUnits(h.Bookings, room, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при расчёте доступности"})
+		return
+	}
+
+	entries := make([]gin.H, 0, len(nights))
+	for _, night := range nights {
+		entries = append(entries, gin.H{"date": night.Format("2006-01-02"), "available": available[night]})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"room_id": room.ID, "units": room.Units, "nights": entries})
+}
+
 // @Security BearerAuth
 // GetOwnerBookingsHandler godoc
-// @Summary Получение бронирований владельца
-// @Description Получение бронирований для владельца
+// @Summary Получение бронирований отелей
+// @Description Получение бронирований отелей, которыми пользователь владеет или в штате которых состоит
 // @Tags bookings
 // @Produce json
+// @Param hotel_id query int false "Только бронирования этого отеля"
 // @Success 201 {array} response.BookingResponse "Данные о бранировании"
 // @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при получении бронирований"
 // @Router /owners/bookings [get]
-func GetOwnerBookingsHandler(c *gin.Context) {
-	ownerID := c.GetUint("user_id")
-
-	role := c.GetString("role")
-
-	if role != "owner" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+func (h *Handler) GetOwnerBookingsHandler(c *gin.Context) {
+	hotelIDs, err := h.Hotels.ManagedIDs(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении бронирований"})
 		return
 	}
 
-	var bookings []Booking
-	query := `
-	SELECT b.*
-	FROM bookings b
-	JOIN rooms r ON b.room_id = r.id
-	JOIN hotels h ON r.hotel_id = h.id
-	WHERE h.owner_id = ?
-	`
+	// Фильтр по чужому отелю — отказ в доступе, а не пустой список
+	if hotelID := c.Query("hotel_id"); hotelID != "" {
+		id, err := parseID(hotelID)
+		if err != nil || !slices.Contains(hotelIDs, id) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+			return
+		}
+		hotelIDs = []uint{id}
+	}
 
-	if err := storage.DB.Raw(query, ownerID).Scan(&bookings).Error; err != nil {
+	bookings, err := h.Bookings.ByHotels(hotelIDs)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении бронирований"})
 		return
 	}
@@ -417,11 +477,9 @@ func GetOwnerBookingsHandler(c *gin.Context) {
 // @Success 200 {array} response.BookingResponse "Данные о бранировании"
 // Failure 500 {object} response.ErrorResponse "Ошибка при получении бронирований"
 // @Router /bookings/my [get]
-func GetYourBookingsHandler(c *gin.Context) {
-	userID := c.GetUint("user_id")
-
-	var bookings []Booking
-	if err := storage.DB.Where("user_id = ?", userID).Find(&bookings).Error; err != nil {
+func (h *Handler) GetYourBookingsHandler(c *gin.Context) {
+	bookings, err := h.Bookings.ByUser(c.GetUint("user_id"))
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении бронирований"})
 		return
 	}
@@ -431,22 +489,23 @@ func GetYourBookingsHandler(c *gin.Context) {
 // @Security BearerAuth
 // CancelBookingHandler godoc
 // @Summary Отмена бронирования
-// @Description Отмена бронирования пользователем
+// @Description Отмена бронирования пользователем. Неоплаченное бронирование отменяется бесплатно, по оплаченному возвращается сумма по политике отмены отеля или номера.
 // @Tags bookings
 // @Param id path int true "ID бронирования"
 // @Produce json
-// @Success 200 {object} response.MessageResponse "Бронирование успешно отменено"
-// @Failure 400 {object} response.ErrorResponse "Бронирование уже оплачено и не может быть отменено"
+// @Success 200 {object} response.CancellationResponse "Бронирование успешно отменено"
+// @Failure 400 {object} response.ErrorResponse "Бронирование в этом статусе не может быть отменено"
 // @Failure 403 {object} response.ErrorResponse "Вы не можете отменить бронирование, которое не принадлежит вам"
 // @Failure 404 {object} response.ErrorResponse "Бронирование не найдено"
+// @Failure 409 {object} response.ErrorResponse "Статус бронирования изменился"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при отмене бронирования"
+// @Failure 502 {object} response.ErrorResponse "Ошибка платежной системы при возврате"
 // @Router /bookings/{id} [delete]
-func CancelBookingHandler(c *gin.Context) {
-	bookingID := c.Param("id")
+func (h *Handler) CancelBookingHandler(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
-	var booking Booking
-	if err := storage.DB.First(&booking, bookingID).Error; err != nil {
+	booking, err := h.booking(c.Param("id"))
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Бронирование не найдено"})
 		return
 	}
@@ -455,49 +514,419 @@ func CancelBookingHandler(c *gin.Context) {
 		return
 	}
 
-	if booking.PaymentStatus == "succeeded" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Бронирование уже оплачено и не может быть отменено"})
+	if booking.Status != StatusPendingPayment && booking.Status != StatusConfirmed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Бронирование в этом статусе не может быть отменено"})
 		return
 	}
 
-	if err := storage.DB.Delete(&booking).Error; err != nil {
+	refund, err := CancelWithRefund(c.Request.Context(), h.Payments, h.Bookings, h.Rooms, &booking, UserActor(userID), "Отменено гостем")
+	if errors.Is(err, ErrStaleBooking) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Статус бронирования изменился, повторите запрос"})
+		return
+	}
+	if errors.Is(err, ErrRefundInProgress) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Возврат по бронированию уже выполняется"})
+		return
+	}
+	if errors.Is(err, ErrRefundFailed) {
+		slog.ErrorContext(c.Request.Context(), "Возврат по бронированию не выполнен", "booking_id", booking.ID, logging.Err(err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Бронирование отменено, но возврат не выполнен, повторите запрос возврата"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отмене бронирования"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Бронирование успешно отменено"})
+	c.JSON(http.StatusOK, gin.H{"message": "Бронирование успешно отменено", "refund_amount": refund})
 
 }
 
-func init() {
-	// Start the cleanup goroutine
-	go cleanupUnpaidBookings()
+type UpdateBookingStatusInput struct {
+	Status string `json:"status" binding:"required" enums:"checked_in,checked_out,no_show,cancelled"`
+	Reason string `json:"reason"`
 }
 
-func cleanupUnpaidBookings() {
-	log.Println("Запуск очистки просроченных бронирований...")
-	ticker := time.NewTicker(3 * time.Minute)
-	for range ticker.C {
-		var bookings []Booking
-		thirtyMinutesAgo := time.Now().Add(-30 * time.Minute)
+// Статусы, которые отель может выставить вручную
+var staffStatuses = map[BookingStatus]bool{
+	StatusCheckedIn:  true,
+	StatusCheckedOut: true,
+	StatusNoShow:     true,
+	StatusCancelled:  true,
+}
 
-		// Находим только онлайн-бронирования с истекшим сроком
-		if err := storage.DB.Where(
-			"created_at <= ? AND payment_status = ? AND is_offline_booking = ?",
-			thirtyMinutesAgo,
-			"pending",
-			false,
-		).Find(&bookings).Error; err != nil {
-			log.Printf("Ошибка при обнаружении просроченных бронирований: %v", err)
-			continue
-		}
+// @Security BearerAuth
+// UpdateBookingStatusHandler godoc
+// @Summary Изменение статуса бронирования отелем
+// @Description Заселение, выезд, неявка или отмена бронирования владельцем отеля или менеджером. При отмене оплаченного бронирования гостю возвращается вся оплата.
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "ID бронирования"
+// @Param input body UpdateBookingStatusInput true "Новый статус и причина"
+// @Success 200 {object} response.BookingResponse "Бронирование с новым статусом"
+// @Failure 400 {object} response.ErrorResponse "Недопустимый статус или переход"
+// @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} response.ErrorResponse "Бронирование не найдено"
+// @Failure 409 {object} response.ErrorResponse "Статус бронирования изменился или возврат уже выполняется"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при изменении статуса"
+// @Failure 502 {object} response.ErrorResponse "Ошибка платежной системы при возврате"
+// @Router /owners/bookings/{id}/status [put]
+func (h *Handler) UpdateBookingStatusHandler(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var input UpdateBookingStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := BookingStatus(input.Status)
+	if !staffStatuses[status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Недопустимый статус"})
+		return
+	}
 
-		for _, booking := range bookings {
-			if err := storage.DB.Delete(&booking).Error; err != nil {
-				log.Printf("Ошибка при отмене бронирования с истекшим сроком действия %d: %v", booking.ID, err)
-				continue
-			}
-			log.Printf("Отмененное бронирование с истекшим сроком действия %d", booking.ID)
+	booking, err := h.booking(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Бронирование не найдено"})
+		return
+	}
+
+	hotel, err := h.bookingHotel(booking)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Отель не найден"})
+		return
+	}
+	allowed, err := hotels.CanManageHotel(h.Hotels, hotel, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при изменении статуса"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return
+	}
+
+	// Отель отменяет бронирование не по вине гостя: оплата возвращается полностью
+	if status == StatusCancelled && booking.Status != StatusCancelled {
+		_, err = CancelWithFullRefund(c.Request.Context(), h.Payments, h.Bookings, &booking, UserActor(userID), input.Reason)
+	} else {
+		err = h.Bookings.Transition(&booking, status, UserActor(userID), input.Reason)
+	}
+	if errors.Is(err, ErrInvalidTransition) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, ErrStaleBooking) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Статус бронирования изменился, повторите запрос"})
+		return
+	}
+	if errors.Is(err, ErrRefundInProgress) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Возврат по бронированию уже выполняется"})
+		return
+	}
+	if errors.Is(err, ErrRefundFailed) {
+		slog.ErrorContext(c.Request.Context(), "Возврат по бронированию не выполнен", "booking_id", booking.ID, logging.Err(err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Бронирование отменено, но возврат не выполнен"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при изменении статуса"})
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
+// @Security BearerAuth
+// GetBookingEventsHandler godoc
+// @Summary История статусов бронирования
+// @Description Возвращает журнал переходов бронирования: кто, когда и почему менял статус
+// @Tags bookings
+// @Produce json
+// @Param id path int true "ID бронирования"
+// @Success 200 {array} response.BookingEventResponse "Журнал переходов"
+// @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} response.ErrorResponse "Бронирование не найдено"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при получении истории"
+// @Router /bookings/{id}/events [get]
+func (h *Handler) GetBookingEventsHandler(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	role := c.GetString("role")
+
+	booking, err := h.booking(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Бронирование не найдено"})
+		return
+	}
+
+	// Историю видят гость, администратор и штат отеля номера
+	allowed := booking.UserID == userID || auth.HasPermission(role, auth.PermAllBookings)
+	if !allowed && auth.HasPermission(role, auth.PermHotelBookings) {
+		if hotel, err := h.bookingHotel(booking); err == nil {
+			allowed, _ = hotels.CanManageHotel(h.Hotels, hotel, userID)
 		}
 	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return
+	}
+
+	events, err := h.Bookings.Events(booking.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении истории"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// canManageBookingsOf проверяет, что текущий пользователь владеет отелем номера
+// или состоит в его штате. При отказе сам отвечает клиенту и возвращает false.
+func (h *Handler) canManageBookingsOf(c *gin.Context, room hotels.Room) bool {
+	hotel, err := h.Hotels.ByID(room.HotelID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Отель не найден"})
+		return false
+	}
+	allowed, err := hotels.CanManageHotel(h.Hotels, hotel, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке доступа"})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Номер относится к отелю, в штате которого вы не состоите"})
+		return false
+	}
+	return true
+}
+
+// bookingHotel возвращает отель, к которому относится забронированный номер
+func (h *Handler) bookingHotel(booking Booking) (hotels.Hotel, error) {
+	room, err := h.Rooms.ByIDUnscoped(booking.RoomID)
+	if err != nil {
+		return hotels.Hotel{}, err
+	}
+	return h.Hotels.ByID(room.HotelID)
+}
+
+// checkCapacity проверяет, что гости помещаются в номер.
+// При ошибке сам отвечает клиенту и возвращает false.
+func checkCapacity(c *gin.Context, room hotels.Room, adults, children int) bool {
+	if adults+children > room.Capacity {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Номер вмещает не более %d гостей", room.Capacity)})
+		return false
+	}
+	return true
+}
+
+// quoteBooking рассчитывает стоимость бронирования по правилам цен номера.
+// При ошибке сам отвечает клиенту и возвращает false.
+func (h *Handler) quoteBooking(c *gin.Context, room hotels.Room, start, end time.Time, guests int) (*pricing.Quote, bool) {
+	quote, err := h.Quote(room, start, end, guests)
+	var minStayErr *pricing.MinStayError
+	if errors.As(err, &minStayErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Минимальный срок проживания — %d ноч.", minStayErr.MinStay)})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при расчёте стоимости"})
+		return nil, false
+	}
+	return quote, true
+}
+
+type CancellationPolicyInput struct {
+	FreeUntilDays  int     `json:"free_until_days" binding:"min=0"`         // Бесплатная отмена не позднее чем за N дней до заезда
+	PenaltyPercent float64 `json:"penalty_percent" binding:"min=0,max=100"` // Штраф в % от стоимости при более поздней отмене
+	NonRefundable  bool    `json:"non_refundable"`                          // Невозвратный тариф
+}
+
+// saveCancellationPolicy создаёт или заменяет политику отмены отеля или номера (roomID = 0 — отель)
+func (h *Handler) saveCancellationPolicy(c *gin.Context, hotelID, roomID uint) {
+	var input CancellationPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy := CancellationPolicy{
+		HotelID:        hotelID,
+		RoomID:         roomID,
+		FreeUntilDays:  input.FreeUntilDays,
+		PenaltyPercent: input.PenaltyPercent,
+		NonRefundable:  input.NonRefundable,
+	}
+	if err := h.Bookings.SavePolicy(&policy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении политики отмены"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// deleteCancellationPolicy удаляет политику отмены отеля или номера (roomID = 0 — отель)
+func (h *Handler) deleteCancellationPolicy(c *gin.Context, hotelID, roomID uint) {
+	deleted, err := h.Bookings.DeletePolicy(hotelID, roomID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении политики отмены"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Политика отмены не задана"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Политика отмены удалена"})
+}
+
+// ownedHotelParam загружает отель из параметра id и проверяет, что он принадлежит текущему владельцу.
+// При ошибке сам отвечает клиенту и возвращает false.
+func (h *Handler) ownedHotelParam(c *gin.Context) (hotels.Hotel, bool) {
+	id, _ := parseID(c.Param("id"))
+	hotel, err := h.Hotels.ByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Отель не найден"})
+		return hotels.Hotel{}, false
+	}
+	if hotel.OwnerID != c.GetUint("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Отель не принадлежит вам"})
+		return hotels.Hotel{}, false
+	}
+	return hotel, true
+}
+
+// ownedRoomParam загружает номер из параметра id и проверяет, что он принадлежит текущему владельцу.
+// При ошибке сам отвечает клиенту и возвращает false.
+func (h *Handler) ownedRoomParam(c *gin.Context) (hotels.Room, bool) {
+	room, err := h.room(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
+		return hotels.Room{}, false
+	}
+
+	hotel, err := h.Hotels.ByID(room.HotelID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Отель не найден"})
+		return hotels.Room{}, false
+	}
+	if hotel.OwnerID != c.GetUint("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Номер не принадлежит вам"})
+		return hotels.Room{}, false
+	}
+	return room, true
+}
+
+// @Security BearerAuth
+// SetHotelCancellationPolicyHandler godoc
+// @Summary Политика отмены отеля
+// @Description Задаёт условия отмены для всех номеров отеля, у которых нет своей политики. Доступно только владельцу.
+// @Tags cancellation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отеля"
+// @Param input body CancellationPolicyInput true "Условия отмены"
+// @Success 200 {object} response.CancellationPolicyResponse "Сохранённая политика"
+// @Failure 400 {object} response.ErrorResponse "Ошибка валидации"
+// @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} response.ErrorResponse "Отель не найден"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при сохранении политики отмены"
+// @Router /owners/hotels/{id}/cancellation-policy [put]
+func (h *Handler) SetHotelCancellationPolicyHandler(c *gin.Context) {
+	hotel, ok := h.ownedHotelParam(c)
+	if !ok {
+		return
+	}
+	h.saveCancellationPolicy(c, hotel.ID, 0)
+}
+
+// @Security BearerAuth
+// DeleteHotelCancellationPolicyHandler godoc
+// @Summary Удаление политики отмены отеля
+// @Description Удаляет политику отмены отеля, отмена номеров без своей политики становится бесплатной. Доступно только владельцу.
+// @Tags cancellation
+// @Produce json
+// @Param id path int true "ID отеля"
+// @Success 200 {object} response.MessageResponse "Политика отмены удалена"
+// @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} response.ErrorResponse "Политика отмены не задана"
+// @Router /owners/hotels/{id}/cancellation-policy [delete]
+func (h *Handler) DeleteHotelCancellationPolicyHandler(c *gin.Context) {
+	hotel, ok := h.ownedHotelParam(c)
+	if !ok {
+		return
+	}
+	h.deleteCancellationPolicy(c, hotel.ID, 0)
+}
+
+// @Security BearerAuth
+// SetRoomCancellationPolicyHandler godoc
+// @Summary Политика отмены номера
+// @Description Задаёт условия отмены для номера, например невозвратный тариф. Политика номера важнее политики отеля. Доступно только владельцу.
+// @Tags cancellation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID номера"
+// @Param input body CancellationPolicyInput true "Условия отмены"
+// @Success 200 {object} response.CancellationPolicyResponse "Сохранённая политика"
+// @Failure 400 {object} response.ErrorResponse "Ошибка валидации"
+// @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} response.ErrorResponse "Номер не найден"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при сохранении политики отмены"
+// @Router /owners/rooms/{id}/cancellation-policy [put]
+func (h *Handler) SetRoomCancellationPolicyHandler(c *gin.Context) {
+	room, ok := h.ownedRoomParam(c)
+	if !ok {
+		return
+	}
+	h.saveCancellationPolicy(c, room.HotelID, room.ID)
+}
+
+// @Security BearerAuth
+// DeleteRoomCancellationPolicyHandler godoc
+// @Summary Удаление политики отмены номера
+// @Description Удаляет политику номера, после чего действует политика отеля. Доступно только владельцу.
+// @Tags cancellation
+// @Produce json
+// @Param id path int true "ID номера"
+// @Success 200 {object} response.MessageResponse "Политика отмены удалена"
+// @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} response.ErrorResponse "Политика отмены не задана"
+// @Router /owners/rooms/{id}/cancellation-policy [delete]
+func (h *Handler) DeleteRoomCancellationPolicyHandler(c *gin.Context) {
+	room, ok := h.ownedRoomParam(c)
+	if !ok {
+		return
+	}
+	h.deleteCancellationPolicy(c, room.HotelID, room.ID)
+}
+
+// GetRoomCancellationPolicyHandler godoc
+// @Summary Условия отмены номера
+// @Description Возвращает действующую политику отмены номера: политику номера или отеля. Если политика не задана, отмена бесплатная и возвращается пустая политика.
+// @Tags cancellation
+// @Produce json
+// @Param id path int true "ID номера"
+// @Success 200 {object} response.CancellationPolicyResponse "Действующая политика"
+// @Failure 404 {object} response.ErrorResponse "Номер не найден"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при получении политики отмены"
+// @Router /rooms/{id}/cancellation-policy [get]
+func (h *Handler) GetRoomCancellationPolicyHandler(c *gin.Context) {
+	room, err := h.room(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
+		return
+	}
+
+	policy, err := h.Bookings.PolicyFor(room)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении политики отмены"})
+		return
+	}
+	if policy == nil {
+		policy = &CancellationPolicy{HotelID: room.HotelID}
+	}
+
+	c.JSON(http.StatusOK, policy)
 }
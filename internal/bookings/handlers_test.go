@@ -0,0 +1,380 @@
+package bookings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/pricing"
+	"hotel-booking/internal/users"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubGateway выдаёт ссылку на оплату без обращения к платёжной системе
+type stubGateway struct{}
+
+func (stubGateway) CreatePayment(ctx context.Context, booking *Booking) (string, string, error) {
+	return "pay-1", "https://pay.test/1", nil
+}
+
+func (stubGateway) Refund(ctx context.Context, paymentID string, amount float64, idempotenceKey string) error {
+	return errors.New("stub: возврат не поддерживается")
+}
+
+func TestCreateBookingHandlerWithMemoryRepos(t *testing.T) {
+	store := hotels.NewMemory()
+	hotel := hotels.Hotel{Name: "Тестовый отель", OwnerID: 1}
+	if err := store.Hotels().Create(&hotel); err != nil {
+		t.Fatal(err)
+	}
+	room := hotels.Room{HotelID: hotel.ID, Price: 1000, Capacity: 2, Units: 1}
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatal(err)
+	}
+
+	userRepo := users.NewMemoryUserRepo()
+	guest := users.User{Name: "Гость", Email: "guest@example.com", Phone: "+79990000007"}
+	if err := userRepo.Create(&guest); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewMemoryBookingRepo(store.Rooms(), userRepo)
+	quote := func(room hotels.Room, start, end time.Time, guests int) (*pricing.Quote, error) {
+		return &pricing.Quote{Total: room.Price * float64(len(pricing.Nights(start, end)))}, nil
+	}
+	h := NewHandler(repo, store.Hotels(), store.Rooms(), userRepo, quote)
+	h.Payments = stubGateway{}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/bookings", func(c *gin.Context) {
+		c.Set("user_id", guest.ID)
+	}, h.CreateBookingHandler)
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	body, _ := json.Marshal(CreateBookingInput{RoomID: room.ID, StartDate: start, EndDate: start.Add(48 * time.Hour)})
+	book := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/bookings", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := book()
+	if w.Code != http.StatusCreated {
+		t.Fatalf("первое бронирование: код %d, %s", w.Code, w.Body.String())
+	}
+	var created CreatedBooking
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.TotalCost != 2000 || created.PaymentURL != "https://pay.test/1" || created.Status != StatusPendingPayment {
+		t.Fatalf("неожиданное бронирование: %+v", created)
+	}
+
+	// ID платежа и письмо со ссылкой на оплату сохранены вместе
+	stored, _ := repo.ByID(created.ID)
+	emails := repo.Emails()
+	if stored.PaymentID != "pay-1" || len(emails) != 1 || emails[0].To != guest.Email || !strings.Contains(emails[0].Message.Text, "https://pay.test/1") {
+		t.Fatalf("платёж %q, письма %+v", stored.PaymentID, emails)
+	}
+
+	// Единственный номер уже занят
+	if w := book(); w.Code != http.StatusConflict {
+		t.Fatalf("второе бронирование: код %d, ожидался 409", w.Code)
+	}
+
+	events, err := repo.Events(created.ID)
+	if err != nil || len(events) != 1 || events[0].ToStatus != StatusPendingPayment {
+		t.Fatalf("журнал бронирования: %+v, %v", events, err)
+	}
+}
+
+func TestBookingEventsAccess(t *testing.T) {
+	store := hotels.NewMemory()
+	hotel := hotels.Hotel{Name: "Тестовый отель", OwnerID: 1}
+	if err := store.Hotels().Create(&hotel); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Hotels().AddStaff(&hotels.HotelStaff{HotelID: hotel.ID, UserID: 5, Role: hotels.StaffRoleManager}); err != nil {
+		t.Fatal(err)
+	}
+	room := hotels.Room{HotelID: hotel.ID, Price: 1000, Capacity: 2, Units: 1}
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatal(err)
+	}
+
+	userRepo := users.NewMemoryUserRepo()
+	repo := NewMemoryBookingRepo(store.Rooms(), userRepo)
+	start := time.Now().AddDate(0, 0, 10)
+	booking := Booking{RoomID: room.ID, UserID: 7, StartDate: start, EndDate: start.AddDate(0, 0, 1), TotalCost: 1000}
+	if err := repo.Create(&booking, UserActor(7), "тест"); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(repo, store.Hotels(), store.Rooms(), userRepo, nil)
+
+	tests := []struct {
+		name   string
+		userID uint
+		role   string
+		want   int
+	}{
+		{"гость бронирования", 7, "client", http.StatusOK},
+		{"другой гость", 8, "client", http.StatusForbidden},
+		{"администратор", 9, "admin", http.StatusOK},
+		{"владелец отеля", 1, "owner", http.StatusOK},
+		{"владелец другого отеля", 2, "owner", http.StatusForbidden},
+		{"менеджер в штате отеля", 5, "manager", http.StatusOK},
+		{"менеджер не из штата", 6, "manager", http.StatusForbidden},
+		// Роль без права на бронирования отелей не даёт доступа даже с ID владельца
+		{"владелец с ролью клиента", 1, "client", http.StatusForbidden},
+		{"неизвестная роль", 9, "superuser", http.StatusForbidden},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/bookings/:id/events", func(c *gin.Context) {
+				c.Set("user_id", tt.userID)
+				c.Set("role", tt.role)
+			}, h.GetBookingEventsHandler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/bookings/%d/events", booking.ID), nil))
+			if w.Code != tt.want {
+				t.Errorf("код %d, ожидался %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHotelStaffBookingAccess(t *testing.T) {
+	// Отель 1 — владелец 1, менеджер 5; менеджер 6 работает в другом отеле
+	store := hotels.NewMemory()
+	for _, hotel := range []hotels.Hotel{{Name: "Первый", OwnerID: 1}, {Name: "Второй", OwnerID: 2}} {
+		if err := store.Hotels().Create(&hotel); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, staff := range []hotels.HotelStaff{{HotelID: 1, UserID: 5}, {HotelID: 2, UserID: 6}} {
+		staff.Role = hotels.StaffRoleManager
+		if err := store.Hotels().AddStaff(&staff); err != nil {
+			t.Fatal(err)
+		}
+	}
+	room := hotels.Room{HotelID: 1, Price: 1000, Capacity: 2, Units: 10}
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatal(err)
+	}
+
+	userRepo := users.NewMemoryUserRepo()
+	repo := NewMemoryBookingRepo(store.Rooms(), userRepo)
+	quote := func(room hotels.Room, start, end time.Time, guests int) (*pricing.Quote, error) {
+		return &pricing.Quote{Total: room.Price * float64(len(pricing.Nights(start, end)))}, nil
+	}
+	h := NewHandler(repo, store.Hotels(), store.Rooms(), userRepo, quote)
+
+	start := time.Now().AddDate(0, 0, 10).Format(time.RFC3339)
+	end := time.Now().AddDate(0, 0, 12).Format(time.RFC3339)
+	offline := func(phone string) string {
+		return fmt.Sprintf(`{"room_id":%d,"start_date":%q,"end_date":%q,"phone_number":%q,"name":"Гость"}`, room.ID, start, end, phone)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		userID uint
+		role   string
+		want   int
+	}{
+		{"офлайн-бронирование менеджером отеля", http.MethodPost, "/booking/offline", offline("+79990000005"), 5, "manager", http.StatusCreated},
+		{"офлайн-бронирование менеджером другого отеля", http.MethodPost, "/booking/offline", offline("+79990000006"), 6, "manager", http.StatusForbidden},
+		{"бронирования отеля у владельца", http.MethodGet, "/owners/bookings?hotel_id=1", "", 1, "owner", http.StatusOK},
+		{"бронирования отеля у менеджера отеля", http.MethodGet, "/owners/bookings?hotel_id=1", "", 5, "manager", http.StatusOK},
+		{"бронирования отеля у менеджера другого отеля", http.MethodGet, "/owners/bookings?hotel_id=1", "", 6, "manager", http.StatusForbidden},
+		{"бронирования отеля у владельца другого отеля", http.MethodGet, "/owners/bookings?hotel_id=1", "", 2, "owner", http.StatusForbidden},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Set("user_id", tt.userID)
+				c.Set("role", tt.role)
+			})
+			r.POST("/booking/offline", h.CreateOfflineBookingHandler)
+			r.GET("/owners/bookings", h.GetOwnerBookingsHandler)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("код %d, ожидался %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	// Без фильтра менеджер другого отеля не видит чужих бронирований
+	r := gin.New()
+	r.GET("/owners/bookings", func(c *gin.Context) {
+		c.Set("user_id", uint(6))
+		c.Set("role", "manager")
+	}, h.GetOwnerBookingsHandler)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/owners/bookings", nil))
+	var listed []Booking
+	if err := json.Unmarshal(w.Body.Bytes(), &listed); w.Code != http.StatusOK || err != nil || len(listed) != 0 {
+		t.Fatalf("список менеджера другого отеля: %d %s", w.Code, w.Body.String())
+	}
+	if all, _ := repo.ByHotels([]uint{1}); len(all) != 1 {
+		t.Fatalf("бронирований отеля %d, ожидалось одно от его менеджера", len(all))
+	}
+}
+
+func TestHotelCancellationRefundsInFull(t *testing.T) {
+	store := hotels.NewMemory()
+	hotel := hotels.Hotel{Name: "Тестовый отель", OwnerID: 1}
+	if err := store.Hotels().Create(&hotel); err != nil {
+		t.Fatal(err)
+	}
+	room := hotels.Room{HotelID: hotel.ID, Price: 1000, Capacity: 2, Units: 1}
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatal(err)
+	}
+
+	userRepo := users.NewMemoryUserRepo()
+	repo := NewMemoryBookingRepo(store.Rooms(), userRepo)
+	// Невозвратный тариф защищает отель от отказа гостя, но не при отмене самим отелем
+	if err := repo.SavePolicy(&CancellationPolicy{HotelID: hotel.ID, NonRefundable: true}); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().AddDate(0, 0, 10)
+	booking := Booking{RoomID: room.ID, UserID: 7, StartDate: start, EndDate: start.AddDate(0, 0, 2), TotalCost: 2000,
+		Status: StatusConfirmed, PaymentStatus: paymentSucceeded, PaymentID: "pay-1"}
+	if err := repo.Create(&booking, UserActor(7), "тест"); err != nil {
+		t.Fatal(err)
+	}
+	g := &recordingGateway{}
+	h := NewHandler(repo, store.Hotels(), store.Rooms(), userRepo, nil)
+	h.Payments = g
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/owners/bookings/:id/status", func(c *gin.Context) {
+		c.Set("user_id", uint(1))
+		c.Set("role", "owner")
+	}, h.UpdateBookingStatusHandler)
+
+	body := strings.NewReader(`{"status":"cancelled","reason":"Отель закрыт на ремонт"}`)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, fmt.Sprintf("/owners/bookings/%d/status", booking.ID), body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("код %d: %s", w.Code, w.Body.String())
+	}
+	stored, _ := repo.ByID(booking.ID)
+	if stored.Status != StatusRefunded || stored.RefundedAmount != booking.TotalCost || len(g.amounts) != 1 || g.amounts[0] != booking.TotalCost {
+		t.Fatalf("после отмены отелем: %+v, возвраты %v", stored, g.amounts)
+	}
+}
+
+// failingPhoneLookup отвечает ошибкой базы на поиск по телефону
+type failingPhoneLookup struct {
+	*users.MemoryUserRepo
+}
+
+func (failingPhoneLookup) ByPhone(phone string) (users.User, error) {
+	return users.User{}, errors.New("соединение с базой потеряно")
+}
+
+func TestCreateOfflineBookingCreatesGuestWithBooking(t *testing.T) {
+	store := hotels.NewMemory()
+	hotel := hotels.Hotel{Name: "Тестовый отель", OwnerID: 1}
+	if err := store.Hotels().Create(&hotel); err != nil {
+		t.Fatal(err)
+	}
+	room := hotels.Room{HotelID: hotel.ID, Price: 1000, Capacity: 2, Units: 1}
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatal(err)
+	}
+
+	userRepo := users.NewMemoryUserRepo()
+	repo := NewMemoryBookingRepo(store.Rooms(), userRepo)
+	quote := func(room hotels.Room, start, end time.Time, guests int) (*pricing.Quote, error) {
+		return &pricing.Quote{Total: room.Price * float64(len(pricing.Nights(start, end)))}, nil
+	}
+	h := NewHandler(repo, store.Hotels(), store.Rooms(), userRepo, quote)
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	offline := func(h *Handler, input CreateOfflineBookingInput) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		r := gin.New()
+		r.POST("/bookings/offline", func(c *gin.Context) {
+			c.Set("user_id", hotel.OwnerID)
+		}, h.CreateOfflineBookingHandler)
+
+		body, _ := json.Marshal(input)
+		req := httptest.NewRequest(http.MethodPost, "/bookings/offline", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	input := func(phone string, adults int) CreateOfflineBookingInput {
+		return CreateOfflineBookingInput{RoomID: room.ID, StartDate: start, EndDate: start.Add(48 * time.Hour),
+			PhoneNumber: phone, Name: "Гость", Adults: adults}
+	}
+
+	w := offline(h, input("+79990000001", 1))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("первое бронирование: код %d, %s", w.Code, w.Body.String())
+	}
+	guest, err := userRepo.ByPhone("+79990000001")
+	if err != nil {
+		t.Fatalf("гость не создан: %v", err)
+	}
+	var created Booking
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || created.UserID != guest.ID {
+		t.Fatalf("бронирование %+v оформлено не на гостя %d: %v", created, guest.ID, err)
+	}
+
+	// Отказ на любой проверке не оставляет нового пользователя
+	tests := []struct {
+		name  string
+		input CreateOfflineBookingInput
+		code  int
+	}{
+		{"номер занят", input("+79990000002", 1), http.StatusConflict},
+		{"гостей больше вместимости", input("+79990000003", 5), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := offline(h, tt.input); w.Code != tt.code {
+				t.Fatalf("код %d, ожидался %d: %s", w.Code, tt.code, w.Body.String())
+			}
+			if user, err := userRepo.ByPhone(tt.input.PhoneNumber); err == nil {
+				t.Fatalf("создан пользователь %+v", user)
+			}
+		})
+	}
+
+	t.Run("ошибка поиска пользователя", func(t *testing.T) {
+		broken := NewHandler(repo, store.Hotels(), store.Rooms(), failingPhoneLookup{userRepo}, quote)
+		if w := offline(broken, input("+79990000004", 1)); w.Code != http.StatusInternalServerError {
+			t.Fatalf("код %d, ожидался 500: %s", w.Code, w.Body.String())
+		}
+		if user, err := userRepo.ByPhone("+79990000004"); err == nil {
+			t.Fatalf("создан пользователь %+v", user)
+		}
+	})
+}
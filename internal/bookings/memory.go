@@ -0,0 +1,372 @@
+package bookings
+
+import (
+	"context"
+	"fmt"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/outbox"
+	"hotel-booking/internal/users"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryBookingRepo хранит бронирования в памяти; используется в тестах обработчиков.
+// Номера и их отели берутся из rooms, гости офлайн-бронирований создаются в users.
+type MemoryBookingRepo struct {
+	rooms hotels.RoomRepo
+	users users.UserRepo
+
+	mu       sync.Mutex
+	nextID   uint
+	bookings map[uint]*Booking
+	events   []BookingEvent
+	policies []CancellationPolicy
+	emails   []outbox.Email
+}
+
+func NewMemoryBookingRepo(rooms hotels.RoomRepo, userRepo users.UserRepo) *MemoryBookingRepo {
+	return &MemoryBookingRepo{rooms: rooms, users: userRepo, bookings: map[uint]*Booking{}}
+}
+
+func (r *MemoryBookingRepo) id() uint {
+	r.nextID++
+	return r.nextID
+}
+
+// list возвращает копии бронирований, подходящих под match, в порядке ID
+func (r *MemoryBookingRepo) list(match func(b *Booking) bool) []Booking {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.listLocked(match)
+}
+
+func (r *MemoryBookingRepo) listLocked(match func(b *Booking) bool) []Booking {
+	var bookings []Booking
+	for _, booking := range r.bookings {
+		if !booking.DeletedAt.Valid && match(booking) {
+			bookings = append(bookings, *booking)
+		}
+	}
+	sort.Slice(bookings, func(i, j int) bool { return bookings[i].ID < bookings[j].ID })
+	return bookings
+}
+
+func (r *MemoryBookingRepo) ByID(id uint) (Booking, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	booking, ok := r.bookings[id]
+	if !ok || booking.DeletedAt.Valid {
+		return Booking{}, gorm.ErrRecordNotFound
+	}
+	return *booking, nil
+}
+
+func (r *MemoryBookingRepo) ByPaymentID(paymentID string) (Booking, error) {
+	found := r.list(func(b *Booking) bool { return paymentID != "" && b.PaymentID == paymentID })
+	if len(found) == 0 {
+		return Booking{}, gorm.ErrRecordNotFound
+	}
+	return found[0], nil
+}
+
+func (r *MemoryBookingRepo) ByUser(userID uint) ([]Booking, error) {
+	return r.list(func(b *Booking) bool { return b.UserID == userID }), nil
+}
+
+func (r *MemoryBookingRepo) ByRoom(roomID uint) ([]Booking, error) {
+	return r.list(func(b *Booking) bool { return b.RoomID == roomID }), nil
+}
+
+func (r *MemoryBookingRepo) ByHotels(hotelIDs []uint) ([]Booking, error) {
+	wanted := map[uint]bool{}
+	for _, id := range hotelIDs {
+		wanted[id] = true
+	}
+	var result []Booking
+	for _, booking := range r.list(func(*Booking) bool { return true }) {
+		room, err := r.rooms.ByIDUnscoped(booking.RoomID)
+		if err != nil {
+			continue
+		}
+		if wanted[room.HotelID] {
+			result = append(result, booking)
+		}
+	}
+	return result, nil
+}
+
+func overlaps(b *Booking, roomID uint, start, end time.Time) bool {
+	if b.RoomID != roomID || !b.EndDate.After(start) || !b.StartDate.Before(end) {
+		return false
+	}
+	for _, status := range ActiveStatuses {
+		if b.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryBookingRepo) DueForExpiry(now, createdBefore time.Time) ([]Booking, error) {
+	return r.list(func(b *Booking) bool {
+		if b.Status != StatusPendingPayment || b.IsOfflineBooking {
+			return false
+		}
+		if b.ExpiresAt != nil {
+			return !b.ExpiresAt.After(now)
+		}
+		return !b.CreatedAt.After(createdBefore)
+	}), nil
+}
+
+func (r *MemoryBookingRepo) Overlapping(roomID uint, start, end time.Time) ([]Booking, error) {
+	return r.list(func(b *Booking) bool { return overlaps(b, roomID, start, end) }), nil
+}
+
+func (r *MemoryBookingRepo) Create(booking *Booking, actor Actor, reason string) error {
+	return r.CreateWithGuest(booking, nil, actor, reason)
+}
+
+func (r *MemoryBookingRepo) CreateWithGuest(booking *Booking, guest *users.User, actor Actor, reason string) error {
+	normalizeDates(booking)
+	room, err := r.rooms.ByID(booking.RoomID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	overlapping := r.listLocked(func(b *Booking) bool { return overlaps(b, room.ID, booking.StartDate, booking.EndDate) })
+	for _, free := range freeUnits(room, nightlyOccupancy(overlapping, booking.StartDate, booking.EndDate)) {
+		if free < 1 {
+			return ErrRoomUnavailable
+		}
+	}
+
+	if guest != nil {
+		if err := resolveGuest(r.users, guest); err != nil {
+			return err
+		}
+		booking.UserID = guest.ID
+	}
+
+	// Значения по умолчанию из схемы
+	if booking.Status == "" {
+		booking.Status = StatusPendingPayment
+	}
+	if booking.PaymentStatus == "" {
+		booking.PaymentStatus = "pending"
+	}
+	booking.ID = r.id()
+	if booking.CreatedAt.IsZero() {
+		booking.CreatedAt = time.Now()
+	}
+	booking.UpdatedAt = time.Now()
+	stored := *booking
+	r.bookings[booking.ID] = &stored
+	r.recordLocked(booking.ID, "", booking.Status, actor, reason)
+	return nil
+}
+
+func (r *MemoryBookingRepo) recordLocked(bookingID uint, from, to BookingStatus, actor Actor, reason string) {
+	r.events = append(r.events, BookingEvent{
+		ID:         r.id(),
+		BookingID:  bookingID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorType:  actor.Type,
+		ActorID:    actor.UserID,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// transitionLocked повторяет Transition для данных в памяти
+func (r *MemoryBookingRepo) transitionLocked(booking *Booking, to BookingStatus, actor Actor, reason string) error {
+	from := booking.Status
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+	stored, ok := r.bookings[booking.ID]
+	if !ok || stored.Status != from {
+		return ErrStaleBooking
+	}
+	stored.Status = to
+	stored.UpdatedAt = time.Now()
+	booking.Status = to
+	r.recordLocked(booking.ID, from, to, actor, reason)
+	return nil
+}
+
+func (r *MemoryBookingRepo) Transition(booking *Booking, to BookingStatus, actor Actor, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.transitionLocked(booking, to, actor, reason)
+}
+
+func (r *MemoryBookingRepo) TransitionWithEmail(ctx context.Context, booking *Booking, to BookingStatus, actor Actor, reason string, mail outbox.Email) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.transitionLocked(booking, to, actor, reason); err != nil {
+		return err
+	}
+	r.emails = append(r.emails, mail)
+	return nil
+}
+
+// Emails возвращает письма, поставленные в очередь вместе с изменением бронирований
+func (r *MemoryBookingRepo) Emails() []outbox.Email {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]outbox.Email(nil), r.emails...)
+}
+
+func (r *MemoryBookingRepo) CancelPaid(booking *Booking, refundAmount float64, actor Actor, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.transitionLocked(booking, StatusCancelled, actor, reason); err != nil {
+		return err
+	}
+	stored := r.bookings[booking.ID]
+	amount := refundAmount
+	stored.RefundAmount, booking.RefundAmount = &amount, &refundAmount
+	return nil
+}
+
+// setPaymentStatus меняет статус оплаты from на to, только если он всё ещё from
+func (r *MemoryBookingRepo) setPaymentStatus(booking *Booking, from, to string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.bookings[booking.ID]
+	if !ok || stored.PaymentStatus != from {
+		return false
+	}
+	stored.PaymentStatus, booking.PaymentStatus = to, to
+	return true
+}
+
+func (r *MemoryBookingRepo) ClaimRefund(booking *Booking) error {
+	if !r.setPaymentStatus(booking, paymentSucceeded, paymentRefundPending) {
+		return ErrRefundInProgress
+	}
+	return nil
+}
+
+func (r *MemoryBookingRepo) ReleaseRefund(booking *Booking) error {
+	r.setPaymentStatus(booking, paymentRefundPending, paymentSucceeded)
+	return nil
+}
+
+func (r *MemoryBookingRepo) Refunded(booking *Booking, amount float64, actor Actor, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !CanTransition(booking.Status, StatusRefunded) {
+		r.recordLocked(booking.ID, booking.Status, booking.Status, actor, reason)
+	} else if err := r.transitionLocked(booking, StatusRefunded, actor, reason); err != nil {
+		return err
+	}
+	stored := r.bookings[booking.ID]
+	stored.PaymentStatus, booking.PaymentStatus = "refunded", "refunded"
+	stored.RefundedAmount, booking.RefundedAmount = amount, amount
+	return nil
+}
+
+func (r *MemoryBookingRepo) SetPaymentID(booking *Booking, paymentID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.setPaymentIDLocked(booking, paymentID)
+}
+
+func (r *MemoryBookingRepo) setPaymentIDLocked(booking *Booking, paymentID string) error {
+	stored, ok := r.bookings[booking.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	stored.PaymentID = paymentID
+	booking.PaymentID = paymentID
+	return nil
+}
+
+func (r *MemoryBookingRepo) SetPaymentIDWithEmail(ctx context.Context, booking *Booking, paymentID string, mail outbox.Email) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.setPaymentIDLocked(booking, paymentID); err != nil {
+		return err
+	}
+	r.emails = append(r.emails, mail)
+	return nil
+}
+
+func (r *MemoryBookingRepo) SetPaymentStatus(booking *Booking, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.bookings[booking.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	stored.PaymentStatus, booking.PaymentStatus = status, status
+	return nil
+}
+
+func (r *MemoryBookingRepo) Events(bookingID uint) ([]BookingEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []BookingEvent
+	for _, event := range r.events {
+		if event.BookingID == bookingID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *MemoryBookingRepo) PolicyFor(room hotels.Room) (*CancellationPolicy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found *CancellationPolicy
+	for i, policy := range r.policies {
+		if policy.HotelID != room.HotelID {
+			continue
+		}
+		if policy.RoomID == room.ID {
+			p := r.policies[i]
+			return &p, nil
+		}
+		if policy.RoomID == 0 {
+			p := r.policies[i]
+			found = &p
+		}
+	}
+	return found, nil
+}
+
+func (r *MemoryBookingRepo) SavePolicy(policy *CancellationPolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for i, existing := range r.policies {
+		if existing.HotelID == policy.HotelID && existing.RoomID == policy.RoomID {
+			policy.ID, policy.CreatedAt, policy.UpdatedAt = existing.ID, existing.CreatedAt, now
+			r.policies[i] = *policy
+			return nil
+		}
+	}
+	policy.ID, policy.CreatedAt, policy.UpdatedAt = r.id(), now, now
+	r.policies = append(r.policies, *policy)
+	return nil
+}
+
+func (r *MemoryBookingRepo) DeletePolicy(hotelID, roomID uint) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, policy := range r.policies {
+		if policy.HotelID == hotelID && policy.RoomID == roomID {
+			r.policies = append(r.policies[:i], r.policies[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -9,12 +9,41 @@ import (
 type Booking struct {
 	gorm.Model
 	CreatedAt        time.Time
-	RoomID           uint      `gorm:"not null"`
-	UserID           uint      `gorm:"not null"`
-	StartDate        time.Time `gorm:"not null"`
-	EndDate          time.Time `gorm:"not null"`
-	TotalCost        float64   `gorm:"not null"` //Итоговая стоимость
-	PaymentStatus    string    `gorm:"type:varchar(20);default:'pending'"`
-	PaymentID        string    `gorm:"type:varchar(50)"`
-	IsOfflineBooking bool      `gorm:"default:false"`
+	RoomID           uint          `gorm:"not null"`
+	UserID           uint          `gorm:"not null"`
+	StartDate        time.Time     `gorm:"not null"`
+	EndDate          time.Time     `gorm:"not null"`
+	Adults           int           `gorm:"not null;default:1"` // Количество взрослых
+	Children         int           `gorm:"not null;default:0"` // Количество детей
+	TotalCost        float64       `gorm:"not null"`           //Итоговая стоимость
+	Status           BookingStatus `gorm:"type:varchar(20);default:'pending_payment';index"`
+	PaymentStatus    string        `gorm:"type:varchar(20);default:'pending'"` // Статус платежа у платёжной системы
+	PaymentID        string        `gorm:"type:varchar(50)"`
+	RefundedAmount   float64       `gorm:"default:0"` // Сумма, возвращённая гостю при отмене
+	RefundAmount     *float64      // Сумма к возврату, определённая при отмене оплаченного бронирования
+	IsOfflineBooking bool          `gorm:"default:false"`
+	ExpiresAt        *time.Time    `gorm:"index"` // Срок оплаты онлайн-бронирования, после него бронирование истекает
+}
+
+// BookingEvent — запись журнала переходов бронирования между статусами
+type BookingEvent struct {
+	ID         uint          `gorm:"primarykey"`
+	BookingID  uint          `gorm:"not null;index"`
+	FromStatus BookingStatus `gorm:"type:varchar(20)"`
+	ToStatus   BookingStatus `gorm:"type:varchar(20);not null"`
+	ActorType  string        `gorm:"type:varchar(20);not null"` // user, system или payment
+	ActorID    *uint         // ID пользователя, если переход выполнил пользователь
+	Reason     string        `gorm:"type:text"`
+	CreatedAt  time.Time
+}
+
+// CancellationPolicy — условия отмены для отеля (RoomID = 0) или отдельного номера.
+// Политика номера важнее политики отеля.
+type CancellationPolicy struct {
+	gorm.Model
+	HotelID        uint    `gorm:"not null;uniqueIndex:idx_cancellation_policies_scope"`
+	RoomID         uint    `gorm:"not null;default:0;uniqueIndex:idx_cancellation_policies_scope"` // 0 — политика всего отеля
+	FreeUntilDays  int     `gorm:"not null;default:0"`                                             // Бесплатная отмена не позднее чем за N дней до заезда
+	PenaltyPercent float64 `gorm:"not null;default:0"`                                             // Штраф в % от стоимости при более поздней отмене
+	NonRefundable  bool    `gorm:"default:false"`                                                  // Невозвратный тариф
 }
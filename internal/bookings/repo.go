@@ -0,0 +1,310 @@
+package bookings
+
+import (
+	"context"
+	"errors"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/outbox"
+	"hotel-booking/internal/users"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BookingRepo — бронирования, журнал их переходов и политики отмены.
+// Не найденное бронирование возвращается как gorm.ErrRecordNotFound в обеих реализациях.
+type BookingRepo interface {
+	ByID(id uint) (Booking, error)
+	ByPaymentID(paymentID string) (Booking, error)
+	ByUser(userID uint) ([]Booking, error)
+	ByRoom(roomID uint) ([]Booking, error)
+	// ByHotels возвращает бронирования номеров отелей, в том числе удалённых номеров
+	ByHotels(hotelIDs []uint) ([]Booking, error)
+	// DueForExpiry возвращает онлайн-бронирования, ожидающие оплаты, у которых ExpiresAt
+	// наступил к now, а без ExpiresAt — созданные не позже createdBefore
+	DueForExpiry(now, createdBefore time.Time) ([]Booking, error)
+	// Overlapping возвращает активные бронирования номера, пересекающиеся с периодом
+	Overlapping(roomID uint, start, end time.Time) ([]Booking, error)
+	// Create атомарно проверяет наличие свободного номера на каждую ночь и сохраняет
+	// бронирование вместе с первой записью журнала. Если мест нет, возвращает ErrRoomUnavailable.
+	// Даты заезда и выезда сохраняются как календарные даты (pricing.Date).
+	Create(booking *Booking, actor Actor, reason string) error
+	// CreateWithGuest выполняет Create для гостя guest. Ещё не сохранённый гость (ID = 0)
+	// ищется по телефону и при необходимости создаётся в той же транзакции, после блокировки
+	// номера: если номер занят, гость не остаётся в базе.
+	CreateWithGuest(booking *Booking, guest *users.User, actor Actor, reason string) error
+	// Transition выполняет Transition в отдельной транзакции
+	Transition(booking *Booking, to BookingStatus, actor Actor, reason string) error
+	// TransitionWithEmail выполняет Transition и ставит письмо mail в очередь в одной транзакции:
+	// письмо уйдёт, только если статус сменился. ID запроса для журнала отправки берётся из ctx.
+	TransitionWithEmail(ctx context.Context, booking *Booking, to BookingStatus, actor Actor, reason string, mail outbox.Email) error
+	// CancelPaid отменяет оплаченное бронирование и в той же транзакции сохраняет
+	// сумму возврата refundAmount: повторы возврата берут её, а не пересчитывают
+	CancelPaid(booking *Booking, refundAmount float64, actor Actor, reason string) error
+	// ClaimRefund атомарно переводит оплату из succeeded в refund_pending, чтобы деньги
+	// возвращал только один запрос. Если оплата уже не succeeded, возвращает ErrRefundInProgress.
+	ClaimRefund(booking *Booking) error
+	// ReleaseRefund возвращает оплату из refund_pending в succeeded, если возврат не прошёл
+	ReleaseRefund(booking *Booking) error
+	// Refunded отмечает возврат amount гостю и переводит бронирование в refunded.
+	// Истёкшее бронирование в refunded не переходит: возврат только записывается в журнал.
+	Refunded(booking *Booking, amount float64, actor Actor, reason string) error
+	SetPaymentID(booking *Booking, paymentID string) error
+	// SetPaymentIDWithEmail сохраняет ID платежа и ставит письмо mail в очередь в одной транзакции.
+	// ID запроса для журнала отправки берётся из ctx.
+	SetPaymentIDWithEmail(ctx context.Context, booking *Booking, paymentID string, mail outbox.Email) error
+	// SetPaymentStatus сохраняет статус оплаты из уведомления платёжной системы
+	SetPaymentStatus(booking *Booking, status string) error
+	Events(bookingID uint) ([]BookingEvent, error)
+
+	// PolicyFor возвращает политику отмены номера: политику самого номера,
+	// иначе политику отеля. Если ни одна не задана, возвращает nil — отмена бесплатная.
+	PolicyFor(room hotels.Room) (*CancellationPolicy, error)
+	// SavePolicy создаёт или заменяет политику отеля или номера
+	SavePolicy(policy *CancellationPolicy) error
+	// DeletePolicy удаляет политику (roomID = 0 — отеля); false, если её не было
+	DeletePolicy(hotelID, roomID uint) (bool, error)
+}
+
+// GormBookingRepo хранит бронирования в базе данных
+type GormBookingRepo struct {
+	db *gorm.DB
+}
+
+func NewGormBookingRepo(db *gorm.DB) *GormBookingRepo {
+	return &GormBookingRepo{db: db}
+}
+
+func (r *GormBookingRepo) ByID(id uint) (Booking, error) {
+	var booking Booking
+	err := r.db.First(&booking, id).Error
+	return booking, err
+}
+
+func (r *GormBookingRepo) ByPaymentID(paymentID string) (Booking, error) {
+	var booking Booking
+	err := r.db.Where("payment_id = ?", paymentID).First(&booking).Error
+	return booking, err
+}
+
+func (r *GormBookingRepo) ByUser(userID uint) ([]Booking, error) {
+	var bookings []Booking
+	err := r.db.Where("user_id = ?", userID).Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *GormBookingRepo) ByRoom(roomID uint) ([]Booking, error) {
+	var bookings []Booking
+	err := r.db.Where("room_id = ?", roomID).Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *GormBookingRepo) ByHotels(hotelIDs []uint) ([]Booking, error) {
+	var bookings []Booking
+	if len(hotelIDs) == 0 {
+		return bookings, nil
+	}
+	err := r.db.
+		Joins("JOIN rooms r ON bookings.room_id = r.id").
+		Where("r.hotel_id IN ?", hotelIDs).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *GormBookingRepo) DueForExpiry(now, createdBefore time.Time) ([]Booking, error) {
+	var bookings []Booking
+	err := r.db.Where(
+		"status = ? AND is_offline_booking = ? AND (expires_at <= ? OR (expires_at IS NULL AND created_at <= ?))",
+		StatusPendingPayment,
+		false,
+		now,
+		createdBefore,
+	).Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *GormBookingRepo) Overlapping(roomID uint, start, end time.Time) ([]Booking, error) {
+	var overlapping []Booking
+	err := r.db.Where("room_id = ? AND status IN ? AND NOT (end_date <= ? OR start_date >= ?)",
+		roomID, ActiveStatuses, start, end).Find(&overlapping).Error
+	return overlapping, err
+}
+
+func (r *GormBookingRepo) Create(booking *Booking, actor Actor, reason string) error {
+	return r.CreateWithGuest(booking, nil, actor, reason)
+}
+
+func (r *GormBookingRepo) CreateWithGuest(booking *Booking, guest *users.User, actor Actor, reason string) error {
+	normalizeDates(booking)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Блокируем тип номера: параллельные бронирования одного типа выполняются по очереди
+		var room hotels.Room
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&room, booking.RoomID).Error; err != nil {
+			return err
+		}
+
+		available, err := AvailableUnits(&GormBookingRepo{db: tx}, room, booking.StartDate, booking.EndDate)
+		if err != nil {
+			return err
+		}
+		for _, free := range available {
+			if free < 1 {
+				return ErrRoomUnavailable
+			}
+		}
+
+		// Гость ищется под блокировкой номера: параллельный запрос с тем же телефоном
+		// уже создал его и зафиксировал транзакцию
+		if guest != nil {
+			if err := resolveGuest(users.NewGormUserRepo(tx), guest); err != nil {
+				return err
+			}
+			booking.UserID = guest.ID
+		}
+
+		if err := tx.Create(booking).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, booking.ID, "", booking.Status, actor, reason)
+	})
+}
+
+// resolveGuest находит ещё не сохранённого гостя по телефону или создаёт его
+func resolveGuest(userRepo users.UserRepo, guest *users.User) error {
+	if guest.ID != 0 {
+		return nil
+	}
+	existing, err := userRepo.ByPhone(guest.Phone)
+	if err == nil {
+		*guest = existing
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return userRepo.Create(guest)
+}
+
+func (r *GormBookingRepo) Transition(booking *Booking, to BookingStatus, actor Actor, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return Transition(tx, booking, to, actor, reason)
+	})
+}
+
+func (r *GormBookingRepo) TransitionWithEmail(ctx context.Context, booking *Booking, to BookingStatus, actor Actor, reason string, mail outbox.Email) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := Transition(tx, booking, to, actor, reason); err != nil {
+			return err
+		}
+		return outbox.EnqueueEmail(tx, mail.To, mail.Message)
+	})
+}
+
+func (r *GormBookingRepo) CancelPaid(booking *Booking, refundAmount float64, actor Actor, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := Transition(tx, booking, StatusCancelled, actor, reason); err != nil {
+			return err
+		}
+		if err := tx.Model(booking).Update("refund_amount", refundAmount).Error; err != nil {
+			return err
+		}
+		booking.RefundAmount = &refundAmount
+		return nil
+	})
+}
+
+// setPaymentStatus меняет статус оплаты from на to, только если в базе он всё ещё from
+func (r *GormBookingRepo) setPaymentStatus(booking *Booking, from, to string) (bool, error) {
+	result := r.db.Model(&Booking{}).
+		Where("id = ? AND payment_status = ?", booking.ID, from).
+		Update("payment_status", to)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	booking.PaymentStatus = to
+	return true, nil
+}
+
+func (r *GormBookingRepo) ClaimRefund(booking *Booking) error {
+	claimed, err := r.setPaymentStatus(booking, paymentSucceeded, paymentRefundPending)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrRefundInProgress
+	}
+	return nil
+}
+
+func (r *GormBookingRepo) ReleaseRefund(booking *Booking) error {
+	_, err := r.setPaymentStatus(booking, paymentRefundPending, paymentSucceeded)
+	return err
+}
+
+func (r *GormBookingRepo) Refunded(booking *Booking, amount float64, actor Actor, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(booking).Updates(map[string]interface{}{
+			"payment_status":  "refunded",
+			"refunded_amount": amount,
+		}).Error; err != nil {
+			return err
+		}
+		booking.PaymentStatus, booking.RefundedAmount = "refunded", amount
+		if !CanTransition(booking.Status, StatusRefunded) {
+			return recordEvent(tx, booking.ID, booking.Status, booking.Status, actor, reason)
+		}
+		return Transition(tx, booking, StatusRefunded, actor, reason)
+	})
+}
+
+func (r *GormBookingRepo) SetPaymentID(booking *Booking, paymentID string) error {
+	return r.db.Model(booking).Update("payment_id", paymentID).Error
+}
+
+func (r *GormBookingRepo) SetPaymentIDWithEmail(ctx context.Context, booking *Booking, paymentID string, mail outbox.Email) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(booking).Update("payment_id", paymentID).Error; err != nil {
+			return err
+		}
+		return outbox.EnqueueEmail(tx, mail.To, mail.Message)
+	})
+}
+
+func (r *GormBookingRepo) SetPaymentStatus(booking *Booking, status string) error {
+	if err := r.db.Model(booking).Update("payment_status", status).Error; err != nil {
+		return err
+	}
+	booking.PaymentStatus = status
+	return nil
+}
+
+func (r *GormBookingRepo) Events(bookingID uint) ([]BookingEvent, error) {
+	var events []BookingEvent
+	err := r.db.Where("booking_id = ?", bookingID).Order("created_at, id").Find(&events).Error
+	return events, err
+}
+
+func (r *GormBookingRepo) PolicyFor(room hotels.Room) (*CancellationPolicy, error) {
+	var policies []CancellationPolicy
+	if err := r.db.Where("hotel_id = ? AND room_id IN ?", room.HotelID, []uint{0, room.ID}).
+		Order("room_id DESC").Limit(1).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	return &policies[0], nil
+}
+
+func (r *GormBookingRepo) SavePolicy(policy *CancellationPolicy) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hotel_id"}, {Name: "room_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"free_until_days", "penalty_percent", "non_refundable", "updated_at"}),
+	}).Create(policy).Error
+}
+
+func (r *GormBookingRepo) DeletePolicy(hotelID, roomID uint) (bool, error) {
+	result := r.db.Unscoped().Where("hotel_id = ? AND room_id = ?", hotelID, roomID).Delete(&CancellationPolicy{})
+	return result.RowsAffected > 0, result.Error
+}
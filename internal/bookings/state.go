@@ -0,0 +1,116 @@
+package bookings
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BookingStatus string
+
+// Жизненный цикл бронирования
+const (
+	StatusPendingPayment BookingStatus = "pending_payment" // ожидает оплаты
+	StatusConfirmed      BookingStatus = "confirmed"       // оплачено или подтверждено отелем
+	StatusCheckedIn      BookingStatus = "checked_in"      // гость заселился
+	StatusCheckedOut     BookingStatus = "checked_out"     // гость выехал
+	StatusCancelled      BookingStatus = "cancelled"       // отменено
+	StatusExpired        BookingStatus = "expired"         // не оплачено вовремя
+	StatusRefunded       BookingStatus = "refunded"        // оплата возвращена
+	StatusNoShow         BookingStatus = "no_show"         // гость не приехал
+)
+
+// ActiveStatuses — статусы, в которых бронирование занимает номер
+var ActiveStatuses = []BookingStatus{StatusPendingPayment, StatusConfirmed, StatusCheckedIn}
+
+// transitions — единственное место, где описаны допустимые переходы между статусами
+var transitions = map[BookingStatus][]BookingStatus{
+	StatusPendingPayment: {StatusConfirmed, StatusCancelled, StatusExpired},
+	StatusConfirmed:      {StatusCheckedIn, StatusCancelled, StatusRefunded, StatusNoShow},
+	StatusCheckedIn:      {StatusCheckedOut},
+	StatusCancelled:      {StatusRefunded},
+}
+
+// Типы инициаторов перехода
+const (
+	ActorUser    = "user"
+	ActorSystem  = "system"
+	ActorPayment = "payment"
+)
+
+var (
+	ErrInvalidTransition = errors.New("недопустимая смена статуса бронирования")
+	ErrStaleBooking      = errors.New("статус бронирования был изменён параллельно")
+)
+
+// Actor — кто меняет статус бронирования
+type Actor struct {
+	Type   string
+	UserID *uint
+}
+
+func UserActor(userID uint) Actor {
+	return Actor{Type: ActorUser, UserID: &userID}
+}
+
+var (
+	SystemActor  = Actor{Type: ActorSystem}
+	PaymentActor = Actor{Type: ActorPayment}
+)
+
+func (s BookingStatus) IsValid() bool {
+	switch s {
+	case StatusPendingPayment, StatusConfirmed, StatusCheckedIn, StatusCheckedOut,
+		StatusCancelled, StatusExpired, StatusRefunded, StatusNoShow:
+		return true
+	}
+	return false
+}
+
+// CanTransition проверяет, разрешён ли переход из from в to
+func CanTransition(from, to BookingStatus) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition переводит бронирование в новый статус и записывает событие в журнал.
+// Вызывается внутри транзакции tx; если статус в базе уже изменился, возвращает ErrStaleBooking.
+func Transition(tx *gorm.DB, booking *Booking, to BookingStatus, actor Actor, reason string) error {
+	from := booking.Status
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+
+	result := tx.Model(&Booking{}).
+		Where("id = ? AND status = ?", booking.ID, from).
+		Update("status", to)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleBooking
+	}
+
+	booking.Status = to
+	return recordEvent(tx, booking.ID, from, to, actor, reason)
+}
+
+// recordEvent добавляет запись в журнал переходов бронирования
+func recordEvent(tx *gorm.DB, bookingID uint, from, to BookingStatus, actor Actor, reason string) error {
+	event := BookingEvent{
+		BookingID:  bookingID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorType:  actor.Type,
+		ActorID:    actor.UserID,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	}
+	return tx.Create(&event).Error
+}
@@ -0,0 +1,83 @@
+package bookings
+
+import (
+	"errors"
+	"fmt"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/users"
+	"testing"
+	"time"
+)
+
+var allStatuses = []BookingStatus{
+	StatusPendingPayment, StatusConfirmed, StatusCheckedIn, StatusCheckedOut,
+	StatusCancelled, StatusExpired, StatusRefunded, StatusNoShow,
+}
+
+// allowedTransitions повторяет жизненный цикл бронирования независимо от transitions,
+// чтобы случайная правка таблицы переходов не прошла незамеченной
+var allowedTransitions = map[[2]BookingStatus]bool{
+	{StatusPendingPayment, StatusConfirmed}: true,
+	{StatusPendingPayment, StatusCancelled}: true,
+	{StatusPendingPayment, StatusExpired}:   true,
+	{StatusConfirmed, StatusCheckedIn}:      true,
+	{StatusConfirmed, StatusCancelled}:      true,
+	{StatusConfirmed, StatusRefunded}:       true,
+	{StatusConfirmed, StatusNoShow}:         true,
+	{StatusCheckedIn, StatusCheckedOut}:     true,
+	{StatusCancelled, StatusRefunded}:       true,
+}
+
+func TestTransitionsBetweenAllStatuses(t *testing.T) {
+	for _, from := range allStatuses {
+		for _, to := range allStatuses {
+			want := allowedTransitions[[2]BookingStatus{from, to}]
+			t.Run(fmt.Sprintf("%s->%s", from, to), func(t *testing.T) {
+				if got := CanTransition(from, to); got != want {
+					t.Fatalf("CanTransition = %v, ожидалось %v", got, want)
+				}
+
+				store := hotels.NewMemory()
+				room := hotels.Room{HotelID: 1, Price: 1000, Capacity: 2, Units: 1}
+				if err := store.Rooms().Create(&room); err != nil {
+					t.Fatal(err)
+				}
+				repo := NewMemoryBookingRepo(store.Rooms(), users.NewMemoryUserRepo())
+				start := time.Now().AddDate(0, 0, 10)
+				booking := Booking{RoomID: room.ID, UserID: 7, StartDate: start, EndDate: start.AddDate(0, 0, 1), TotalCost: 1000, Status: from}
+				if err := repo.Create(&booking, SystemActor, "тест"); err != nil {
+					t.Fatal(err)
+				}
+				before, _ := repo.Events(booking.ID)
+
+				err := repo.Transition(&booking, to, UserActor(1), "проверка перехода")
+				stored, _ := repo.ByID(booking.ID)
+				events, _ := repo.Events(booking.ID)
+				if !want {
+					if !errors.Is(err, ErrInvalidTransition) {
+						t.Fatalf("Transition: %v, ожидалась ErrInvalidTransition", err)
+					}
+					if stored.Status != from || booking.Status != from || len(events) != len(before) {
+						t.Fatalf("запрещённый переход изменил бронирование: %s, событий %d, было %d", stored.Status, len(events), len(before))
+					}
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("Transition: %v", err)
+				}
+				if stored.Status != to || booking.Status != to {
+					t.Fatalf("статус после перехода: %s (в базе %s), ожидался %s", booking.Status, stored.Status, to)
+				}
+				if len(events) != len(before)+1 {
+					t.Fatalf("событий %d, ожидалось %d", len(events), len(before)+1)
+				}
+				last := events[len(events)-1]
+				if last.FromStatus != from || last.ToStatus != to || last.ActorType != ActorUser ||
+					last.ActorID == nil || *last.ActorID != 1 || last.Reason != "проверка перехода" {
+					t.Fatalf("запись журнала: %+v", last)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile — YAML-файл, который читается, если CONFIG_FILE не задан. Его отсутствие не ошибка.
+const DefaultFile = "config.yaml"
+
+// Config — настройки приложения. Загружаются один раз при старте через Load
+// и передаются в подсистемы из main; сами подсистемы переменные окружения не читают.
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	URLs     URLConfig      `yaml:"urls"`
+	Database DatabaseConfig `yaml:"database"`
+	JWT      JWTConfig      `yaml:"jwt"`
+	Mail     MailConfig     `yaml:"mail"`
+	Payments PaymentsConfig `yaml:"payments"`
+	Booking  BookingConfig  `yaml:"booking"`
+	Outbox   OutboxConfig   `yaml:"outbox"`
+	WebDAV   WebDAVConfig   `yaml:"webdav"`
+	Log      LogConfig      `yaml:"log"`
+}
+
+type ServerConfig struct {
+	Addr           string   `yaml:"addr" env:"HTTP_ADDR"`
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"` // Прокси, которым доверяется X-Forwarded-For
+	CORSOrigins    []string `yaml:"cors_origins" env:"CORS_ORIGINS"`       // По умолчанию — адрес фронтенда
+
+	ReadHeaderTimeoutSeconds int `yaml:"read_header_timeout_seconds" env:"HTTP_READ_HEADER_TIMEOUT_SECONDS"`
+	ReadTimeoutSeconds       int `yaml:"read_timeout_seconds" env:"HTTP_READ_TIMEOUT_SECONDS"` // Включая загрузку изображений
+	WriteTimeoutSeconds      int `yaml:"write_timeout_seconds" env:"HTTP_WRITE_TIMEOUT_SECONDS"`
+	IdleTimeoutSeconds       int `yaml:"idle_timeout_seconds" env:"HTTP_IDLE_TIMEOUT_SECONDS"`
+	ShutdownTimeoutSeconds   int `yaml:"shutdown_timeout_seconds" env:"HTTP_SHUTDOWN_TIMEOUT_SECONDS"`   // Сколько ждать завершения запросов и фоновых задач при остановке
+	ReadinessTimeoutSeconds  int `yaml:"readiness_timeout_seconds" env:"HTTP_READINESS_TIMEOUT_SECONDS"` // Ограничение на проверки /readyz
+}
+
+// URLConfig — внешние адреса, из которых строятся ссылки в письмах и платежах
+type URLConfig struct {
+	Backend  string `yaml:"backend" env:"URL_BACKEND"`
+	Frontend string `yaml:"frontend" env:"URL_FRONTEND"`
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	SSLMode  string `yaml:"sslmode" env:"DB_SSLMODE"`
+}
+
+// JWTConfig — ключи подписи токенов: Keys в формате kid:alg:путь через запятую или HS256-секрет Key
+type JWTConfig struct {
+	Keys      string `yaml:"keys" env:"JWT_KEYS"`
+	Key       string `yaml:"key" env:"JWT_KEY"`
+	ActiveKID string `yaml:"active_kid" env:"JWT_ACTIVE_KID"`
+}
+
+type MailConfig struct {
+	Transport string `yaml:"transport" env:"MAIL_TRANSPORT"` // smtp, file или memory
+	From      string `yaml:"from" env:"SMTP_EMAIL"`
+	FromName  string `yaml:"from_name" env:"SMTP_FROM_NAME"`
+	Host      string `yaml:"host" env:"SMTP_HOST"`
+	Port      string `yaml:"port" env:"SMTP_PORT"`
+	Username  string `yaml:"username" env:"SMTP_USERNAME"` // По умолчанию совпадает с From
+	Password  string `yaml:"password" env:"SMTP_PASSWORD"`
+	TLS       string `yaml:"tls" env:"SMTP_TLS"` // starttls, tls или none
+	Dir       string `yaml:"dir" env:"MAIL_DIR"` // Каталог для транспорта file
+}
+
+type PaymentsConfig struct {
+	Provider          string   `yaml:"provider" env:"PAYMENT_PROVIDER"` // yookassa или fake
+	ShopID            string   `yaml:"shop_id" env:"YOKASSA_SHOP_ID"`
+	SecretKey         string   `yaml:"secret_key" env:"YOKASSA_SECRET_KEY"`
+	ReturnURL         string   `yaml:"return_url" env:"PAYMENT_RETURN_URL"` // Куда провайдер возвращает гостя после оплаты
+	WebhookAllowedIPs []string `yaml:"webhook_allowed_ips" env:"PAYMENT_WEBHOOK_ALLOWED_IPS"`
+}
+
+type BookingConfig struct {
+	HoldMinutes        int `yaml:"hold_minutes" env:"BOOKING_HOLD_MINUTES"`
+	ExpiryCheckSeconds int `yaml:"expiry_check_seconds" env:"BOOKING_EXPIRY_CHECK_SECONDS"`
+}
+
+type OutboxConfig struct {
+	IntervalSeconds int `yaml:"interval_seconds" env:"OUTBOX_INTERVAL_SECONDS"`
+	MaxAttempts     int `yaml:"max_attempts" env:"OUTBOX_MAX_ATTEMPTS"`
+}
+
+// WebDAVConfig — облачное хранилище изображений номеров
+type WebDAVConfig struct {
+	Endpoint  string `yaml:"endpoint" env:"WEBDAV_ENDPOINT"`
+	Username  string `yaml:"username" env:"WEBDAV_USERNAME"`
+	Password  string `yaml:"password" env:"WEBDAV_PASSWORD"`
+	PublicURL string `yaml:"public_url" env:"WEBDAV_URL"` // Публичная ссылка на папку с изображениями
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+func (c ServerConfig) ReadHeaderTimeout() time.Duration { return seconds(c.ReadHeaderTimeoutSeconds) }
+func (c ServerConfig) ReadTimeout() time.Duration       { return seconds(c.ReadTimeoutSeconds) }
+func (c ServerConfig) WriteTimeout() time.Duration      { return seconds(c.WriteTimeoutSeconds) }
+func (c ServerConfig) IdleTimeout() time.Duration       { return seconds(c.IdleTimeoutSeconds) }
+func (c ServerConfig) ShutdownTimeout() time.Duration   { return seconds(c.ShutdownTimeoutSeconds) }
+func (c ServerConfig) ReadinessTimeout() time.Duration  { return seconds(c.ReadinessTimeoutSeconds) }
+
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`   // debug, info, warn или error
+	Format string `yaml:"format" env:"LOG_FORMAT"` // json или text
+}
+
+// Hold возвращает время на оплату онлайн-бронирования
+func (c BookingConfig) Hold() time.Duration {
+	return time.Duration(c.HoldMinutes) * time.Minute
+}
+
+// ExpiryInterval возвращает интервал поиска просроченных бронирований
+func (c BookingConfig) ExpiryInterval() time.Duration {
+	return time.Duration(c.ExpiryCheckSeconds) * time.Second
+}
+
+// Interval возвращает интервал диспетчера outbox
+func (c OutboxConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// Enabled сообщает, заданы ли учётные данные хранилища
+func (c WebDAVConfig) Enabled() bool {
+	return c.Username != ""
+}
+
+// Default возвращает настройки по умолчанию для локальной разработки
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:                     ":8080",
+			ReadHeaderTimeoutSeconds: 5,
+			ReadTimeoutSeconds:       60,
+			WriteTimeoutSeconds:      60,
+			IdleTimeoutSeconds:       120,
+			ShutdownTimeoutSeconds:   15,
+			ReadinessTimeoutSeconds:  3,
+		},
+		URLs:     URLConfig{Backend: "http://localhost:8080", Frontend: "http://localhost:3000"},
+		Database: DatabaseConfig{Port: "5432", SSLMode: "disable"},
+		Mail:     MailConfig{Transport: "smtp", Dir: "mail"},
+		Payments: PaymentsConfig{Provider: "yookassa"},
+		Booking:  BookingConfig{HoldMinutes: 30, ExpiryCheckSeconds: 60},
+		Outbox:   OutboxConfig{IntervalSeconds: 5, MaxAttempts: 8},
+		WebDAV:   WebDAVConfig{Endpoint: "https://webdav.cloud.mail.ru"},
+		Log:      LogConfig{Level: "info", Format: "json"},
+	}
+}
+
+// Load собирает настройки: значения по умолчанию, затем YAML-файл path (или CONFIG_FILE,
+// или config.yaml, если он есть), затем переменные окружения, в том числе из .env.
+// Переменные окружения важнее файла. Все отсутствующие и неверные значения
+// возвращаются одной ошибкой *ValidationError.
+func Load(path string) (*Config, error) {
+	return load(path, (*Config).validate)
+}
+
+// LoadMigrate собирает настройки так же, как Load, но проверяет только подключение
+// к базе и журнал. Подкоманде migrate ключи JWT, почта и платежи не нужны.
+func LoadMigrate(path string) (*Config, error) {
+	return load(path, (*Config).validateMigrate)
+}
+
+func load(path string, validate func(*Config) []string) (*Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path == "" {
+		if _, err := os.Stat(DefaultFile); err == nil {
+			path = DefaultFile
+		}
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("чтение файла настроек: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("разбор файла настроек %s: %w", path, err)
+		}
+	}
+
+	// .env не переопределяет уже заданные переменные окружения
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("чтение .env: %w", err)
+	}
+
+	var problems []string
+	applyEnv(reflect.ValueOf(&cfg).Elem(), &problems)
+
+	// Старое название переменной с опечаткой
+	if value := os.Getenv("WEVDAV_URL"); value != "" && os.Getenv("WEBDAV_URL") == "" {
+		slog.Warn("Переменная WEVDAV_URL устарела, используйте WEBDAV_URL")
+		cfg.WebDAV.PublicURL = value
+	}
+
+	if len(cfg.Server.CORSOrigins) == 0 {
+		cfg.Server.CORSOrigins = []string{cfg.URLs.Frontend}
+	}
+	if cfg.Payments.ReturnURL == "" {
+		cfg.Payments.ReturnURL = strings.TrimRight(cfg.URLs.Frontend, "/") + "/my-bookings"
+	}
+	if cfg.Mail.Username == "" {
+		cfg.Mail.Username = cfg.Mail.From
+	}
+
+	problems = append(problems, validate(&cfg)...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return &cfg, nil
+}
+
+// applyEnv заполняет поля с тегом env из непустых переменных окружения.
+// Списки задаются через запятую; ошибки разбора чисел добавляются в problems.
+func applyEnv(v reflect.Value, problems *[]string) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		tag := v.Type().Field(i).Tag.Get("env")
+		if field.Kind() == reflect.Struct {
+			applyEnv(field, problems)
+			continue
+		}
+		if tag == "" {
+			continue
+		}
+		value := strings.TrimSpace(os.Getenv(tag))
+		if value == "" {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				*problems = append(*problems, fmt.Sprintf("%s должен быть числом, получено %q", tag, value))
+				continue
+			}
+			field.SetInt(int64(n))
+		case reflect.Slice:
+			var items []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			field.Set(reflect.ValueOf(items))
+		}
+	}
+}
+
+// checks собирает сообщения о неверных настройках
+type checks struct {
+	problems []string
+}
+
+func (ch *checks) require(value, env, key string) {
+	if strings.TrimSpace(value) == "" {
+		ch.problems = append(ch.problems, fmt.Sprintf("не задан %s (%s)", env, key))
+	}
+}
+
+func (ch *checks) positive(value int, env, key string) {
+	if value <= 0 {
+		ch.problems = append(ch.problems, fmt.Sprintf("%s (%s) должен быть положительным числом, получено %d", env, key, value))
+	}
+}
+
+func (ch *checks) oneOf(value, env, key string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	ch.problems = append(ch.problems, fmt.Sprintf("%s (%s): неизвестное значение %q, допустимо: %s", env, key, value, strings.Join(allowed, ", ")))
+}
+
+// validate проверяет обязательные значения с учётом выбранных транспорта почты и платёжного провайдера
+func (c *Config) validate() []string {
+	var ch checks
+	ch.require(c.Server.Addr, "HTTP_ADDR", "server.addr")
+	ch.positive(c.Server.ReadHeaderTimeoutSeconds, "HTTP_READ_HEADER_TIMEOUT_SECONDS", "server.read_header_timeout_seconds")
+	ch.positive(c.Server.ReadTimeoutSeconds, "HTTP_READ_TIMEOUT_SECONDS", "server.read_timeout_seconds")
+	ch.positive(c.Server.WriteTimeoutSeconds, "HTTP_WRITE_TIMEOUT_SECONDS", "server.write_timeout_seconds")
+	ch.positive(c.Server.IdleTimeoutSeconds, "HTTP_IDLE_TIMEOUT_SECONDS", "server.idle_timeout_seconds")
+	ch.positive(c.Server.ShutdownTimeoutSeconds, "HTTP_SHUTDOWN_TIMEOUT_SECONDS", "server.shutdown_timeout_seconds")
+	ch.positive(c.Server.ReadinessTimeoutSeconds, "HTTP_READINESS_TIMEOUT_SECONDS", "server.readiness_timeout_seconds")
+	ch.require(c.URLs.Backend, "URL_BACKEND", "urls.backend")
+	ch.require(c.URLs.Frontend, "URL_FRONTEND", "urls.frontend")
+
+	c.checkDatabase(&ch)
+
+	if c.JWT.Keys == "" && c.JWT.Key == "" {
+		ch.problems = append(ch.problems, "не задан JWT_KEYS или JWT_KEY (jwt.keys или jwt.key)")
+	}
+
+	ch.oneOf(c.Mail.Transport, "MAIL_TRANSPORT", "mail.transport", "smtp", "file", "memory")
+	if c.Mail.Transport == "smtp" {
+		ch.require(c.Mail.From, "SMTP_EMAIL", "mail.from")
+		ch.require(c.Mail.Host, "SMTP_HOST", "mail.host")
+		ch.require(c.Mail.Port, "SMTP_PORT", "mail.port")
+	}
+	if c.Mail.TLS != "" {
+		ch.oneOf(c.Mail.TLS, "SMTP_TLS", "mail.tls", "starttls", "tls", "none")
+	}
+
+	ch.oneOf(c.Payments.Provider, "PAYMENT_PROVIDER", "payments.provider", "yookassa", "fake")
+	if c.Payments.Provider == "yookassa" {
+		ch.require(c.Payments.ShopID, "YOKASSA_SHOP_ID", "payments.shop_id")
+		ch.require(c.Payments.SecretKey, "YOKASSA_SECRET_KEY", "payments.secret_key")
+	}
+
+	ch.positive(c.Booking.HoldMinutes, "BOOKING_HOLD_MINUTES", "booking.hold_minutes")
+	ch.positive(c.Booking.ExpiryCheckSeconds, "BOOKING_EXPIRY_CHECK_SECONDS", "booking.expiry_check_seconds")
+	ch.positive(c.Outbox.IntervalSeconds, "OUTBOX_INTERVAL_SECONDS", "outbox.interval_seconds")
+	ch.positive(c.Outbox.MaxAttempts, "OUTBOX_MAX_ATTEMPTS", "outbox.max_attempts")
+
+	c.checkLog(&ch)
+
+	if c.WebDAV.Enabled() {
+		ch.require(c.WebDAV.Endpoint, "WEBDAV_ENDPOINT", "webdav.endpoint")
+		ch.require(c.WebDAV.Password, "WEBDAV_PASSWORD", "webdav.password")
+		ch.require(c.WebDAV.PublicURL, "WEBDAV_URL", "webdav.public_url")
+	}
+	return ch.problems
+}
+
+// validateMigrate проверяет только то, что нужно подкоманде migrate: базу и журнал
+func (c *Config) validateMigrate() []string {
+	var ch checks
+	c.checkDatabase(&ch)
+	c.checkLog(&ch)
+	return ch.problems
+}
+
+func (c *Config) checkDatabase(ch *checks) {
+	ch.require(c.Database.Host, "DB_HOST", "database.host")
+	ch.require(c.Database.Port, "DB_PORT", "database.port")
+	ch.require(c.Database.User, "DB_USER", "database.user")
+	ch.require(c.Database.Name, "DB_NAME", "database.name")
+}
+
+func (c *Config) checkLog(ch *checks) {
+	ch.oneOf(c.Log.Level, "LOG_LEVEL", "log.level", "debug", "info", "warn", "error")
+	ch.oneOf(c.Log.Format, "LOG_FORMAT", "log.format", "json", "text")
+}
+
+// ValidationError перечисляет все отсутствующие и неверные настройки сразу
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "неверная конфигурация:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
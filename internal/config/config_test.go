@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// clearEnv убирает переменные, которые могли остаться в окружении разработчика
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"CONFIG_FILE", "DB_HOST", "DB_PORT", "DB_USER", "DB_NAME", "JWT_KEYS", "JWT_KEY",
+		"MAIL_TRANSPORT", "SMTP_EMAIL", "SMTP_HOST", "SMTP_PORT", "PAYMENT_PROVIDER",
+		"YOKASSA_SHOP_ID", "YOKASSA_SECRET_KEY", "WEBDAV_USERNAME", "WEBDAV_PASSWORD",
+		"WEBDAV_URL", "WEVDAV_URL", "BOOKING_HOLD_MINUTES", "CORS_ORIGINS", "URL_FRONTEND",
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestLoadReportsAllMissingValues(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("BOOKING_HOLD_MINUTES", "полчаса")
+
+	_, err := Load("")
+	var validation *ValidationError
+	if !errors.As(err, &validation) {
+		t.Fatalf("ожидалась ValidationError, получено %v", err)
+	}
+	for _, name := range []string{"DB_HOST", "DB_USER", "DB_NAME", "JWT_KEY", "SMTP_EMAIL", "SMTP_HOST", "SMTP_PORT", "YOKASSA_SHOP_ID", "YOKASSA_SECRET_KEY", "BOOKING_HOLD_MINUTES"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("в ошибке нет %s:\n%v", name, err)
+		}
+	}
+}
+
+func TestLoadMergesFileAndEnv(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := `
+database:
+  host: db.internal
+  user: hotel
+  name: hotel
+jwt:
+  key: secret
+mail:
+  transport: file
+payments:
+  provider: fake
+urls:
+  frontend: https://hotel.example
+webdav:
+  username: cloud
+  password: pass
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DB_HOST", "db.override")
+	t.Setenv("WEVDAV_URL", "https://cloud.example/public")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Host != "db.override" {
+		t.Errorf("DB_HOST из окружения не переопределил файл: %q", cfg.Database.Host)
+	}
+	if cfg.Database.Port != "5432" || cfg.Booking.HoldMinutes != 30 {
+		t.Errorf("не применены значения по умолчанию: %+v %+v", cfg.Database, cfg.Booking)
+	}
+	if len(cfg.Server.CORSOrigins) != 1 || cfg.Server.CORSOrigins[0] != "https://hotel.example" {
+		t.Errorf("CORS по умолчанию = %v, ожидался адрес фронтенда", cfg.Server.CORSOrigins)
+	}
+	if cfg.Payments.ReturnURL != "https://hotel.example/my-bookings" {
+		t.Errorf("ReturnURL = %q", cfg.Payments.ReturnURL)
+	}
+	if cfg.WebDAV.PublicURL != "https://cloud.example/public" {
+		t.Errorf("WEVDAV_URL не принят как WEBDAV_URL: %q", cfg.WebDAV.PublicURL)
+	}
+}
+
+func TestLoadMigrateChecksOnlyDatabase(t *testing.T) {
+	clearEnv(t)
+
+	_, err := LoadMigrate("")
+	var validation *ValidationError
+	if !errors.As(err, &validation) {
+		t.Fatalf("ожидалась ValidationError, получено %v", err)
+	}
+	if !strings.Contains(err.Error(), "DB_HOST") {
+		t.Errorf("в ошибке нет DB_HOST:\n%v", err)
+	}
+	for _, name := range []string{"JWT_KEY", "SMTP_EMAIL", "YOKASSA_SHOP_ID"} {
+		if strings.Contains(err.Error(), name) {
+			t.Errorf("migrate требует %s:\n%v", name, err)
+		}
+	}
+
+	t.Setenv("DB_HOST", "localhost")
+	t.Setenv("DB_USER", "hotel")
+	t.Setenv("DB_NAME", "hotel")
+	if _, err := LoadMigrate(""); err != nil {
+		t.Fatalf("настроек базы достаточно для migrate: %v", err)
+	}
+}
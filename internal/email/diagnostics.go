@@ -0,0 +1,129 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Diagnostics — результат проверки почтового транспорта
+type Diagnostics struct {
+	Transport string           `json:"transport"`          // smtp, file или memory
+	Server    string           `json:"server,omitempty"`   // Адрес SMTP-сервера
+	TLSMode   string           `json:"tls_mode,omitempty"` // starttls, tls или none
+	From      string           `json:"from,omitempty"`     // Адрес отправителя
+	Steps     []DiagnosticStep `json:"steps"`              // Шаги подключения и отправки по порядку
+	OK        bool             `json:"ok"`                 // Все шаги выполнены успешно
+	Error     string           `json:"error,omitempty"`    // Ошибка первого неудачного шага
+}
+
+// DiagnosticStep — один шаг проверки: подключение, EHLO, STARTTLS, авторизация или отправка
+type DiagnosticStep struct {
+	Name       string `json:"name"`
+	OK         bool   `json:"ok"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// add записывает шаг; на nil ничего не делает, чтобы обычная отправка не собирала диагностику
+func (d *Diagnostics) add(name string, started time.Time, detail string, err error) {
+	if d == nil {
+		return
+	}
+	step := DiagnosticStep{Name: name, OK: err == nil, Detail: detail, DurationMs: time.Since(started).Milliseconds()}
+	if err != nil {
+		step.Error = err.Error()
+		if d.Error == "" {
+			d.Error = step.Error
+		}
+	}
+	d.Steps = append(d.Steps, step)
+}
+
+// Diagnose проверяет транспорт m и, если to не пустой, отправляет на него тестовое письмо.
+// Для SMTP проверка идёт на отдельном соединении и показывает каждый шаг рукопожатия.
+func Diagnose(ctx context.Context, m Mailer, to string, msg Message) Diagnostics {
+	var d Diagnostics
+	switch mailer := m.(type) {
+	case *SMTPMailer:
+		d = mailer.diagnose(ctx, to, msg)
+	case *FileMailer:
+		d = Diagnostics{Transport: "file", Server: mailer.Dir, From: mailer.From.String()}
+		d.send(ctx, m, to, msg)
+	case *MemoryMailer:
+		d = Diagnostics{Transport: "memory"}
+		d.send(ctx, m, to, msg)
+	case nil:
+		d = Diagnostics{Transport: "none", Error: "почтовый транспорт не настроен"}
+	default:
+		d = Diagnostics{Transport: fmt.Sprintf("%T", m)}
+		d.send(ctx, m, to, msg)
+	}
+	if d.Steps == nil {
+		d.Steps = []DiagnosticStep{}
+	}
+	d.OK = d.Error == ""
+	return d
+}
+
+func (d *Diagnostics) send(ctx context.Context, m Mailer, to string, msg Message) {
+	if to == "" {
+		return
+	}
+	started := time.Now()
+	d.add("send", started, to, m.Send(ctx, to, msg))
+}
+
+func (m *SMTPMailer) diagnose(ctx context.Context, to string, msg Message) Diagnostics {
+	d := Diagnostics{
+		Transport: "smtp",
+		Server:    net.JoinHostPort(m.cfg.Host, m.cfg.Port),
+		TLSMode:   m.cfg.TLSMode,
+		From:      m.cfg.From.String(),
+	}
+
+	client, _, err := m.dialTraced(ctx, &d)
+	if err != nil {
+		return d
+	}
+	defer client.Close()
+
+	if to != "" {
+		started := time.Now()
+		data, err := buildMessage(m.cfg.From, to, msg, time.Now())
+		if err == nil {
+			err = m.deliver(client, to, data)
+		}
+		d.add("send", started, to, err)
+	}
+
+	started := time.Now()
+	d.add("quit", started, "", client.Quit())
+	return d
+}
+
+// extensions перечисляет расширения сервера, важные для отправки
+func extensions(client *smtp.Client) string {
+	var supported []string
+	for _, ext := range []string{"STARTTLS", "AUTH", "SIZE", "8BITMIME", "PIPELINING"} {
+		if ok, params := client.Extension(ext); ok {
+			if params != "" {
+				ext += " " + params
+			}
+			supported = append(supported, ext)
+		}
+	}
+	if len(supported) == 0 {
+		return "расширения не объявлены"
+	}
+	return strings.Join(supported, ", ")
+}
+
+func tlsDetail(state tls.ConnectionState) string {
+	return fmt.Sprintf("%s, %s", tls.VersionName(state.Version), tls.CipherSuiteName(state.CipherSuite))
+}
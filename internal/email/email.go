@@ -1,28 +1,144 @@
 package email
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"net/smtp"
-	"os"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
 )
 
-func SendEmail(to, subject, body string) error {
-	smtpHost := os.Getenv("SMTP_HOST")
-	smtpPort := os.Getenv("SMTP_PORT")
-	fromEmail := os.Getenv("SMTP_EMAIL")
-	password := os.Getenv("SMTP_PASSWORD")
+// Mailer доставляет готовые письма. Реализации: SMTPMailer для рабочей почты,
+// FileMailer и MemoryMailer для разработки и тестов.
+type Mailer interface {
+	Send(ctx context.Context, to string, msg Message) error
+	// Close освобождает соединения, которые держит транспорт
+	Close() error
+}
+
+var (
+	mailerMu sync.RWMutex
+	mailer   Mailer
+)
+
+// SetMailer задаёт транспорт, через который SendEmail отправляет письма
+func SetMailer(m Mailer) {
+	mailerMu.Lock()
+	defer mailerMu.Unlock()
+	mailer = m
+}
+
+// GetMailer возвращает текущий транспорт писем
+func GetMailer() Mailer {
+	mailerMu.RLock()
+	defer mailerMu.RUnlock()
+	return mailer
+}
 
-	message := fmt.Sprintf(
-		"From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%s",
-		fromEmail, to, subject, body,
-	)
+// pinger — транспорт, который умеет проверять соединение с почтовым сервером
+type pinger interface {
+	Ping(ctx context.Context) error
+}
 
-	auth := smtp.PlainAuth("", fromEmail, password, smtpHost)
+// Ping проверяет доступность почтового сервера транспорта m.
+// Транспорты без сервера (file, memory) считаются доступными всегда.
+func Ping(ctx context.Context, m Mailer) error {
+	if m == nil {
+		return fmt.Errorf("почтовый транспорт не настроен")
+	}
+	if p, ok := m.(pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
 
-	err := smtp.SendMail(smtpHost+":"+smtpPort, auth, fromEmail, []string{to}, []byte(message))
+// SendEmail отправляет письмо через транспорт, заданный SetMailer
+func SendEmail(to string, msg Message) error {
+	m := GetMailer()
+	if m == nil {
+		return fmt.Errorf("почтовый транспорт не настроен")
+	}
+	return m.Send(context.Background(), to, msg)
+}
+
+// buildMessage собирает письмо в формате RFC 5322 с заголовками Date и Message-ID.
+// Заголовки с не-ASCII символами кодируются по RFC 2047. Тело — multipart/alternative:
+// сначала текстовая версия, затем HTML; почтовый клиент показывает последнюю, которую умеет отобразить.
+func buildMessage(from mail.Address, to string, msg Message, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", msg.Text},
+		{"text/html; charset=UTF-8", msg.HTML},
+	}
+	for _, part := range parts {
+		if part.content == "" {
+			continue
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	messageID, err := newMessageID(from.Address)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	var message bytes.Buffer
+	writeHeader(&message, "From", from.String())
+	writeHeader(&message, "To", (&mail.Address{Address: to}).String())
+	writeHeader(&message, "Subject", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	writeHeader(&message, "Date", now.Format(time.RFC1123Z))
+	writeHeader(&message, "Message-ID", messageID)
+	writeHeader(&message, "MIME-Version", "1.0")
+	writeHeader(&message, "Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", writer.Boundary()))
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
+
+// writeHeader пишет заголовок, отбрасывая переводы строк, чтобы значение не могло добавить свои заголовки
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	fmt.Fprintf(buf, "%s: %s\r\n", name, value)
+}
+
+// newMessageID создаёт уникальный Message-ID в домене отправителя
+func newMessageID(from string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 && i < len(from)-1 {
+		domain = from[i+1:]
+	}
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b), domain), nil
 }
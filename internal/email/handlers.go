@@ -1,36 +1,52 @@
 package email
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// @Summary Отправка тестового письма
-// @Description Отправляет тестовое письмо на указанную почту.
-// @Tags email
+type MailTestInput struct {
+	To string `json:"to" binding:"omitempty,email"` // Адрес для тестового письма; без него только проверяется подключение
+}
+
+// @Security BearerAuth
+// MailTestHandler godoc
+// @Summary Диагностика почты
+// @Description Проверяет почтовый транспорт: для SMTP — подключение, EHLO, STARTTLS и авторизацию на отдельном соединении. Если указан адрес, отправляет на него тестовое письмо в обход очереди. Доступно только администраторам.
+// @Tags admin
 // @Accept json
 // @Produce json
-// @Param to query string true "Email получателя"
-// @Success 200 {object} response.SuccessResponse "Тестовое письмо успешно отправлено"
-// @Failure 400 {object} response.ErrorResponse "Не указан email получателя"
-// @Failure 500 {object} response.ErrorResponse "Ошибка отправки письма"
-// @Router /email/test [get]
-func SendTestEmailHandler(c *gin.Context) {
-	to := c.Query("to")
-	if to == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Не указан email получателя"})
-		return
+// @Param input body MailTestInput false "Адрес для тестового письма"
+// @Success 200 {object} response.MailDiagnosticsResponse "Все шаги выполнены успешно"
+// @Failure 400 {object} response.ErrorResponse "Неверный адрес"
+// @Failure 403 {object} response.ErrorResponse "Недостаточно прав"
+// @Failure 502 {object} response.MailDiagnosticsResponse "Один из шагов завершился ошибкой"
+// @Router /admin/mail/test [post]
+func MailTestHandler(c *gin.Context) {
+	var input MailTestInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 	}
 
-	subject := "Тестовое письмо"
-	body := "Проверка работы отправки сообщений"
-
-	if err := SendEmail(to, subject, body); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка отправки письма", "details": err.Error()})
-		return
+	msg := Message{
+		Subject: "Тестовое письмо",
+		Text:    "Проверка работы отправки сообщений",
+		HTML:    "<p>Проверка работы отправки сообщений</p>",
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Тестовое письмо успешно отправлено"})
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
 
+	diagnostics := Diagnose(ctx, GetMailer(), input.To, msg)
+	if !diagnostics.OK {
+		c.JSON(http.StatusBadGateway, diagnostics)
+		return
+	}
+	c.JSON(http.StatusOK, diagnostics)
 }
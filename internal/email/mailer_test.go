@@ -0,0 +1,319 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testMessage = Message{
+	Subject: "Подтверждение регистрации",
+	HTML:    "<p>Здравствуйте!</p>",
+	Text:    "Здравствуйте!",
+}
+
+func TestBuildMessageHeaders(t *testing.T) {
+	from := mail.Address{Name: "Отель", Address: "noreply@hotel.test"}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	data, err := buildMessage(from, "guest@example.com", testMessage, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("письмо не разбирается как RFC 5322: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != testMessage.Subject {
+		t.Errorf("Subject = %q (%v), ожидался %q", subject, err, testMessage.Subject)
+	}
+	if raw := parsed.Header.Get("Subject"); !strings.HasPrefix(raw, "=?UTF-8?") {
+		t.Errorf("Subject не закодирован по RFC 2047: %q", raw)
+	}
+	sender, err := parsed.Header.AddressList("From")
+	if err != nil || len(sender) != 1 || sender[0].Name != from.Name || sender[0].Address != from.Address {
+		t.Errorf("From = %v (%v)", sender, err)
+	}
+	if date, err := parsed.Header.Date(); err != nil || !date.Equal(now) {
+		t.Errorf("Date = %v (%v)", date, err)
+	}
+	if id := parsed.Header.Get("Message-ID"); !strings.HasPrefix(id, "<") || !strings.HasSuffix(id, "@hotel.test>") {
+		t.Errorf("Message-ID = %q", id)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v)", mediaType, err)
+	}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var types []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(part)
+		types = append(types, part.Header.Get("Content-Type"))
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "text/plain") && string(body) != testMessage.Text {
+			t.Errorf("текстовая версия = %q", body)
+		}
+	}
+	if len(types) != 2 || !strings.HasPrefix(types[0], "text/plain") || !strings.HasPrefix(types[1], "text/html") {
+		t.Errorf("части письма: %v, ожидались text/plain и text/html", types)
+	}
+}
+
+func TestBuildMessageRejectsHeaderInjection(t *testing.T) {
+	msg := testMessage
+	msg.Subject = "Тема\r\nBcc: victim@example.com"
+	data, err := buildMessage(mail.Address{Address: "noreply@hotel.test"}, "guest@example.com", msg, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header.Get("Bcc") != "" {
+		t.Error("перевод строки в теме добавил заголовок Bcc")
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewFileMailer(dir, mail.Address{Address: "noreply@hotel.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := m.Send(context.Background(), "guest@example.com", testMessage); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 2 {
+		t.Fatalf("сохранено писем: %d, ожидалось 2", len(files))
+	}
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := mail.ReadMessage(f); err != nil {
+		t.Errorf("сохранённое письмо не разбирается: %v", err)
+	}
+}
+
+// fakeSMTPServer — минимальный SMTP-сервер без TLS и авторизации, считает соединения и письма.
+// Пока silent установлен, сервер читает команды, но не отвечает, как зависший.
+type fakeSMTPServer struct {
+	listener    net.Listener
+	mu          sync.Mutex
+	connections int
+	messages    []string
+	silent      bool
+}
+
+func startFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTPServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.connections++
+			s.mu.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		s.mu.Lock()
+		silent := s.silent
+		s.mu.Unlock()
+		if !silent {
+			io.WriteString(conn, line+"\r\n")
+		}
+	}
+
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.Fields(line + " x")[0])
+		switch command {
+		case "EHLO", "HELO":
+			reply("250 fake")
+		case "MAIL", "RCPT", "NOOP", "RSET":
+			reply("250 OK")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPMailerReusesConnection(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+
+	m, err := NewSMTPMailer(SMTPConfig{
+		Host:    host,
+		Port:    port,
+		From:    mail.Address{Address: "noreply@hotel.test"},
+		TLSMode: TLSModeNone,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := m.Send(context.Background(), "guest@example.com", testMessage); err != nil {
+			t.Fatalf("письмо %d: %v", i, err)
+		}
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.connections != 1 {
+		t.Errorf("открыто соединений: %d, ожидалось 1", server.connections)
+	}
+	if len(server.messages) != 3 {
+		t.Errorf("доставлено писем: %d, ожидалось 3", len(server.messages))
+	}
+}
+
+func TestSMTPMailerPingStopsAtDeadline(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+
+	m, err := NewSMTPMailer(SMTPConfig{Host: host, Port: port, From: mail.Address{Address: "noreply@hotel.test"}, TLSMode: TLSModeNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	ping := func() (time.Duration, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		started := time.Now()
+		err := m.Ping(ctx)
+		return time.Since(started), err
+	}
+	if _, err := ping(); err != nil {
+		t.Fatalf("проверка доступного сервера: %v", err)
+	}
+
+	// Открытое соединение зависло: NOOP не должен ждать ответа дольше срока ctx
+	server.mu.Lock()
+	server.silent = true
+	server.mu.Unlock()
+	if elapsed, err := ping(); err == nil || elapsed > time.Second {
+		t.Fatalf("проверка зависшего сервера: %v за %s", err, elapsed)
+	}
+
+	// Новое соединение к зависшему серверу тоже ограничено сроком, мьютекс не остаётся занятым
+	if elapsed, err := ping(); err == nil || elapsed > time.Second {
+		t.Fatalf("подключение к зависшему серверу: %v за %s", err, elapsed)
+	}
+
+	server.mu.Lock()
+	server.silent = false
+	server.mu.Unlock()
+	if _, err := ping(); err != nil {
+		t.Fatalf("проверка после восстановления сервера: %v", err)
+	}
+}
+
+func TestSMTPMailerRequiresStartTLS(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+
+	m, err := NewSMTPMailer(SMTPConfig{Host: host, Port: port, From: mail.Address{Address: "noreply@hotel.test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if err := m.Send(context.Background(), "guest@example.com", testMessage); err == nil {
+		t.Error("письмо ушло без STARTTLS на сервер, который его не поддерживает")
+	}
+}
+
+func TestDiagnoseSMTP(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+
+	m, err := NewSMTPMailer(SMTPConfig{Host: host, Port: port, From: mail.Address{Address: "noreply@hotel.test"}, TLSMode: TLSModeNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	d := Diagnose(context.Background(), m, "guest@example.com", testMessage)
+	if !d.OK {
+		t.Fatalf("диагностика не прошла: %+v", d)
+	}
+	var names []string
+	for _, step := range d.Steps {
+		names = append(names, step.Name)
+	}
+	if got := strings.Join(names, ","); got != "connect,greeting,ehlo,send,quit" {
+		t.Errorf("шаги: %s", got)
+	}
+
+	if d := Diagnose(context.Background(), nil, "", testMessage); d.OK {
+		t.Error("диагностика без транспорта прошла успешно")
+	}
+}
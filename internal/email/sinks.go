@@ -0,0 +1,140 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileMailer сохраняет каждое письмо в каталог Dir как .eml-файл. Для разработки без SMTP-сервера.
+type FileMailer struct {
+	Dir  string
+	From mail.Address
+
+	mu  sync.Mutex
+	seq int
+}
+
+func NewFileMailer(dir string, from mail.Address) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("каталог писем %s: %w", dir, err)
+	}
+	return &FileMailer{Dir: dir, From: from}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, to string, msg Message) error {
+	now := time.Now()
+	data, err := buildMessage(m.From, to, msg, now)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.seq++
+	name := fmt.Sprintf("%s-%04d-%s.eml", now.Format("20060102-150405.000"), m.seq, safeFileName(to))
+	m.mu.Unlock()
+
+	return os.WriteFile(filepath.Join(m.Dir, name), data, 0o644)
+}
+
+func (m *FileMailer) Close() error {
+	return nil
+}
+
+// safeFileName оставляет в адресе только символы, допустимые в имени файла
+func safeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '@', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// SentMessage — письмо, принятое MemoryMailer
+type SentMessage struct {
+	To string
+	Message
+}
+
+// MemoryMailer хранит отправленные письма в памяти. Для тестов.
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []SentMessage
+	Err      error // Если задана, Send возвращает эту ошибку и письмо не сохраняется
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, to string, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	m.messages = append(m.messages, SentMessage{To: to, Message: msg})
+	return nil
+}
+
+// Messages возвращает копию списка отправленных писем
+func (m *MemoryMailer) Messages() []SentMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]SentMessage(nil), m.messages...)
+}
+
+// Reset очищает список отправленных писем
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
+
+func (m *MemoryMailer) Close() error {
+	return nil
+}
+
+// Транспорты писем
+const (
+	TransportSMTP   = "smtp"
+	TransportFile   = "file"
+	TransportMemory = "memory"
+)
+
+// MailerConfig — выбор транспорта писем. SMTP используется для транспорта smtp,
+// Dir — для file; адрес отправителя берётся из SMTP.From.
+type MailerConfig struct {
+	Transport string // smtp (по умолчанию), file или memory
+	Dir       string // Каталог для транспорта file, по умолчанию mail
+	SMTP      SMTPConfig
+}
+
+// NewMailer создаёт транспорт писем по настройкам
+func NewMailer(cfg MailerConfig) (Mailer, error) {
+	switch cfg.Transport {
+	case "", TransportSMTP:
+		return NewSMTPMailer(cfg.SMTP)
+	case TransportFile:
+		dir := cfg.Dir
+		if dir == "" {
+			dir = "mail"
+		}
+		from := cfg.SMTP.From
+		if from.Address == "" {
+			from.Address = "noreply@localhost"
+		}
+		return NewFileMailer(dir, from)
+	case TransportMemory:
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("неизвестный транспорт писем %q", cfg.Transport)
+	}
+}
@@ -0,0 +1,259 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"sync"
+	"time"
+)
+
+// Режимы шифрования SMTP
+const (
+	TLSModeStartTLS = "starttls" // Обычное соединение, затем обязательный STARTTLS (порт 587)
+	TLSModeImplicit = "tls"      // TLS с первого байта (порт 465)
+	TLSModeNone     = "none"     // Без шифрования, только для локальных серверов
+)
+
+const defaultIdleTimeout = 30 * time.Second
+
+// SMTPConfig — параметры подключения к SMTP-серверу
+type SMTPConfig struct {
+	Host        string
+	Port        string
+	Username    string
+	Password    string
+	From        mail.Address
+	TLSMode     string        // starttls, tls или none
+	IdleTimeout time.Duration // Сколько держать открытым неиспользуемое соединение
+}
+
+// SMTPMailer отправляет письма через SMTP. Соединение переиспользуется между письмами
+// и закрывается после IdleTimeout простоя; письма отправляются по одному.
+type SMTPMailer struct {
+	cfg SMTPConfig
+
+	mu       sync.Mutex
+	client   *smtp.Client
+	conn     net.Conn // Соединение client, на нём выставляется срок каждой операции
+	lastUsed time.Time
+	timer    *time.Timer
+}
+
+func NewSMTPMailer(cfg SMTPConfig) (*SMTPMailer, error) {
+	if cfg.Host == "" || cfg.Port == "" {
+		return nil, errors.New("не задан SMTP-сервер")
+	}
+	if cfg.From.Address == "" {
+		return nil, errors.New("не задан адрес отправителя")
+	}
+	switch cfg.TLSMode {
+	case "":
+		cfg.TLSMode = TLSModeStartTLS
+		if cfg.Port == "465" {
+			cfg.TLSMode = TLSModeImplicit
+		}
+	case TLSModeStartTLS, TLSModeImplicit, TLSModeNone:
+	default:
+		return nil, fmt.Errorf("неизвестный режим TLS %q", cfg.TLSMode)
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = defaultIdleTimeout
+	}
+	return &SMTPMailer{cfg: cfg}, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, to string, msg Message) error {
+	data, err := buildMessage(m.cfg.From, to, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	client, err := m.connection(ctx)
+	if err != nil {
+		return err
+	}
+	if err := m.deliver(client, to, data); err != nil {
+		// После ошибки состояние сессии неизвестно, следующее письмо откроет новое соединение
+		m.closeLocked()
+		return err
+	}
+
+	m.lastUsed = time.Now()
+	m.scheduleIdleClose()
+	return nil
+}
+
+// Ping открывает соединение или проверяет открытое командой NOOP.
+// Время простоя не продлевается: соединение, открытое проверкой, закроется через IdleTimeout.
+func (m *SMTPMailer) Ping(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.connection(ctx); err != nil {
+		return err
+	}
+	m.scheduleIdleClose()
+	return nil
+}
+
+func (m *SMTPMailer) deliver(client *smtp.Client, to string, data []byte) error {
+	if err := client.Mail(m.cfg.From.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// connection возвращает открытое соединение или открывает новое.
+// Соединение, которое сервер успел закрыть, обнаруживается командой NOOP.
+// Обмен по соединению ограничивается сроком ctx, чтобы зависший сервер не держал m.mu.
+func (m *SMTPMailer) connection(ctx context.Context) (*smtp.Client, error) {
+	if m.client != nil {
+		setDeadline(ctx, m.conn)
+		if err := m.client.Noop(); err == nil {
+			return m.client, nil
+		}
+		m.closeLocked()
+	}
+
+	client, conn, err := m.dialTraced(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	m.client, m.conn = client, conn
+	return client, nil
+}
+
+// setDeadline выставляет срок операций с conn по ctx; без срока в ctx ограничение снимается
+func setDeadline(ctx context.Context, conn net.Conn) {
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+}
+
+// dialTraced открывает соединение, выполняет STARTTLS и авторизацию.
+// Все шаги ограничены сроком ctx. Если trace не nil, в него записывается результат каждого шага.
+func (m *SMTPMailer) dialTraced(ctx context.Context, trace *Diagnostics) (*smtp.Client, net.Conn, error) {
+	addr := net.JoinHostPort(m.cfg.Host, m.cfg.Port)
+	tlsConfig := &tls.Config{ServerName: m.cfg.Host, MinVersion: tls.VersionTLS12}
+
+	started := time.Now()
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if m.cfg.TLSMode == TLSModeImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		err = fmt.Errorf("подключение к %s: %w", addr, err)
+		trace.add("connect", started, "", err)
+		return nil, nil, err
+	}
+	setDeadline(ctx, conn)
+	detail := addr
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		detail += ", " + tlsDetail(tlsConn.ConnectionState())
+	}
+	trace.add("connect", started, detail, nil)
+
+	started = time.Now()
+	client, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		trace.add("greeting", started, "", err)
+		return nil, nil, err
+	}
+	trace.add("greeting", started, "", nil)
+
+	started = time.Now()
+	if err := client.Hello("localhost"); err != nil {
+		client.Close()
+		trace.add("ehlo", started, "", err)
+		return nil, nil, err
+	}
+	trace.add("ehlo", started, extensions(client), nil)
+
+	if m.cfg.TLSMode == TLSModeStartTLS {
+		started = time.Now()
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			err := fmt.Errorf("сервер %s не поддерживает STARTTLS", addr)
+			trace.add("starttls", started, "", err)
+			return nil, nil, err
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			trace.add("starttls", started, "", err)
+			return nil, nil, err
+		}
+		state, _ := client.TLSConnectionState()
+		trace.add("starttls", started, tlsDetail(state), nil)
+	}
+
+	if m.cfg.Username != "" {
+		if ok, mechanisms := client.Extension("AUTH"); ok {
+			started = time.Now()
+			auth := smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+			if err := client.Auth(auth); err != nil {
+				client.Close()
+				trace.add("auth", started, "PLAIN от "+m.cfg.Username, err)
+				return nil, nil, err
+			}
+			trace.add("auth", started, "PLAIN от "+m.cfg.Username+", сервер поддерживает: "+mechanisms, nil)
+		}
+	}
+	return client, conn, nil
+}
+
+// scheduleIdleClose закрывает соединение, если за IdleTimeout не было новых писем
+func (m *SMTPMailer) scheduleIdleClose() {
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+	m.timer = time.AfterFunc(m.cfg.IdleTimeout, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.client != nil && time.Since(m.lastUsed) >= m.cfg.IdleTimeout {
+			m.closeLocked()
+		}
+	})
+}
+
+func (m *SMTPMailer) closeLocked() {
+	if m.client == nil {
+		return
+	}
+	if err := m.client.Quit(); err != nil {
+		m.client.Close()
+	}
+	m.client, m.conn = nil, nil
+}
+
+func (m *SMTPMailer) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+	m.closeLocked()
+	return nil
+}
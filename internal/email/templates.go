@@ -0,0 +1,156 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Языки писем. Язык пользователя без своих шаблонов заменяется DefaultLanguage.
+const (
+	LanguageRU      = "ru"
+	LanguageEN      = "en"
+	DefaultLanguage = LanguageRU
+)
+
+// Шаблоны писем: для каждого есть templates/<язык>/<имя>.html и .txt,
+// общий для языка подвал лежит в templates/<язык>/common.html и .txt
+const (
+	TemplateVerification   = "verification"
+	TemplatePasswordReset  = "password_reset"
+	TemplateBookingCreated = "booking_created"
+	TemplateBookingExpired = "booking_expired"
+)
+
+var (
+	languages = []string{LanguageRU, LanguageEN}
+	templates = []string{TemplateVerification, TemplatePasswordReset, TemplateBookingCreated, TemplateBookingExpired}
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// Message — готовое письмо: тема, HTML-версия и текстовая альтернатива
+type Message struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+type templateSet struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// sets — разобранные шаблоны по ключу "<язык>/<имя>"
+var sets = mustParseTemplates()
+
+// funcs возвращает функции шаблонов, форматирующие даты и суммы по правилам языка
+func funcs(lang string) map[string]interface{} {
+	dateLayout := "02.01.2006"
+	if lang == LanguageEN {
+		dateLayout = "Jan 2, 2006"
+	}
+	return map[string]interface{}{
+		"year": func() int { return time.Now().Year() },
+		"date": func(t time.Time) string { return t.Format(dateLayout) },
+		"money": func(amount float64) string {
+			if lang == LanguageEN {
+				return fmt.Sprintf("%.2f RUB", amount)
+			}
+			return strings.Replace(fmt.Sprintf("%.2f ₽", amount), ".", ",", 1)
+		},
+	}
+}
+
+func mustParseTemplates() map[string]templateSet {
+	result := make(map[string]templateSet)
+	for _, lang := range languages {
+		for _, name := range templates {
+			html := htmltemplate.Must(htmltemplate.New("layout.html").Funcs(funcs(lang)).
+				ParseFS(templateFiles, "templates/layout.html", "templates/"+lang+"/common.html", "templates/"+lang+"/"+name+".html"))
+			text := texttemplate.Must(texttemplate.New("layout.txt").Funcs(funcs(lang)).
+				ParseFS(templateFiles, "templates/layout.txt", "templates/"+lang+"/common.txt", "templates/"+lang+"/"+name+".txt"))
+			result[lang+"/"+name] = templateSet{html: html, text: text}
+		}
+	}
+	return result
+}
+
+// Language приводит язык пользователя или заголовок Accept-Language
+// к языку, для которого есть шаблоны. Учитывается только первый язык из списка.
+func Language(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_,;"); i > 0 {
+		lang = lang[:i]
+	}
+	for _, l := range languages {
+		if l == lang {
+			return l
+		}
+	}
+	return DefaultLanguage
+}
+
+// Render собирает письмо name на языке lang из данных data.
+// Тема берётся из блока "subject" текстового шаблона.
+func Render(name, lang string, data interface{}) (Message, error) {
+	set, ok := sets[Language(lang)+"/"+name]
+	if !ok {
+		return Message{}, fmt.Errorf("шаблон письма %q не найден", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := set.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("шаблон %q: %w", name, err)
+	}
+	if err := set.text.Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("шаблон %q: %w", name, err)
+	}
+	if err := set.html.Execute(&html, data); err != nil {
+		return Message{}, fmt.Errorf("шаблон %q: %w", name, err)
+	}
+
+	return Message{
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    html.String(),
+		Text:    text.String(),
+	}, nil
+}
+
+// VerificationData — данные письма с подтверждением почты
+type VerificationData struct {
+	Name string
+	Link string // Ссылка подтверждения
+}
+
+// PasswordResetData — данные письма со ссылкой на сброс пароля
+type PasswordResetData struct {
+	Name string
+	Link string // Ссылка сброса пароля
+}
+
+// BookingCreatedData — данные письма о созданном бронировании
+type BookingCreatedData struct {
+	Name        string
+	BookingID   uint
+	RoomID      uint
+	StartDate   time.Time
+	EndDate     time.Time
+	Total       float64
+	PaymentURL  string
+	BookingsURL string // Список бронирований гостя на сайте
+	HoldMinutes int    // Сколько минут бронирование ждёт оплаты
+}
+
+// BookingExpiredData — данные письма о бронировании, снятом из-за неоплаты
+type BookingExpiredData struct {
+	Name      string
+	BookingID uint
+	StartDate time.Time
+	EndDate   time.Time
+}
@@ -0,0 +1,69 @@
+package email
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLanguage(t *testing.T) {
+	cases := map[string]string{
+		"":                   DefaultLanguage,
+		"ru":                 LanguageRU,
+		"EN":                 LanguageEN,
+		"en-US,en;q=0.9":     LanguageEN,
+		"en,ru;q=0.8":        LanguageEN,
+		"fr-FR":              DefaultLanguage,
+		"de;q=1.0, en;q=0.5": DefaultLanguage,
+		" ru-RU ":            LanguageRU,
+	}
+	for input, want := range cases {
+		if got := Language(input); got != want {
+			t.Errorf("Language(%q) = %q, ожидался %q", input, got, want)
+		}
+	}
+}
+
+func TestRenderAllTemplates(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	data := map[string]interface{}{
+		TemplateVerification:  VerificationData{Name: "Иван", Link: "https://example.com/verify?token=a&b"},
+		TemplatePasswordReset: PasswordResetData{Name: "Иван", Link: "https://example.com/reset?token=x"},
+		TemplateBookingCreated: BookingCreatedData{
+			Name: "Иван", BookingID: 7, RoomID: 3, StartDate: start, EndDate: start.AddDate(0, 0, 2),
+			Total: 1234.5, PaymentURL: "https://pay.example.com/1", BookingsURL: "https://example.com/my", HoldMinutes: 30,
+		},
+		TemplateBookingExpired: BookingExpiredData{Name: "Иван", BookingID: 7, StartDate: start, EndDate: start.AddDate(0, 0, 2)},
+	}
+
+	for _, lang := range languages {
+		for _, name := range templates {
+			msg, err := Render(name, lang, data[name])
+			if err != nil {
+				t.Fatalf("%s/%s: %v", lang, name, err)
+			}
+			if msg.Subject == "" || msg.HTML == "" || msg.Text == "" {
+				t.Errorf("%s/%s: пустая тема или версия письма: %+v", lang, name, msg)
+			}
+			if strings.Contains(msg.Subject, "\n") {
+				t.Errorf("%s/%s: тема содержит перевод строки: %q", lang, name, msg.Subject)
+			}
+			if !strings.Contains(msg.Text, "©") || !strings.Contains(msg.HTML, "©") {
+				t.Errorf("%s/%s: нет подвала из общего макета", lang, name)
+			}
+		}
+	}
+}
+
+func TestRenderEscapesHTML(t *testing.T) {
+	msg, err := Render(TemplateVerification, LanguageRU, VerificationData{Name: "<script>", Link: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(msg.HTML, "<script>") {
+		t.Error("имя пользователя не экранировано в HTML-версии")
+	}
+	if !strings.Contains(msg.Text, "<script>") {
+		t.Error("текстовая версия не должна экранировать HTML")
+	}
+}
@@ -0,0 +1,128 @@
+package health
+
+import (
+	"context"
+	"hotel-booking/internal/logging"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Check проверяет одну внешнюю зависимость; ошибка означает, что она недоступна
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker отвечает на проверки живости и готовности сервиса.
+// Готовность — это доступность всех зависимостей, добавленных через Add.
+type Checker struct {
+	timeout  time.Duration
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+// NewChecker создаёт Checker; каждая проверка готовности ограничена timeout
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add добавляет зависимость name к проверке готовности. Вызывается до запуска сервера.
+func (h *Checker) Add(name string, check Check) {
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// Drain переводит сервис в неготовое состояние перед остановкой,
+// чтобы балансировщик перестал направлять на него новые запросы
+func (h *Checker) Drain() {
+	h.draining.Store(true)
+}
+
+// Readiness — результат проверки готовности
+type Readiness struct {
+	Status string            `json:"status"` // ok, unavailable или draining
+	Checks map[string]string `json:"checks"` // ok или unavailable для каждой зависимости
+}
+
+type checkResult struct {
+	name string
+	err  error
+}
+
+// Ready выполняет все проверки параллельно и ждёт их не дольше timeout:
+// проверка, не вернувшая результат вовремя, считается недоступной.
+// Ответ содержит только ok или unavailable, причина ошибки пишется в журнал.
+func (h *Checker) Ready(ctx context.Context) Readiness {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	results := make(chan checkResult, len(h.checks))
+	for _, c := range h.checks {
+		go func(c namedCheck) {
+			results <- checkResult{name: c.name, err: c.check(ctx)}
+		}(c)
+	}
+
+	result := Readiness{Status: "ok", Checks: make(map[string]string, len(h.checks))}
+wait:
+	for range h.checks {
+		select {
+		case r := <-results:
+			result.Checks[r.name] = "ok"
+			if r.err != nil {
+				slog.WarnContext(ctx, "Зависимость недоступна", "check", r.name, logging.Err(r.err))
+				result.Checks[r.name] = "unavailable"
+				result.Status = "unavailable"
+			}
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	// Проверки, не успевшие ответить до таймаута
+	for _, c := range h.checks {
+		if _, ok := result.Checks[c.name]; !ok {
+			slog.WarnContext(ctx, "Зависимость не ответила вовремя", "check", c.name, "timeout", h.timeout)
+			result.Checks[c.name] = "unavailable"
+			result.Status = "unavailable"
+		}
+	}
+
+	if h.draining.Load() {
+		result.Status = "draining"
+	}
+	return result
+}
+
+// HealthzHandler godoc
+// @Summary Проверка живости
+// @Description Отвечает, пока процесс обслуживает запросы. Внешние зависимости не проверяются.
+// @Tags health
+// @Produce json
+// @Success 200 {object} response.HealthResponse "Сервис работает"
+// @Router /healthz [get]
+func (h *Checker) HealthzHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// ReadyzHandler godoc
+// @Summary Проверка готовности
+// @Description Проверяет базу данных, почтовый сервер и облачное хранилище. Для каждой зависимости возвращает только ok или unavailable, причина ошибки пишется в журнал сервера. Во время остановки сервиса возвращает 503.
+// @Tags health
+// @Produce json
+// @Success 200 {object} response.ReadinessResponse "Все зависимости доступны"
+// @Failure 503 {object} response.ReadinessResponse "Зависимость недоступна или сервис останавливается"
+// @Router /readyz [get]
+func (h *Checker) ReadyzHandler(c *gin.Context) {
+	result := h.Ready(c.Request.Context())
+	status := http.StatusOK
+	if result.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, result)
+}
@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func readyz(t *testing.T, h *Checker) (int, Readiness) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/readyz", h.ReadyzHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var result Readiness
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("разбор ответа %q: %v", w.Body.String(), err)
+	}
+	return w.Code, result
+}
+
+func TestReadyz(t *testing.T) {
+	h := NewChecker(50 * time.Millisecond)
+	h.Add("database", func(ctx context.Context) error { return nil })
+
+	code, result := readyz(t, h)
+	if code != http.StatusOK || result.Status != "ok" || result.Checks["database"] != "ok" {
+		t.Fatalf("все зависимости доступны: %d %+v", code, result)
+	}
+
+	h.Add("smtp", func(ctx context.Context) error { return errors.New("dial tcp 10.0.0.5:587: connection refused") })
+	// Зависшая проверка прерывается по таймауту
+	h.Add("storage", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	code, result = readyz(t, h)
+	if code != http.StatusServiceUnavailable || result.Status != "unavailable" {
+		t.Fatalf("зависимость недоступна: %d %+v", code, result)
+	}
+	// Причина ошибки с адресами внутренних серверов не попадает в публичный ответ
+	if result.Checks["database"] != "ok" || result.Checks["smtp"] != "unavailable" || result.Checks["storage"] != "unavailable" {
+		t.Fatalf("результаты проверок: %+v", result.Checks)
+	}
+}
+
+func TestReadyDoesNotWaitForStuckCheck(t *testing.T) {
+	h := NewChecker(50 * time.Millisecond)
+	release := make(chan struct{})
+	defer close(release)
+	h.Add("database", func(ctx context.Context) error { return nil })
+	// Проверка не смотрит на ctx, например ждёт мьютекс
+	h.Add("smtp", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	started := time.Now()
+	result := h.Ready(context.Background())
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("Ready ждал зависшую проверку %s", elapsed)
+	}
+	if result.Status != "unavailable" || result.Checks["database"] != "ok" || result.Checks["smtp"] != "unavailable" {
+		t.Fatalf("результат: %+v", result)
+	}
+}
+
+func TestReadyzWhileDraining(t *testing.T) {
+	h := NewChecker(time.Second)
+	h.Drain()
+
+	if code, result := readyz(t, h); code != http.StatusServiceUnavailable || result.Status != "draining" {
+		t.Fatalf("при остановке: %d %+v", code, result)
+	}
+}
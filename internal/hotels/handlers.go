@@ -1,18 +1,41 @@
 package hotels
 
 import (
-	"hotel-booking/internal/storage"
+	"errors"
+	"hotel-booking/internal/users"
 	"io"
 	"net/http"
-	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Handler — обработчики отелей, номеров, избранного, оценок и штата.
+// Данные читаются только через репозитории, которые подключаются в main.
+type Handler struct {
+	Hotels  HotelRepo
+	Rooms   RoomRepo
+	Ratings RatingRepo
+	Users   users.UserRepo
+}
+
+func NewHandler(hotels HotelRepo, rooms RoomRepo, ratings RatingRepo, users users.UserRepo) *Handler {
+	return &Handler{Hotels: hotels, Rooms: rooms, Ratings: ratings, Users: users}
+}
+
+// parseID разбирает ID из параметра пути или запроса
+func parseID(value string) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 64)
+	return uint(id), err
+}
+
 type CreateHotelInput struct {
-	Name        string `json:"name" binding:"required"`
-	Addres      string `json:"address" binding:"required"`
-	Description string `json:"description"`
+	Name        string  `json:"name" binding:"required"`
+	Addres      string  `json:"address" binding:"required"`
+	Description string  `json:"description"`
+	TaxPercent  float64 `json:"tax_percent" binding:"min=0,max=100"` // Налог, % от стоимости проживания
+	ServiceFee  float64 `json:"service_fee" binding:"min=0"`         // Сервисный сбор за бронирование
 }
 
 // @Security BearerAuth
@@ -28,15 +51,8 @@ type CreateHotelInput struct {
 // @Failure 403 {object} response.ErrorResponse "Только владельцы могут создавать отели"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при создании отеля"
 // @Router /owners/hotels [post]
-func CreateHotelHandler(c *gin.Context) {
+func (h *Handler) CreateHotelHandler(c *gin.Context) {
 	ownerID := c.GetUint("user_id")
-	role := c.GetString("role")
-
-	if role != "owner" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
-		return
-	}
-
 	var input CreateHotelInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -48,9 +64,11 @@ func CreateHotelHandler(c *gin.Context) {
 		Address:     input.Addres,
 		Description: input.Description,
 		OwnerID:     ownerID,
+		TaxPercent:  input.TaxPercent,
+		ServiceFee:  input.ServiceFee,
 	}
 
-	if err := storage.DB.Create(&hotel).Error; err != nil {
+	if err := h.Hotels.Create(&hotel); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании отеля"})
 		return
 	}
@@ -66,9 +84,9 @@ func CreateHotelHandler(c *gin.Context) {
 // @Success 200 {object} []response.HotelResponse "Список отелей"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при получении отелей"
 // @Router /hotels [get]
-func GetHotelsHandler(c *gin.Context) {
-	var hotels []Hotel
-	if err := storage.DB.Preload("Rooms").Find(&hotels).Error; err != nil {
+func (h *Handler) GetHotelsHandler(c *gin.Context) {
+	hotels, err := h.Hotels.List()
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении отелей"})
 		return
 	}
@@ -76,11 +94,14 @@ func GetHotelsHandler(c *gin.Context) {
 }
 
 type CreateRoomInput struct {
-	HotelID   uint    `json:"hotel_id" binding:"required"`
-	RoomType  string  `json:"room_type" binding:"required"`
-	Price     float64 `json:"price" binding:"required"`
-	Amenities string  `json:"amenities"`
-	Capacity  int     `json:"capacity" binding:"required"`
+	HotelID         uint    `json:"hotel_id" binding:"required"`
+	RoomType        string  `json:"room_type" binding:"required"`
+	Price           float64 `json:"price" binding:"required"`
+	Amenities       string  `json:"amenities"`
+	Capacity        int     `json:"capacity" binding:"required"`
+	Units           int     `json:"units" binding:"omitempty,min=1"`                  // Количество номеров этого типа, по умолчанию 1
+	BaseOccupancy   int     `json:"base_occupancy" binding:"min=0,ltefield=Capacity"` // Гостей, включённых в цену; 0 — все
+	ExtraGuestPrice float64 `json:"extra_guest_price" binding:"min=0"`                // Доплата за гостя сверх base_occupancy за ночь
 }
 
 // @Security BearerAuth
@@ -97,35 +118,40 @@ type CreateRoomInput struct {
 // @Failure 404 {object} response.ErrorResponse "Отель не найден"
 // @Failure 500 {object} response.ErrorResponse "Ошибка сервера"
 // @Router /owners/rooms [post]
-func CreateRoomHandler(c *gin.Context) {
-	role := c.GetString("role")
-
-	if role != "owner" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Только владелец может создавать номера"})
-		return
-	}
-
+func (h *Handler) CreateRoomHandler(c *gin.Context) {
 	var input CreateRoomInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	var hotel Hotel
-	if err := storage.DB.First(&hotel, input.HotelID).Error; err != nil {
+	hotel, err := h.Hotels.ByID(input.HotelID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Отель не найден"})
 		return
 	}
 
+	if hotel.OwnerID != c.GetUint("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Отель не принадлежит вам"})
+		return
+	}
+
+	if input.Units == 0 {
+		input.Units = 1
+	}
+
 	room := Room{
-		HotelID:   input.HotelID,
-		RoomType:  input.RoomType,
-		Price:     input.Price,
-		Amenities: input.Amenities,
-		Capacity:  input.Capacity,
+		HotelID:         input.HotelID,
+		RoomType:        input.RoomType,
+		Price:           input.Price,
+		Amenities:       input.Amenities,
+		Capacity:        input.Capacity,
+		Units:           input.Units,
+		BaseOccupancy:   input.BaseOccupancy,
+		ExtraGuestPrice: input.ExtraGuestPrice,
 	}
 
-	if err := storage.DB.Create(&room).Error; err != nil {
+	if err := h.Rooms.Create(&room); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании номера"})
 		return
 	}
@@ -135,7 +161,7 @@ func CreateRoomHandler(c *gin.Context) {
 
 // GetRoomsHandler godoc
 // @Summary Получение списка номеров
-// @Description Возвращает отфильтрованный список номеров с возможностью фильтрации по цене, вместимости, датам бронирования и отелю
+// @Description Возвращает отфильтрованный список типов номеров с возможностью фильтрации по цене, вместимости, датам бронирования и отелю. При указании дат возвращаются типы, у которых в каждую ночь периода есть свободный номер.
 // @Tags rooms
 // @Produce json
 // @Param min_price query string false "Минимальная цена"
@@ -145,52 +171,76 @@ func CreateRoomHandler(c *gin.Context) {
 // @Param end_date query string false "Дата окончания (YYYY-MM-DD)"
 // @Param hotel_id query string false "ID отеля"
 // @Success 200 {array} response.RoomResponse "Список номеров"
+// @Failure 400 {object} response.ErrorResponse "Некорректный фильтр"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при получении номеров"
 // @Router /rooms [get]
-func GetRoomsHandler(c *gin.Context) {
-	var rooms []Room
+func (h *Handler) GetRoomsHandler(c *gin.Context) {
+	filter, err := roomFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rooms, err := h.Rooms.Search(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении номеров"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rooms)
+}
 
-	query := storage.DB.Preload("Images")
+// roomFilter собирает фильтр поиска номеров из параметров запроса
+func roomFilter(c *gin.Context) (RoomFilter, error) {
+	var filter RoomFilter
 
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
-
 	if startDate != "" && endDate != "" {
-		query = query.Where("id NOT IN (?)",
-			storage.DB.Table("bookings").
-				Select("room_id").
-				Where("(start_date <= ? AND end_date >= ?) OR (start_date <= ? AND end_date >= ?) OR (start_date >= ? AND end_date <= ?)",
-					endDate, startDate, endDate, startDate, startDate, endDate))
+		start, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return filter, errors.New("Некорректная дата начала")
+		}
+		end, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return filter, errors.New("Некорректная дата окончания")
+		}
+		filter.StartDate, filter.EndDate = &start, &end
 	}
 
-	hotelID := c.Query("hotel_id")
-	if hotelID != "" {
-		query = query.Where("hotel_id = ?", hotelID)
+	if hotelID := c.Query("hotel_id"); hotelID != "" {
+		id, err := parseID(hotelID)
+		if err != nil {
+			return filter, errors.New("Некорректный ID отеля")
+		}
+		filter.HotelID = id
 	}
-	// фильтры цен
-	minPrice := c.Query("min_price")
-	maxPrice := c.Query("max_price")
 
-	if minPrice != "" {
-		query = query.Where("price >= ?", minPrice)
+	// фильтры цен
+	if minPrice := c.Query("min_price"); minPrice != "" {
+		price, err := strconv.ParseFloat(minPrice, 64)
+		if err != nil {
+			return filter, errors.New("Некорректная минимальная цена")
+		}
+		filter.MinPrice = &price
 	}
-
-	if maxPrice != "" {
-		query = query.Where("price <= ?", maxPrice)
+	if maxPrice := c.Query("max_price"); maxPrice != "" {
+		price, err := strconv.ParseFloat(maxPrice, 64)
+		if err != nil {
+			return filter, errors.New("Некорректная максимальная цена")
+		}
+		filter.MaxPrice = &price
 	}
 
 	// фильтры количества гостей
-	capacity := c.Query("capacity")
-	if capacity != "" {
-		query = query.Where("capacity >= ?", capacity)
-	}
-
-	if err := query.Find(&rooms).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении номеров"})
-		return
+	if capacity := c.Query("capacity"); capacity != "" {
+		guests, err := strconv.Atoi(capacity)
+		if err != nil {
+			return filter, errors.New("Некорректная вместимость")
+		}
+		filter.Capacity = guests
 	}
-
-	c.JSON(http.StatusOK, rooms)
+	return filter, nil
 }
 
 // @Security BearerAuth
@@ -203,17 +253,9 @@ func GetRoomsHandler(c *gin.Context) {
 // @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при получении отелей"
 // @Router /owners/hotels [get]
-func GetOwnerHotelsHandler(c *gin.Context) {
-	ownerID := c.GetUint("user_id")
-	role := c.GetString("role")
-
-	if role != "owner" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
-		return
-	}
-
-	var hotels []Hotel
-	if err := storage.DB.Where("owner_id = ?", ownerID).Find(&hotels).Error; err != nil {
+func (h *Handler) GetOwnerHotelsHandler(c *gin.Context) {
+	hotels, err := h.Hotels.ByOwner(c.GetUint("user_id"))
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении отелей"})
 		return
 	}
@@ -224,7 +266,7 @@ func GetOwnerHotelsHandler(c *gin.Context) {
 // @Security BearerAuth
 // GetOwnerRoomsHandler godoc
 // @Summary Получение списка номеров владельца
-// @Description Возвращает список всех номеров в отелях, принадлежащих текущему владельцу
+// @Description Возвращает список номеров в отелях, которыми текущий пользователь владеет или в штате которых состоит
 // @Tags rooms
 // @Produce json
 // @Param hotel_id query string false "ID отеля для фильтрации"
@@ -232,24 +274,26 @@ func GetOwnerHotelsHandler(c *gin.Context) {
 // @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при получении номеров"
 // @Router /owners/rooms [get]
-func GetOwnerRoomsHandler(c *gin.Context) {
-	ownerID := c.GetUint("user_id")
-	role := c.GetString("role")
-	hotelID := c.Query("hotel_id")
-
-	if role != "owner" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+func (h *Handler) GetOwnerRoomsHandler(c *gin.Context) {
+	hotelIDs, err := h.Hotels.ManagedIDs(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении номеров"})
 		return
 	}
 
-	query := storage.DB.Table("rooms").Joins("JOIN hotels ON rooms.hotel_id = hotels.id").Joins("JOIN users ON hotels.owner_id = users.id").Where("users.id = ?", ownerID)
-
-	if hotelID != "" {
-		query = query.Where("hotels.id = ?", hotelID)
+	if hotelID := c.Query("hotel_id"); hotelID != "" {
+		id, _ := parseID(hotelID)
+		var filtered []uint
+		for _, managed := range hotelIDs {
+			if managed == id {
+				filtered = append(filtered, managed)
+			}
+		}
+		hotelIDs = filtered
 	}
 
-	var rooms []Room
-	if err := query.Find(&rooms).Error; err != nil {
+	rooms, err := h.Rooms.ByHotels(hotelIDs)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении номеров"})
 		return
 	}
@@ -260,7 +304,7 @@ func GetOwnerRoomsHandler(c *gin.Context) {
 // @Security BearerAuth
 // ChangeRoomHandler godoc
 // @Summary Изменение номера
-// @Description Изменяет существующий номер. Доступно только для владельцев.
+// @Description Изменяет существующий номер. Доступно владельцу и менеджерам отеля.
 // @Tags rooms
 // @Accept json
 // @Produce json
@@ -272,45 +316,27 @@ func GetOwnerRoomsHandler(c *gin.Context) {
 // @Failure 404 {object} response.ErrorResponse "Номер не найден"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при обновлении номера"
 // @Router /owners/{id}/room [put]
-func ChangeRoomHandler(c *gin.Context) {
-	roomID := c.Param("id")
-	ownerID := c.GetUint("user_id")
-	role := c.GetString("role")
-
-	if role != "owner" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
-		return
-	}
-
+func (h *Handler) ChangeRoomHandler(c *gin.Context) {
 	var room CreateRoomInput
 	if err := c.ShouldBindJSON(&room); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	var existingRoom Room
-	if err := storage.DB.First(&existingRoom, roomID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
-		return
-	}
-
-	var hotel Hotel
-	if err := storage.DB.First(&hotel, existingRoom.HotelID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Отель не найден"})
-		return
-	}
-
-	if hotel.OwnerID != ownerID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Номер не принадлежит вам"})
+	existingRoom, ok := h.managedRoom(c)
+	if !ok {
 		return
 	}
 
-	if storage.DB.Model(&existingRoom).Updates(Room{
-		RoomType:  room.RoomType,
-		Price:     room.Price,
-		Amenities: room.Amenities,
-		Capacity:  room.Capacity,
-	}).Error != nil {
+	if h.Rooms.Update(&existingRoom, Room{
+		RoomType:        room.RoomType,
+		Price:           room.Price,
+		Amenities:       room.Amenities,
+		Capacity:        room.Capacity,
+		Units:           room.Units,
+		BaseOccupancy:   room.BaseOccupancy,
+		ExtraGuestPrice: room.ExtraGuestPrice,
+	}) != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении номера"})
 		return
 	}
@@ -331,34 +357,26 @@ func ChangeRoomHandler(c *gin.Context) {
 // @Failure 404 {object} response.ErrorResponse "Номер не найден"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при удалении номера"
 // @Router /owners/{id}/room [delete]
-func DeleteRoomHandler(c *gin.Context) {
-	roomID := c.Param("id")
+func (h *Handler) DeleteRoomHandler(c *gin.Context) {
 	ownerID := c.GetUint("user_id")
-	role := c.GetString("role")
-
-	if role != "owner" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
-		return
-	}
-
-	var existingRoom Room
-	if err := storage.DB.First(&existingRoom, roomID).Error; err != nil {
+	existingRoom, err := h.room(c.Param("id"))
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
 		return
 	}
 
-	var hotel Hotel
-	if err := storage.DB.First(&hotel, existingRoom.HotelID).Error; err != nil {
+	hotel, err := h.Hotels.ByID(existingRoom.HotelID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Отель не найден"})
 		return
 	}
 
 	if hotel.OwnerID != ownerID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Номер не принадлежит вам"})
+		c.JSON(http.StatusForbidden, gin.H{"error": "Номер не принадлежит вам"})
 		return
 	}
 
-	if err := storage.DB.Delete(&existingRoom).Error; err != nil {
+	if err := h.Rooms.Delete(&existingRoom); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении номера"})
 		return
 	}
@@ -378,21 +396,23 @@ func DeleteRoomHandler(c *gin.Context) {
 // @Failure 400 {object} response.ErrorResponse "Номер уже в избранном"
 // @Failure 404 {object} response.ErrorResponse "Номер не найден"
 // @Router /favorites/{room_id} [post]
-func AddToFavoritesHandler(c *gin.Context) {
+func (h *Handler) AddToFavoritesHandler(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	roomID := c.Param("room_id")
 
 	// Проверяем существование номера
-	var room Room
-	if err := storage.DB.First(&room, roomID).Error; err != nil {
+	room, err := h.room(c.Param("room_id"))
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
 		return
 	}
 
 	// Проверяем, не добавлен ли номер уже в избранное
-	var existing Favorite
-	result := storage.DB.Where("user_id = ? AND room_id = ?", userID, roomID).First(&existing)
-	if result.RowsAffected > 0 {
+	exists, err := h.Rooms.IsFavorite(userID, room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении в избранное"})
+		return
+	}
+	if exists {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Номер уже в избранном"})
 		return
 	}
@@ -403,7 +423,7 @@ func AddToFavoritesHandler(c *gin.Context) {
 		RoomID: room.ID,
 	}
 
-	if err := storage.DB.Create(&favorite).Error; err != nil {
+	if err := h.Rooms.AddFavorite(&favorite); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении в избранное"})
 		return
 	}
@@ -420,13 +440,9 @@ func AddToFavoritesHandler(c *gin.Context) {
 // @Success 200 {array} response.RoomResponse "Список избранных номеров"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при получении списка избранного"
 // @Router /favorites [get]
-func GetFavoritesHandler(c *gin.Context) {
-	userID := c.GetUint("user_id")
-
-	var rooms []Room
-	if err := storage.DB.Joins("JOIN favorites ON rooms.id = favorites.room_id").
-		Where("favorites.user_id = ? AND favorites.deleted_at IS NULL", userID).
-		Find(&rooms).Error; err != nil {
+func (h *Handler) GetFavoritesHandler(c *gin.Context) {
+	rooms, err := h.Rooms.Favorites(c.GetUint("user_id"))
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении избранных номеров"})
 		return
 	}
@@ -444,12 +460,15 @@ func GetFavoritesHandler(c *gin.Context) {
 // @Success 200 {object} response.MessageResponse "Номер успешно удален из избранного"
 // @Failure 404 {object} response.ErrorResponse "Номер не найден в избранном"
 // @Router /favorites/{room_id} [delete]
-func RemoveFromFavoritesHandler(c *gin.Context) {
-	userID := c.GetUint("user_id")
-	roomID := c.Param("room_id")
+func (h *Handler) RemoveFromFavoritesHandler(c *gin.Context) {
+	roomID, _ := parseID(c.Param("room_id"))
 
-	result := storage.DB.Where("user_id = ? AND room_id = ?", userID, roomID).Delete(&Favorite{})
-	if result.RowsAffected == 0 {
+	removed, err := h.Rooms.RemoveFavorite(c.GetUint("user_id"), roomID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении из избранного"})
+		return
+	}
+	if !removed {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден в избранном"})
 		return
 	}
@@ -476,9 +495,8 @@ type RatingInput struct {
 // @Failure 400 {object} response.ErrorResponse "Недопустимый рейтинг/Вы уже оценили этот отель"
 // @Failure 404 {object} response.ErrorResponse "Отель не найден"
 // @Router /hotels/{hotel_id}/rate [post]
-func RateHotelHandler(c *gin.Context) {
+func (h *Handler) RateHotelHandler(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	hotelID := c.Param("hotel_id")
 
 	var input RatingInput
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -491,15 +509,18 @@ func RateHotelHandler(c *gin.Context) {
 		return
 	}
 
-	var hotel Hotel
-	if err := storage.DB.First(&hotel, hotelID).Error; err != nil {
+	hotel, err := h.hotel(c.Param("hotel_id"))
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Отель не найден"})
 		return
 	}
 
-	var existingRating HotelRating
-	result := storage.DB.Where("user_id = ? AND hotel_id = ?", userID, hotelID).First(&existingRating)
-	if result.RowsAffected > 0 {
+	rated, err := h.Ratings.HasRatedHotel(userID, hotel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении оценки"})
+		return
+	}
+	if rated {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Вы уже оценили этот отель"})
 		return
 	}
@@ -511,27 +532,13 @@ func RateHotelHandler(c *gin.Context) {
 		Comment: input.Comment,
 	}
 
-	tx := storage.DB.Begin()
-
-	if err := tx.Create(&rating).Error; err != nil {
-		tx.Rollback()
+	// Оценка и новый средний рейтинг сохраняются в одной транзакции
+	if err := h.Ratings.RateHotel(&hotel, &rating); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении оценки"})
 		return
 	}
 
-	newAvgRating := (hotel.AverageRating*float64(hotel.RatingsCount) + float64(input.Rating)) / float64(hotel.RatingsCount+1)
-	if err := tx.Model(&hotel).Updates(map[string]interface{}{
-		"average_rating": newAvgRating,
-		"ratings_count":  hotel.RatingsCount + 1,
-	}).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении рейтинга"})
-		return
-	}
-
-	tx.Commit()
 	c.JSON(http.StatusOK, gin.H{"message": "Оценка успешно добавлена"})
-
 }
 
 // @Security BearerAuth
@@ -547,9 +554,8 @@ func RateHotelHandler(c *gin.Context) {
 // @Failure 400 {object} response.ErrorResponse "Недопусти рейтинг/Вы уже оценили этот номер"
 // @Failure 404 {object} response.ErrorResponse "Номер не найден"
 // @Router /rooms/{room_id}/rate [post]
-func RateRoomHandler(c *gin.Context) {
+func (h *Handler) RateRoomHandler(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	roomID := c.Param("id")
 
 	var input RatingInput
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -562,15 +568,18 @@ func RateRoomHandler(c *gin.Context) {
 		return
 	}
 
-	var room Room
-	if err := storage.DB.First(&room, roomID).Error; err != nil {
+	room, err := h.room(c.Param("room_id"))
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
 		return
 	}
 
-	var existingRating RoomRating
-	result := storage.DB.Where("user_id = ? AND room_id = ?", userID, roomID).First(&existingRating)
-	if result.RowsAffected > 0 {
+	rated, err := h.Ratings.HasRatedRoom(userID, room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении оценки"})
+		return
+	}
+	if rated {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Вы уже оценили этот номер"})
 		return
 	}
@@ -582,25 +591,12 @@ func RateRoomHandler(c *gin.Context) {
 		Comment: input.Comment,
 	}
 
-	tx := storage.DB.Begin()
-
-	if err := tx.Create(&rating).Error; err != nil {
-		tx.Rollback()
+	// Оценка и новый средний рейтинг сохраняются в одной транзакции
+	if err := h.Ratings.RateRoom(&room, &rating); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении оценки"})
 		return
 	}
 
-	newAvgRating := (room.AverageRating*float64(room.RatingsCount) + float64(input.Rating)) / float64(room.RatingsCount+1)
-	if err := tx.Model(&room).Updates(map[string]interface{}{
-		"average_rating": newAvgRating,
-		"ratings_count":  room.RatingsCount + 1,
-	}).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении рейтинга"})
-		return
-	}
-
-	tx.Commit()
 	c.JSON(http.StatusOK, gin.H{"message": "Оценка успешно добавлена"})
 }
 
@@ -613,11 +609,11 @@ func RateRoomHandler(c *gin.Context) {
 // @Success 200 {array} []response.HotelRatingResponse "Список оценок отеля"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при получении оценок"
 // @Router /hotels/{hotel_id}/rate [get]
-func GetHotelsRatingsHandler(c *gin.Context) {
-	hotelID := c.Param("hotel_id")
+func (h *Handler) GetHotelsRatingsHandler(c *gin.Context) {
+	hotelID, _ := parseID(c.Param("hotel_id"))
 
-	var retings []HotelRating
-	if err := storage.DB.Where("hotel_id = ?", hotelID).Find(&retings).Error; err != nil {
+	retings, err := h.Ratings.HotelRatings(hotelID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении оценок"})
 		return
 	}
@@ -634,11 +630,11 @@ func GetHotelsRatingsHandler(c *gin.Context) {
 // @Success 200 {array} []response.RoomRatingResponse "Список оценок номера"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при получении оценок"
 // @Router /rooms/{room_id}/rate [get]
-func GetRoomsRatingsHandler(c *gin.Context) {
-	roomID := c.Param("id")
+func (h *Handler) GetRoomsRatingsHandler(c *gin.Context) {
+	roomID, _ := parseID(c.Param("id"))
 
-	var retings []RoomRating
-	if err := storage.DB.Where("room_id = ?", roomID).Find(&retings).Error; err != nil {
+	retings, err := h.Ratings.RoomRatings(roomID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении оценок"})
 		return
 	}
@@ -664,24 +660,9 @@ func GetRoomsRatingsHandler(c *gin.Context) {
 // @Failure 404 {object} response.ErrorResponse "Отель не найден"
 // @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
 // @Router /owners/rooms/{id}/images [post]
-func UploadRoomImagesHandler(c *gin.Context) {
-	ownerID := c.GetUint("user_id")
-	roomID := c.Param("id")
-
-	var room Room
-	if err := storage.DB.First(&room, roomID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
-		return
-	}
-
-	var hotel Hotel
-	if err := storage.DB.First(&hotel, room.HotelID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Отель не найден"})
-		return
-	}
-
-	if hotel.OwnerID != ownerID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+func (h *Handler) UploadRoomImagesHandler(c *gin.Context) {
+	room, ok := h.managedRoom(c)
+	if !ok {
 		return
 	}
 
@@ -692,11 +673,7 @@ func UploadRoomImagesHandler(c *gin.Context) {
 	}
 
 	files := form.File["images"]
-	webdavService := NewWebDAVService(
-		"https://webdav.cloud.mail.ru",
-		os.Getenv("WEBDAV_USERNAME"),
-		os.Getenv("WEBDAV_PASSWORD"),
-	)
+	webdavService := NewWebDAVService(getWebDAVConfig())
 	if webdavService == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка подключения к облачному хранилищу"})
 		return
@@ -739,7 +716,7 @@ func UploadRoomImagesHandler(c *gin.Context) {
 			ImageName: filename,
 		}
 
-		if err := storage.DB.Create(&roomImage).Error; err != nil {
+		if err := h.Rooms.AddImage(&roomImage); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении изображения"})
 			return
 		}
@@ -760,39 +737,20 @@ func UploadRoomImagesHandler(c *gin.Context) {
 // @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
 // @Failure 404 {object} response.ErrorResponse "Изображение не найдено"
 // @Router /owners/rooms/{room_id}/images/{image_id} [delete]
-func DeleteRoomImageHandler(c *gin.Context) {
-	ownerID := c.GetUint("user_id")
-	roomID := c.Param("id")
-	imageID := c.Param("image_id")
-
-	var room Room
-	if err := storage.DB.First(&room, roomID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
+func (h *Handler) DeleteRoomImageHandler(c *gin.Context) {
+	room, ok := h.managedRoom(c)
+	if !ok {
 		return
 	}
 
-	var hotel Hotel
-	if err := storage.DB.First(&hotel, room.HotelID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Отель не найден"})
-		return
-	}
-
-	if hotel.OwnerID != ownerID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
-		return
-	}
-
-	var image RoomImage
-	if err := storage.DB.Where("id = ? AND room_id = ?", imageID, roomID).First(&image).Error; err != nil {
+	imageID, _ := parseID(c.Param("image_id"))
+	image, err := h.Rooms.Image(room.ID, imageID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Изображение не найдено"})
 		return
 	}
 
-	webdavService := NewWebDAVService(
-		"https://webdav.cloud.mail.ru",
-		os.Getenv("WEBDAV_USERNAME"),
-		os.Getenv("WEBDAV_PASSWORD"),
-	)
+	webdavService := NewWebDAVService(getWebDAVConfig())
 	if webdavService == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка подключения к облачному хранилищу"})
 		return
@@ -803,7 +761,7 @@ func DeleteRoomImageHandler(c *gin.Context) {
 		return
 	}
 
-	if err := storage.DB.Delete(&image).Error; err != nil {
+	if err := h.Rooms.DeleteImage(&image); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении изображения"})
 		return
 	}
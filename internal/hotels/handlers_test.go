@@ -0,0 +1,89 @@
+package hotels
+
+import (
+	"fmt"
+	"hotel-booking/internal/users"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRoomManagementAccess(t *testing.T) {
+	// Отель 1 — владелец 1, менеджер 5; отель 2 — владелец 2, менеджер 6
+	store := NewMemory()
+	for _, hotel := range []Hotel{{Name: "Первый", OwnerID: 1}, {Name: "Второй", OwnerID: 2}} {
+		if err := store.Hotels().Create(&hotel); err != nil {
+			t.Fatal(err)
+		}
+		manager := uint(5)
+		if hotel.OwnerID == 2 {
+			manager = 6
+		}
+		if err := store.Hotels().AddStaff(&HotelStaff{HotelID: hotel.ID, UserID: manager, Role: StaffRoleManager}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	room := Room{HotelID: 1, RoomType: "Стандарт", Price: 1000, Capacity: 2, Units: 1}
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(store.Hotels(), store.Rooms(), store.Ratings(), users.NewMemoryUserRepo())
+
+	roomPath := fmt.Sprintf("/owners/%d/room", room.ID)
+	imagesPath := fmt.Sprintf("/owners/rooms/%d/images", room.ID)
+	imagePath := imagesPath + "/1"
+	update := `{"hotel_id":1,"room_type":"Люкс","price":2000,"capacity":2}`
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		userID uint
+		role   string
+		want   int
+	}{
+		{"изменение номера владельцем", http.MethodPut, roomPath, update, 1, "owner", http.StatusOK},
+		{"изменение номера менеджером отеля", http.MethodPut, roomPath, update, 5, "manager", http.StatusOK},
+		{"изменение номера менеджером другого отеля", http.MethodPut, roomPath, update, 6, "manager", http.StatusForbidden},
+		{"изменение номера владельцем другого отеля", http.MethodPut, roomPath, update, 2, "owner", http.StatusForbidden},
+		{"удаление номера менеджером другого отеля", http.MethodDelete, roomPath, "", 6, "manager", http.StatusForbidden},
+		{"удаление номера владельцем другого отеля", http.MethodDelete, roomPath, "", 2, "owner", http.StatusForbidden},
+		// Доступ проверяется до разбора формы: без формы свой отель получает 400, чужой — 403
+		{"загрузка изображений менеджером отеля", http.MethodPost, imagesPath, "", 5, "manager", http.StatusBadRequest},
+		{"загрузка изображений менеджером другого отеля", http.MethodPost, imagesPath, "", 6, "manager", http.StatusForbidden},
+		// Изображения нет: свой отель получает 404, чужой — 403 без подсказки о его наличии
+		{"удаление изображения менеджером отеля", http.MethodDelete, imagePath, "", 5, "manager", http.StatusNotFound},
+		{"удаление изображения менеджером другого отеля", http.MethodDelete, imagePath, "", 6, "manager", http.StatusForbidden},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Set("user_id", tt.userID)
+				c.Set("role", tt.role)
+			})
+			r.PUT("/owners/:id/room", h.ChangeRoomHandler)
+			r.DELETE("/owners/:id/room", h.DeleteRoomHandler)
+			r.POST("/owners/rooms/:id/images", h.UploadRoomImagesHandler)
+			r.DELETE("/owners/rooms/:id/images/:image_id", h.DeleteRoomImageHandler)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("код %d, ожидался %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	// Номер изменён разрешёнными запросами и не удалён запрещёнными
+	if stored, _ := store.Rooms().ByID(room.ID); stored.RoomType != "Люкс" {
+		t.Fatalf("номер после проверок: %+v", stored)
+	}
+}
@@ -0,0 +1,468 @@
+package hotels
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Memory хранит отели, номера, штат и оценки в памяти. Репозитории, полученные
+// из одного Memory, видят общие данные, как таблицы одной базы.
+type Memory struct {
+	// Availability решает, есть ли у номера свободное место на период, для RoomFilter с датами.
+	// Если не задана, свободны все номера.
+	Availability func(room Room, start, end time.Time) bool
+
+	mu           sync.Mutex
+	nextID       uint
+	hotels       map[uint]*Hotel
+	rooms        map[uint]*Room
+	staff        []HotelStaff
+	images       map[uint]*RoomImage
+	favorites    map[uint]*Favorite
+	hotelRatings []HotelRating
+	roomRatings  []RoomRating
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		hotels:    map[uint]*Hotel{},
+		rooms:     map[uint]*Room{},
+		images:    map[uint]*RoomImage{},
+		favorites: map[uint]*Favorite{},
+	}
+}
+
+func (m *Memory) Hotels() HotelRepo   { return memoryHotels{m} }
+func (m *Memory) Rooms() RoomRepo     { return memoryRooms{m} }
+func (m *Memory) Ratings() RatingRepo { return memoryRatings{m} }
+
+// id выдаёт следующий ID; вызывается под m.mu
+func (m *Memory) id() uint {
+	m.nextID++
+	return m.nextID
+}
+
+func stamp(model *gorm.Model, id uint) {
+	now := time.Now()
+	model.ID = id
+	model.CreatedAt = now
+	model.UpdatedAt = now
+}
+
+func sortRooms(rooms []Room) []Room {
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].ID < rooms[j].ID })
+	return rooms
+}
+
+type memoryHotels struct{ m *Memory }
+
+func (r memoryHotels) ByID(id uint) (Hotel, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	hotel, ok := r.m.hotels[id]
+	if !ok {
+		return Hotel{}, gorm.ErrRecordNotFound
+	}
+	return *hotel, nil
+}
+
+func (r memoryHotels) List() ([]Hotel, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	hotels := make([]Hotel, 0, len(r.m.hotels))
+	for _, hotel := range r.m.hotels {
+		h := *hotel
+		h.Rooms = []Room{}
+		for _, room := range r.m.rooms {
+			if room.HotelID == h.ID && !room.DeletedAt.Valid {
+				h.Rooms = append(h.Rooms, *room)
+			}
+		}
+		sortRooms(h.Rooms)
+		hotels = append(hotels, h)
+	}
+	sort.Slice(hotels, func(i, j int) bool { return hotels[i].ID < hotels[j].ID })
+	return hotels, nil
+}
+
+func (r memoryHotels) ByOwner(ownerID uint) ([]Hotel, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	var hotels []Hotel
+	for _, hotel := range r.m.hotels {
+		if hotel.OwnerID == ownerID {
+			hotels = append(hotels, *hotel)
+		}
+	}
+	sort.Slice(hotels, func(i, j int) bool { return hotels[i].ID < hotels[j].ID })
+	return hotels, nil
+}
+
+func (r memoryHotels) Create(hotel *Hotel) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	stamp(&hotel.Model, r.m.id())
+	stored := *hotel
+	r.m.hotels[hotel.ID] = &stored
+	return nil
+}
+
+func (r memoryHotels) IsStaff(hotelID, userID uint) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for _, staff := range r.m.staff {
+		if staff.HotelID == hotelID && staff.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r memoryHotels) ManagedIDs(userID uint) ([]uint, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	managed := map[uint]bool{}
+	for _, hotel := range r.m.hotels {
+		if hotel.OwnerID == userID {
+			managed[hotel.ID] = true
+		}
+	}
+	for _, staff := range r.m.staff {
+		if staff.UserID == userID {
+			if _, ok := r.m.hotels[staff.HotelID]; ok {
+				managed[staff.HotelID] = true
+			}
+		}
+	}
+	ids := make([]uint, 0, len(managed))
+	for id := range managed {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (r memoryHotels) Staff(hotelID uint) ([]HotelStaff, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	var staff []HotelStaff
+	for _, s := range r.m.staff {
+		if s.HotelID == hotelID {
+			staff = append(staff, s)
+		}
+	}
+	return staff, nil
+}
+
+func (r memoryHotels) AddStaff(staff *HotelStaff) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	staff.ID = r.m.id()
+	staff.CreatedAt = time.Now()
+	r.m.staff = append(r.m.staff, *staff)
+	return nil
+}
+
+func (r memoryHotels) RemoveStaff(hotelID, userID uint) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for i, s := range r.m.staff {
+		if s.HotelID == hotelID && s.UserID == userID {
+			r.m.staff = append(r.m.staff[:i], r.m.staff[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type memoryRooms struct{ m *Memory }
+
+func (r memoryRooms) ByID(id uint) (Room, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	room, ok := r.m.rooms[id]
+	if !ok || room.DeletedAt.Valid {
+		return Room{}, gorm.ErrRecordNotFound
+	}
+	return *room, nil
+}
+
+func (r memoryRooms) ByIDUnscoped(id uint) (Room, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	room, ok := r.m.rooms[id]
+	if !ok {
+		return Room{}, gorm.ErrRecordNotFound
+	}
+	return *room, nil
+}
+
+func (r memoryRooms) Search(filter RoomFilter) ([]Room, error) {
+	r.m.mu.Lock()
+	var rooms []Room
+	for _, room := range r.m.rooms {
+		switch {
+		case room.DeletedAt.Valid,
+			filter.HotelID != 0 && room.HotelID != filter.HotelID,
+			filter.MinPrice != nil && room.Price < *filter.MinPrice,
+			filter.MaxPrice != nil && room.Price > *filter.MaxPrice,
+			filter.Capacity > 0 && room.Capacity < filter.Capacity:
+			continue
+		}
+		found := *room
+		found.Images = []RoomImage{}
+		for _, image := range r.m.images {
+			if image.RoomID == room.ID {
+				found.Images = append(found.Images, *image)
+			}
+		}
+		rooms = append(rooms, found)
+	}
+	r.m.mu.Unlock()
+
+	// Availability может обращаться к другим репозиториям, поэтому вызывается без блокировки
+	if filter.StartDate != nil && filter.EndDate != nil && r.m.Availability != nil {
+		available := rooms[:0]
+		for _, room := range rooms {
+			if r.m.Availability(room, *filter.StartDate, *filter.EndDate) {
+				available = append(available, room)
+			}
+		}
+		rooms = available
+	}
+	return sortRooms(rooms), nil
+}
+
+func (r memoryRooms) ByHotels(hotelIDs []uint) ([]Room, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	wanted := map[uint]bool{}
+	for _, id := range hotelIDs {
+		wanted[id] = true
+	}
+	var rooms []Room
+	for _, room := range r.m.rooms {
+		if wanted[room.HotelID] && !room.DeletedAt.Valid {
+			rooms = append(rooms, *room)
+		}
+	}
+	return sortRooms(rooms), nil
+}
+
+func (r memoryRooms) Create(room *Room) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	stamp(&room.Model, r.m.id())
+	// Значения по умолчанию из схемы
+	if room.Units == 0 {
+		room.Units = 1
+	}
+	room.Available = true
+	stored := *room
+	r.m.rooms[room.ID] = &stored
+	return nil
+}
+
+func (r memoryRooms) Update(room *Room, changes Room) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	stored, ok := r.m.rooms[room.ID]
+	if !ok || stored.DeletedAt.Valid {
+		return gorm.ErrRecordNotFound
+	}
+	if changes.RoomType != "" {
+		stored.RoomType = changes.RoomType
+	}
+	if changes.Price != 0 {
+		stored.Price = changes.Price
+	}
+	if changes.Amenities != "" {
+		stored.Amenities = changes.Amenities
+	}
+	if changes.Capacity != 0 {
+		stored.Capacity = changes.Capacity
+	}
+	if changes.Units != 0 {
+		stored.Units = changes.Units
+	}
+	if changes.BaseOccupancy != 0 {
+		stored.BaseOccupancy = changes.BaseOccupancy
+	}
+	if changes.ExtraGuestPrice != 0 {
+		stored.ExtraGuestPrice = changes.ExtraGuestPrice
+	}
+	stored.UpdatedAt = time.Now()
+	*room = *stored
+	return nil
+}
+
+func (r memoryRooms) Delete(room *Room) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	stored, ok := r.m.rooms[room.ID]
+	if !ok {
+		return nil
+	}
+	stored.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (r memoryRooms) AddImage(image *RoomImage) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	stamp(&image.Model, r.m.id())
+	stored := *image
+	r.m.images[image.ID] = &stored
+	return nil
+}
+
+func (r memoryRooms) Image(roomID, imageID uint) (RoomImage, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	image, ok := r.m.images[imageID]
+	if !ok || image.RoomID != roomID {
+		return RoomImage{}, gorm.ErrRecordNotFound
+	}
+	return *image, nil
+}
+
+func (r memoryRooms) DeleteImage(image *RoomImage) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	delete(r.m.images, image.ID)
+	return nil
+}
+
+func (r memoryRooms) IsFavorite(userID, roomID uint) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for _, favorite := range r.m.favorites {
+		if favorite.UserID == userID && favorite.RoomID == roomID && !favorite.DeletedAt.Valid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r memoryRooms) AddFavorite(favorite *Favorite) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	stamp(&favorite.Model, r.m.id())
+	stored := *favorite
+	r.m.favorites[favorite.ID] = &stored
+	return nil
+}
+
+func (r memoryRooms) Favorites(userID uint) ([]Room, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	var rooms []Room
+	for _, favorite := range r.m.favorites {
+		if favorite.UserID != userID || favorite.DeletedAt.Valid {
+			continue
+		}
+		if room, ok := r.m.rooms[favorite.RoomID]; ok && !room.DeletedAt.Valid {
+			rooms = append(rooms, *room)
+		}
+	}
+	return sortRooms(rooms), nil
+}
+
+func (r memoryRooms) RemoveFavorite(userID, roomID uint) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	removed := false
+	for _, favorite := range r.m.favorites {
+		if favorite.UserID == userID && favorite.RoomID == roomID && !favorite.DeletedAt.Valid {
+			favorite.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+			removed = true
+		}
+	}
+	return removed, nil
+}
+
+type memoryRatings struct{ m *Memory }
+
+func (r memoryRatings) HotelRatings(hotelID uint) ([]HotelRating, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	var ratings []HotelRating
+	for _, rating := range r.m.hotelRatings {
+		if rating.HotelID == hotelID {
+			ratings = append(ratings, rating)
+		}
+	}
+	return ratings, nil
+}
+
+func (r memoryRatings) RoomRatings(roomID uint) ([]RoomRating, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	var ratings []RoomRating
+	for _, rating := range r.m.roomRatings {
+		if rating.RoomID == roomID {
+			ratings = append(ratings, rating)
+		}
+	}
+	return ratings, nil
+}
+
+func (r memoryRatings) HasRatedHotel(userID, hotelID uint) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for _, rating := range r.m.hotelRatings {
+		if rating.UserID == userID && rating.HotelID == hotelID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r memoryRatings) HasRatedRoom(userID, roomID uint) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for _, rating := range r.m.roomRatings {
+		if rating.UserID == userID && rating.RoomID == roomID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// average добавляет оценку к среднему значению count оценок
+func average(avg float64, count int, rating float64) float64 {
+	return (avg*float64(count) + rating) / float64(count+1)
+}
+
+func (r memoryRatings) RateHotel(hotel *Hotel, rating *HotelRating) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	stored, ok := r.m.hotels[hotel.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	stamp(&rating.Model, r.m.id())
+	r.m.hotelRatings = append(r.m.hotelRatings, *rating)
+	stored.AverageRating = average(stored.AverageRating, stored.RatingsCount, rating.Rating)
+	stored.RatingsCount++
+	hotel.AverageRating, hotel.RatingsCount = stored.AverageRating, stored.RatingsCount
+	return nil
+}
+
+func (r memoryRatings) RateRoom(room *Room, rating *RoomRating) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	stored, ok := r.m.rooms[room.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	stamp(&rating.Model, r.m.id())
+	r.m.roomRatings = append(r.m.roomRatings, *rating)
+	stored.AverageRating = average(stored.AverageRating, stored.RatingsCount, rating.Rating)
+	stored.RatingsCount++
+	room.AverageRating, room.RatingsCount = stored.AverageRating, stored.RatingsCount
+	return nil
+}
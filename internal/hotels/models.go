@@ -12,22 +12,29 @@ type Hotel struct {
 	OwnerID       uint    `gorm:"not null"` // ID владельца отеля
 	AverageRating float64 `gorm:"default:0"`
 	RatingsCount  int     `gorm:"default:0"`
+	TaxPercent    float64 `gorm:"default:0"` // Налог, % от стоимости проживания
+	ServiceFee    float64 `gorm:"default:0"` // Сервисный сбор за бронирование
 	Rooms         []Room
 	Ratings       []HotelRating
 }
 
+// Room — тип номера в отеле. Units одинаковых номеров продаются как общий запас:
+// на каждую ночь доступно Units минус число активных бронирований.
 type Room struct {
 	gorm.Model
-	HotelID       uint    `gorm:"not null"`                  // ID отеля
-	RoomType      string  `gorm:"type:varchar(50);not null"` // Тип номера (стандартный, люкс и т.д.)
-	Price         float64 `gorm:"not null"`                  // Цена за ночь
-	Amenities     string  `gorm:"type:text"`                 // Удобства
-	Capacity      int     `gorm:"not null"`                  // Количество гостей
-	Available     bool    `gorm:"default:true"`              // Наличие
-	AverageRating float64 `gorm:"default:0"`
-	RatingsCount  int     `gorm:"default:0"`
-	Ratings       []RoomRating
-	Images        []RoomImage
+	HotelID         uint    `gorm:"not null"`                  // ID отеля
+	RoomType        string  `gorm:"type:varchar(50);not null"` // Тип номера (стандартный, люкс и т.д.)
+	Price           float64 `gorm:"not null"`                  // Цена за ночь
+	Amenities       string  `gorm:"type:text"`                 // Удобства
+	Capacity        int     `gorm:"not null"`                  // Количество гостей
+	BaseOccupancy   int     `gorm:"default:0"`                 // Гостей, включённых в цену; 0 — все Capacity
+	ExtraGuestPrice float64 `gorm:"default:0"`                 // Доплата за каждого гостя сверх BaseOccupancy за ночь
+	Units           int     `gorm:"not null;default:1"`        // Количество номеров этого типа
+	Available       bool    `gorm:"default:true"`              // Наличие
+	AverageRating   float64 `gorm:"default:0"`
+	RatingsCount    int     `gorm:"default:0"`
+	Ratings         []RoomRating
+	Images          []RoomImage
 }
 
 type RoomImage struct {
@@ -0,0 +1,319 @@
+package hotels
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Репозитории отелей, номеров и оценок. Обработчики работают только через них,
+// поэтому в тестах база данных заменяется на Memory. Не найденная запись
+// возвращается как gorm.ErrRecordNotFound в обеих реализациях.
+
+// HotelRepo — отели и их штат
+type HotelRepo interface {
+	ByID(id uint) (Hotel, error)
+	// List возвращает все отели вместе с номерами
+	List() ([]Hotel, error)
+	ByOwner(ownerID uint) ([]Hotel, error)
+	Create(hotel *Hotel) error
+	IsStaff(hotelID, userID uint) (bool, error)
+	// ManagedIDs возвращает ID отелей, которыми пользователь владеет или в штате которых состоит
+	ManagedIDs(userID uint) ([]uint, error)
+	Staff(hotelID uint) ([]HotelStaff, error)
+	AddStaff(staff *HotelStaff) error
+	// RemoveStaff исключает пользователя из штата; false, если он в штате не состоял
+	RemoveStaff(hotelID, userID uint) (bool, error)
+}
+
+// RoomFilter — условия поиска номеров; нулевые значения не ограничивают выборку
+type RoomFilter struct {
+	HotelID  uint
+	MinPrice *float64
+	MaxPrice *float64
+	Capacity int
+	// Если заданы обе даты, остаются типы номеров, у которых в каждую ночь периода есть свободный номер
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// RoomRepo — номера, их фотографии и избранное пользователей
+type RoomRepo interface {
+	ByID(id uint) (Room, error)
+	// ByIDUnscoped находит номер, даже если он удалён: нужен для старых бронирований
+	ByIDUnscoped(id uint) (Room, error)
+	// Search возвращает номера с фотографиями по фильтру
+	Search(filter RoomFilter) ([]Room, error)
+	ByHotels(hotelIDs []uint) ([]Room, error)
+	Create(room *Room) error
+	// Update записывает ненулевые поля changes
+	Update(room *Room, changes Room) error
+	Delete(room *Room) error
+
+	AddImage(image *RoomImage) error
+	Image(roomID, imageID uint) (RoomImage, error)
+	DeleteImage(image *RoomImage) error
+
+	IsFavorite(userID, roomID uint) (bool, error)
+	AddFavorite(favorite *Favorite) error
+	Favorites(userID uint) ([]Room, error)
+	// RemoveFavorite убирает номер из избранного; false, если его там не было
+	RemoveFavorite(userID, roomID uint) (bool, error)
+}
+
+// RatingRepo — оценки отелей и номеров
+type RatingRepo interface {
+	HotelRatings(hotelID uint) ([]HotelRating, error)
+	RoomRatings(roomID uint) ([]RoomRating, error)
+	HasRatedHotel(userID, hotelID uint) (bool, error)
+	HasRatedRoom(userID, roomID uint) (bool, error)
+	// RateHotel сохраняет оценку и пересчитывает средний рейтинг отеля
+	RateHotel(hotel *Hotel, rating *HotelRating) error
+	// RateRoom сохраняет оценку и пересчитывает средний рейтинг номера
+	RateRoom(room *Room, rating *RoomRating) error
+}
+
+// activeBookingStatuses повторяет bookings.ActiveStatuses: пакет bookings зависит от hotels
+var activeBookingStatuses = []string{"pending_payment", "confirmed", "checked_in"}
+
+// GormHotelRepo хранит отели и штат в базе данных
+type GormHotelRepo struct {
+	db *gorm.DB
+}
+
+func NewGormHotelRepo(db *gorm.DB) *GormHotelRepo {
+	return &GormHotelRepo{db: db}
+}
+
+func (r *GormHotelRepo) ByID(id uint) (Hotel, error) {
+	var hotel Hotel
+	err := r.db.First(&hotel, id).Error
+	return hotel, err
+}
+
+func (r *GormHotelRepo) List() ([]Hotel, error) {
+	var hotels []Hotel
+	err := r.db.Preload("Rooms").Find(&hotels).Error
+	return hotels, err
+}
+
+func (r *GormHotelRepo) ByOwner(ownerID uint) ([]Hotel, error) {
+	var hotels []Hotel
+	err := r.db.Where("owner_id = ?", ownerID).Find(&hotels).Error
+	return hotels, err
+}
+
+func (r *GormHotelRepo) Create(hotel *Hotel) error {
+	return r.db.Create(hotel).Error
+}
+
+func (r *GormHotelRepo) IsStaff(hotelID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&HotelStaff{}).Where("hotel_id = ? AND user_id = ?", hotelID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *GormHotelRepo) ManagedIDs(userID uint) ([]uint, error) {
+	staff := r.db.Model(&HotelStaff{}).Select("hotel_id").Where("user_id = ?", userID)
+	var ids []uint
+	err := r.db.Model(&Hotel{}).Where("owner_id = ? OR id IN (?)", userID, staff).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *GormHotelRepo) Staff(hotelID uint) ([]HotelStaff, error) {
+	var staff []HotelStaff
+	err := r.db.Where("hotel_id = ?", hotelID).Order("id").Find(&staff).Error
+	return staff, err
+}
+
+func (r *GormHotelRepo) AddStaff(staff *HotelStaff) error {
+	return r.db.Create(staff).Error
+}
+
+func (r *GormHotelRepo) RemoveStaff(hotelID, userID uint) (bool, error) {
+	result := r.db.Where("hotel_id = ? AND user_id = ?", hotelID, userID).Delete(&HotelStaff{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GormRoomRepo хранит номера, фотографии и избранное в базе данных
+type GormRoomRepo struct {
+	db *gorm.DB
+}
+
+func NewGormRoomRepo(db *gorm.DB) *GormRoomRepo {
+	return &GormRoomRepo{db: db}
+}
+
+func (r *GormRoomRepo) ByID(id uint) (Room, error) {
+	var room Room
+	err := r.db.First(&room, id).Error
+	return room, err
+}
+
+func (r *GormRoomRepo) ByIDUnscoped(id uint) (Room, error) {
+	var room Room
+	err := r.db.Unscoped().First(&room, id).Error
+	return room, err
+}
+
+func (r *GormRoomRepo) Search(filter RoomFilter) ([]Room, error) {
+	query := r.db.Preload("Images")
+
+	if filter.StartDate != nil && filter.EndDate != nil {
+		start := filter.StartDate.Format("2006-01-02")
+		end := filter.EndDate.Format("2006-01-02")
+		// Тип номера доступен, если в каждую ночь периода занято меньше Units номеров.
+		// Номер занимают только активные бронирования (см. bookings.ActiveStatuses)
+		query = query.Where(`rooms.units > (
+			SELECT COALESCE(MAX(occupied), 0) FROM (
+				SELECT COUNT(b.id) AS occupied
+				FROM generate_series(?::date, GREATEST(?::date - 1, ?::date), interval '1 day') AS night
+				JOIN bookings b ON b.room_id = rooms.id
+					AND b.deleted_at IS NULL
+					AND b.status IN ?
+					AND b.start_date::date <= night::date
+					AND b.end_date::date > night::date
+				GROUP BY night
+			) AS nights
+		)`, start, end, start, activeBookingStatuses)
+	}
+	if filter.HotelID != 0 {
+		query = query.Where("hotel_id = ?", filter.HotelID)
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("price <= ?", *filter.MaxPrice)
+	}
+	if filter.Capacity > 0 {
+		query = query.Where("capacity >= ?", filter.Capacity)
+	}
+
+	var rooms []Room
+	err := query.Find(&rooms).Error
+	return rooms, err
+}
+
+func (r *GormRoomRepo) ByHotels(hotelIDs []uint) ([]Room, error) {
+	var rooms []Room
+	if len(hotelIDs) == 0 {
+		return rooms, nil
+	}
+	err := r.db.Where("hotel_id IN ?", hotelIDs).Find(&rooms).Error
+	return rooms, err
+}
+
+func (r *GormRoomRepo) Create(room *Room) error {
+	return r.db.Create(room).Error
+}
+
+func (r *GormRoomRepo) Update(room *Room, changes Room) error {
+	return r.db.Model(room).Updates(changes).Error
+}
+
+func (r *GormRoomRepo) Delete(room *Room) error {
+	return r.db.Delete(room).Error
+}
+
+func (r *GormRoomRepo) AddImage(image *RoomImage) error {
+	return r.db.Create(image).Error
+}
+
+func (r *GormRoomRepo) Image(roomID, imageID uint) (RoomImage, error) {
+	var image RoomImage
+	err := r.db.Where("id = ? AND room_id = ?", imageID, roomID).First(&image).Error
+	return image, err
+}
+
+func (r *GormRoomRepo) DeleteImage(image *RoomImage) error {
+	return r.db.Delete(image).Error
+}
+
+func (r *GormRoomRepo) IsFavorite(userID, roomID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&Favorite{}).Where("user_id = ? AND room_id = ?", userID, roomID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *GormRoomRepo) AddFavorite(favorite *Favorite) error {
+	return r.db.Create(favorite).Error
+}
+
+func (r *GormRoomRepo) Favorites(userID uint) ([]Room, error) {
+	var rooms []Room
+	err := r.db.Joins("JOIN favorites ON rooms.id = favorites.room_id").
+		Where("favorites.user_id = ? AND favorites.deleted_at IS NULL", userID).
+		Find(&rooms).Error
+	return rooms, err
+}
+
+func (r *GormRoomRepo) RemoveFavorite(userID, roomID uint) (bool, error) {
+	result := r.db.Where("user_id = ? AND room_id = ?", userID, roomID).Delete(&Favorite{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GormRatingRepo хранит оценки в базе данных
+type GormRatingRepo struct {
+	db *gorm.DB
+}
+
+func NewGormRatingRepo(db *gorm.DB) *GormRatingRepo {
+	return &GormRatingRepo{db: db}
+}
+
+func (r *GormRatingRepo) HotelRatings(hotelID uint) ([]HotelRating, error) {
+	var ratings []HotelRating
+	err := r.db.Where("hotel_id = ?", hotelID).Find(&ratings).Error
+	return ratings, err
+}
+
+func (r *GormRatingRepo) RoomRatings(roomID uint) ([]RoomRating, error) {
+	var ratings []RoomRating
+	err := r.db.Where("room_id = ?", roomID).Find(&ratings).Error
+	return ratings, err
+}
+
+func (r *GormRatingRepo) HasRatedHotel(userID, hotelID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&HotelRating{}).Where("user_id = ? AND hotel_id = ?", userID, hotelID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *GormRatingRepo) HasRatedRoom(userID, roomID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&RoomRating{}).Where("user_id = ? AND room_id = ?", userID, roomID).Count(&count).Error
+	return count > 0, err
+}
+
+// ratingUpdates пересчитывает средний рейтинг в базе, не опираясь на прочитанные ранее значения
+func ratingUpdates(rating float64) map[string]interface{} {
+	return map[string]interface{}{
+		"average_rating": gorm.Expr("(average_rating * ratings_count + ?) / (ratings_count + 1)", rating),
+		"ratings_count":  gorm.Expr("ratings_count + 1"),
+	}
+}
+
+func (r *GormRatingRepo) RateHotel(hotel *Hotel, rating *HotelRating) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(rating).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(hotel).Updates(ratingUpdates(rating.Rating)).Error; err != nil {
+			return err
+		}
+		return tx.Select("average_rating", "ratings_count").First(hotel, hotel.ID).Error
+	})
+}
+
+func (r *GormRatingRepo) RateRoom(room *Room, rating *RoomRating) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(rating).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(room).Updates(ratingUpdates(rating.Rating)).Error; err != nil {
+			return err
+		}
+		return tx.Select("average_rating", "ratings_count").First(room, room.ID).Error
+	})
+}
@@ -0,0 +1,235 @@
+package hotels
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Роли сотрудников в штате отеля
+const (
+	StaffRoleManager = "manager"
+)
+
+var staffRoles = map[string]bool{StaffRoleManager: true}
+
+// HotelStaff — назначение пользователя в штат отеля. Менеджер работает
+// только с бронированиями, номерами и фото отелей, в штат которых он назначен.
+type HotelStaff struct {
+	ID        uint   `gorm:"primarykey"`
+	HotelID   uint   `gorm:"not null;uniqueIndex:idx_hotel_staff_hotel_user"` // ID отеля
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_hotel_staff_hotel_user;index"`
+	Role      string `gorm:"type:varchar(20);not null;default:'manager'"` // Роль в отеле
+	CreatedAt time.Time
+}
+
+type AddHotelStaffInput struct {
+	UserID uint   `json:"user_id" binding:"required"`
+	Role   string `json:"role" binding:"omitempty" enums:"manager"` // Роль в отеле, по умолчанию manager
+}
+
+// CanManageHotel проверяет, что пользователь — владелец отеля или состоит в его штате
+func CanManageHotel(hotels HotelRepo, hotel Hotel, userID uint) (bool, error) {
+	if hotel.OwnerID == userID {
+		return true, nil
+	}
+	return hotels.IsStaff(hotel.ID, userID)
+}
+
+// hotel загружает отель по ID из параметра пути
+func (h *Handler) hotel(param string) (Hotel, error) {
+	id, err := parseID(param)
+	if err != nil {
+		return Hotel{}, err
+	}
+	return h.Hotels.ByID(id)
+}
+
+// room загружает номер по ID из параметра пути
+func (h *Handler) room(param string) (Room, error) {
+	id, err := parseID(param)
+	if err != nil {
+		return Room{}, err
+	}
+	return h.Rooms.ByID(id)
+}
+
+// managedHotel загружает отель и проверяет, что текущий пользователь может им управлять.
+// При ошибке сам отвечает клиенту и возвращает false.
+func (h *Handler) managedHotel(c *gin.Context, hotelID uint) (Hotel, bool) {
+	hotel, err := h.Hotels.ByID(hotelID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Отель не найден"})
+		return Hotel{}, false
+	}
+
+	allowed, err := CanManageHotel(h.Hotels, hotel, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке доступа"})
+		return Hotel{}, false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return Hotel{}, false
+	}
+	return hotel, true
+}
+
+// managedRoom загружает номер из параметра id и проверяет доступ к его отелю
+func (h *Handler) managedRoom(c *gin.Context) (Room, bool) {
+	room, err := h.room(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
+		return Room{}, false
+	}
+	if _, ok := h.managedHotel(c, room.HotelID); !ok {
+		return Room{}, false
+	}
+	return room, true
+}
+
+// staffHotel загружает отель из параметра id и проверяет, что текущий пользователь его владелец
+func (h *Handler) staffHotel(c *gin.Context) (Hotel, bool) {
+	hotel, err := h.hotel(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Отель не найден"})
+		return Hotel{}, false
+	}
+	if hotel.OwnerID != c.GetUint("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Отель не принадлежит вам"})
+		return Hotel{}, false
+	}
+	return hotel, true
+}
+
+// @Security BearerAuth
+// AddHotelStaffHandler godoc
+// @Summary Назначение сотрудника отеля
+// @Description Добавляет пользователя с ролью manager в штат отеля. Доступно только владельцу отеля.
+// @Tags staff
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отеля"
+// @Param input body AddHotelStaffInput true "Сотрудник и его роль"
+// @Success 201 {object} response.HotelStaffResponse "Сотрудник назначен"
+// @Failure 400 {object} response.ErrorResponse "Ошибка валидации или пользователь не менеджер"
+// @Failure 403 {object} response.ErrorResponse "Отель не принадлежит вам"
+// @Failure 404 {object} response.ErrorResponse "Отель или пользователь не найден"
+// @Failure 409 {object} response.ErrorResponse "Пользователь уже в штате отеля"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при назначении сотрудника"
+// @Router /owners/hotels/{id}/staff [post]
+func (h *Handler) AddHotelStaffHandler(c *gin.Context) {
+	hotel, ok := h.staffHotel(c)
+	if !ok {
+		return
+	}
+
+	var input AddHotelStaffInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Role == "" {
+		input.Role = StaffRoleManager
+	}
+	if !staffRoles[input.Role] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверная роль сотрудника"})
+		return
+	}
+
+	user, err := h.Users.ByID(input.UserID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+		return
+	}
+	if user.Role != "manager" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "В штат можно назначить только пользователя с ролью manager"})
+		return
+	}
+
+	exists, err := h.Hotels.IsStaff(hotel.ID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при назначении сотрудника"})
+		return
+	}
+	if exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "Пользователь уже в штате отеля"})
+		return
+	}
+
+	staff := HotelStaff{HotelID: hotel.ID, UserID: user.ID, Role: input.Role}
+	if err := h.Hotels.AddStaff(&staff); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при назначении сотрудника"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, staff)
+}
+
+// @Security BearerAuth
+// GetHotelStaffHandler godoc
+// @Summary Штат отеля
+// @Description Возвращает сотрудников отеля. Доступно только владельцу отеля.
+// @Tags staff
+// @Produce json
+// @Param id path int true "ID отеля"
+// @Success 200 {array} response.HotelStaffResponse "Сотрудники отеля"
+// @Failure 403 {object} response.ErrorResponse "Отель не принадлежит вам"
+// @Failure 404 {object} response.ErrorResponse "Отель не найден"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при получении сотрудников"
+// @Router /owners/hotels/{id}/staff [get]
+func (h *Handler) GetHotelStaffHandler(c *gin.Context) {
+	hotel, ok := h.staffHotel(c)
+	if !ok {
+		return
+	}
+
+	staff, err := h.Hotels.Staff(hotel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении сотрудников"})
+		return
+	}
+
+	c.JSON(http.StatusOK, staff)
+}
+
+// @Security BearerAuth
+// RemoveHotelStaffHandler godoc
+// @Summary Удаление сотрудника отеля
+// @Description Исключает пользователя из штата отеля. Доступно только владельцу отеля.
+// @Tags staff
+// @Produce json
+// @Param id path int true "ID отеля"
+// @Param user_id path int true "ID пользователя"
+// @Success 200 {object} response.MessageResponse "Сотрудник удален из штата"
+// @Failure 400 {object} response.ErrorResponse "Неверный ID пользователя"
+// @Failure 403 {object} response.ErrorResponse "Отель не принадлежит вам"
+// @Failure 404 {object} response.ErrorResponse "Отель или сотрудник не найден"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при удалении сотрудника"
+// @Router /owners/hotels/{id}/staff/{user_id} [delete]
+func (h *Handler) RemoveHotelStaffHandler(c *gin.Context) {
+	hotel, ok := h.staffHotel(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID пользователя"})
+		return
+	}
+
+	removed, err := h.Hotels.RemoveStaff(hotel.ID, uint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении сотрудника"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Сотрудник не найден"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Сотрудник удален из штата"})
+}
@@ -1,31 +1,80 @@
 package hotels
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"hotel-booking/internal/logging"
+	"log/slog"
 	"path"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/studio-b12/gowebdav"
 )
 
+// WebDAVConfig — облачное хранилище изображений номеров
+type WebDAVConfig struct {
+	Endpoint  string // Адрес WebDAV-сервера
+	Username  string
+	Password  string
+	PublicURL string // Публичная ссылка на папку с изображениями
+}
+
+var (
+	webdavMu     sync.RWMutex
+	webdavConfig WebDAVConfig
+)
+
+// SetWebDAVConfig задаёт хранилище, в которое загружаются изображения номеров
+func SetWebDAVConfig(cfg WebDAVConfig) {
+	webdavMu.Lock()
+	defer webdavMu.Unlock()
+	webdavConfig = cfg
+}
+
+func getWebDAVConfig() WebDAVConfig {
+	webdavMu.RLock()
+	defer webdavMu.RUnlock()
+	return webdavConfig
+}
+
+// CheckStorage проверяет подключение к облачному хранилищу изображений
+func CheckStorage(ctx context.Context) error {
+	cfg := getWebDAVConfig()
+	if cfg.Endpoint == "" || cfg.Username == "" {
+		return errors.New("облачное хранилище не настроено")
+	}
+	client := gowebdav.NewClient(cfg.Endpoint, cfg.Username, cfg.Password)
+	client.SetTransport(&logging.Transport{})
+	if deadline, ok := ctx.Deadline(); ok {
+		client.SetTimeout(time.Until(deadline))
+	}
+	return client.Connect()
+}
+
 type WebDAVService struct {
 	client  *gowebdav.Client
 	baseURL string
 }
 
-func NewWebDAVService(url, user, password string) *WebDAVService {
-	client := gowebdav.NewClient("https://webdav.cloud.mail.ru", user, password)
+func NewWebDAVService(cfg WebDAVConfig) *WebDAVService {
+	if cfg.Endpoint == "" || cfg.Username == "" {
+		slog.Warn("Облачное хранилище не настроено")
+		return nil
+	}
+	client := gowebdav.NewClient(cfg.Endpoint, cfg.Username, cfg.Password)
+	client.SetTransport(&logging.Transport{})
 	err := client.Connect()
 	if err != nil {
-		log.Printf("Ошибка подключения к Mail.ru Cloud: %v", err)
+		slog.Error("Ошибка подключения к облачному хранилищу", "endpoint", cfg.Endpoint, logging.Err(err))
 		return nil
 	}
 	return &WebDAVService{
 		client:  client,
-		baseURL: os.Getenv("WEVDAV_URL"), // Вставьте сюда хэш из публичной ссылки
+		baseURL: strings.TrimRight(cfg.PublicURL, "/"),
 	}
 }
 
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Config — формат и уровень журнала
+type Config struct {
+	Level  string // debug, info, warn или error
+	Format string // json или text
+}
+
+// New создаёт журнал, который пишет в w. К каждой записи с контекстом запроса
+// добавляется request_id, секреты и персональные данные маскируются.
+func New(w io.Writer, cfg Config) (*slog.Logger, error) {
+	var level slog.Level
+	if cfg.Level == "" {
+		cfg.Level = "info"
+	}
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("неизвестный уровень журнала %q", cfg.Level)
+	}
+
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redact}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("неизвестный формат журнала %q", cfg.Format)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+
+// Setup делает журнал журналом по умолчанию для slog и стандартного log
+func Setup(w io.Writer, cfg Config) error {
+	logger, err := New(w, cfg)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// contextHandler добавляет к записи request_id из контекста
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Err — атрибут с ошибкой
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.String("error", "")
+	}
+	return slog.String("error", err.Error())
+}
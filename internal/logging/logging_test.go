@@ -0,0 +1,154 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// captureDefault подменяет журнал по умолчанию на JSON в буфер до конца теста
+func captureDefault(t *testing.T, level string) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{Level: level, Format: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+// entries разбирает записи журнала в формате JSON
+func entries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("запись журнала %q: %v", line, err)
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+func TestNewRejectsUnknownSettings(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, Config{Level: "verbose"}); err == nil {
+		t.Error("неизвестный уровень: ожидалась ошибка")
+	}
+	if _, err := New(&bytes.Buffer{}, Config{Format: "xml"}); err == nil {
+		t.Error("неизвестный формат: ожидалась ошибка")
+	}
+}
+
+func TestRedactsSecretsAndPersonalData(t *testing.T) {
+	buf := captureDefault(t, "info")
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	slog.InfoContext(ctx, "вход", "password", "hunter2", "email", "ivan@example.com", "phone", "+79991234567", "user_id", 7)
+
+	logged := entries(t, buf)
+	if len(logged) != 1 {
+		t.Fatalf("записей: %d, ожидалась 1", len(logged))
+	}
+	entry := logged[0]
+	if entry["password"] != redacted {
+		t.Errorf("пароль попал в журнал: %v", entry["password"])
+	}
+	if entry["email"] != "i***@example.com" {
+		t.Errorf("почта: %v", entry["email"])
+	}
+	if entry["phone"] != "***4567" {
+		t.Errorf("телефон: %v", entry["phone"])
+	}
+	if entry["request_id"] != "req-1" {
+		t.Errorf("request_id: %v", entry["request_id"])
+	}
+	if strings.Contains(buf.String(), "hunter2") || strings.Contains(buf.String(), "ivan@") {
+		t.Errorf("в журнале остались секреты: %s", buf.String())
+	}
+}
+
+func TestMiddlewarePropagatesRequestID(t *testing.T) {
+	buf := captureDefault(t, "info")
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware())
+	var seen string
+	r.GET("/items/:id", func(c *gin.Context) {
+		seen = RequestID(c.Request.Context())
+		c.Status(http.StatusNotFound)
+	})
+
+	// ID клиента сохраняется
+	req := httptest.NewRequest(http.MethodGet, "/items/5?token=secret", nil)
+	req.Header.Set(HeaderRequestID, "client-id")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if seen != "client-id" || w.Header().Get(HeaderRequestID) != "client-id" {
+		t.Fatalf("ID запроса: в обработчике %q, в ответе %q", seen, w.Header().Get(HeaderRequestID))
+	}
+
+	// Недопустимый ID заменяется новым
+	req = httptest.NewRequest(http.MethodGet, "/items/5", nil)
+	req.Header.Set(HeaderRequestID, "bad id\n")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get(HeaderRequestID); got == "" || got == "bad id\n" || got != seen {
+		t.Fatalf("новый ID запроса: %q, в обработчике %q", got, seen)
+	}
+
+	logged := entries(t, buf)
+	if len(logged) != 2 {
+		t.Fatalf("записей: %d, ожидалось 2", len(logged))
+	}
+	entry := logged[0]
+	if entry["request_id"] != "client-id" || entry["route"] != "/items/:id" || entry["status"] != float64(http.StatusNotFound) || entry["level"] != "WARN" {
+		t.Errorf("запись о запросе: %v", entry)
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("строка запроса попала в журнал: %s", buf.String())
+	}
+}
+
+func TestTransportForwardsRequestID(t *testing.T) {
+	captureDefault(t, "debug")
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(HeaderRequestID)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{}}
+	ctx := WithRequestID(context.Background(), "req-42")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got != "req-42" {
+		t.Errorf("%s во внешнем запросе: %q", HeaderRequestID, got)
+	}
+	if req.Header.Get(HeaderRequestID) != "" {
+		t.Error("исходный запрос изменён")
+	}
+}
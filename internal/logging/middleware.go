@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware присваивает запросу ID (из X-Request-ID или новый), возвращает его в ответе,
+// кладёт в контекст запроса и пишет в журнал итог запроса. Строка запроса не
+// журналируется: в ней бывают токены подтверждения почты.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HeaderRequestID)
+		if !validRequestID.MatchString(id) {
+			id = NewRequestID()
+		}
+		c.Header(HeaderRequestID, id)
+		c.Set("request_id", id)
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), id))
+
+		started := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Int64("duration_ms", time.Since(started).Milliseconds()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if userID := c.GetUint("user_id"); userID != 0 {
+			attrs = append(attrs, slog.Uint64("user_id", uint64(userID)))
+		}
+		slog.LogAttrs(c.Request.Context(), level, "HTTP-запрос", attrs...)
+	}
+}
+
+// Recovery отвечает 500 на панику в обработчике и пишет её в журнал вместо stderr
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, err interface{}) {
+		slog.ErrorContext(c.Request.Context(), "Паника при обработке запроса", "panic", err, "path", c.Request.URL.Path)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+	})
+}
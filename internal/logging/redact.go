@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"log/slog"
+	"strings"
+)
+
+const redacted = "[скрыто]"
+
+// secretKeys — атрибуты, значение которых не попадает в журнал
+var secretKeys = map[string]bool{
+	"password":           true,
+	"token":              true,
+	"access_token":       true,
+	"refresh_token":      true,
+	"verification_token": true,
+	"secret":             true,
+	"secret_key":         true,
+	"authorization":      true,
+	"cookie":             true,
+	"dsn":                true,
+	"body":               true,
+	"payload":            true,
+}
+
+// redact маскирует секреты и персональные данные: адреса почты и телефоны
+// остаются узнаваемыми для поддержки, но не раскрываются целиком
+func redact(groups []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	switch {
+	case secretKeys[key]:
+		return slog.String(a.Key, redacted)
+	case key == "email" || key == "recipient" || key == "to":
+		return slog.String(a.Key, MaskEmail(a.Value.String()))
+	case key == "phone":
+		return slog.String(a.Key, MaskPhone(a.Value.String()))
+	}
+	return a
+}
+
+// MaskEmail оставляет первую букву имени и домен: i***@example.com
+func MaskEmail(address string) string {
+	at := strings.LastIndex(address, "@")
+	if at <= 0 {
+		return redacted
+	}
+	return address[:1] + "***" + address[at:]
+}
+
+// MaskPhone оставляет последние четыре цифры: ***4567
+func MaskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return redacted
+	}
+	return "***" + phone[len(phone)-4:]
+}
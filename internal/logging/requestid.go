@@ -0,0 +1,36 @@
+package logging
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID — заголовок, в котором ID запроса приходит от клиента или прокси
+// и передаётся во внешние сервисы
+const HeaderRequestID = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// validRequestID ограничивает ID, пришедший снаружи: он попадает в журнал и заголовки ответа
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// NewRequestID создаёт новый ID запроса
+func NewRequestID() string {
+	return uuid.New().String()
+}
+
+// WithRequestID возвращает контекст с ID запроса id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID возвращает ID запроса из контекста или пустую строку
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Transport передаёт ID запроса из контекста во внешний сервис заголовком X-Request-ID
+// и пишет в журнал каждый исходящий запрос. Тела запросов и ответов не журналируются.
+type Transport struct {
+	Base http.RoundTripper // По умолчанию http.DefaultTransport
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if id := RequestID(req.Context()); id != "" && req.Header.Get(HeaderRequestID) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(HeaderRequestID, id)
+	}
+
+	started := time.Now()
+	resp, err := base.RoundTrip(req)
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("host", req.URL.Host),
+		slog.String("path", req.URL.Path),
+		slog.Int64("duration_ms", time.Since(started).Milliseconds()),
+	}
+	if err != nil {
+		slog.LogAttrs(req.Context(), slog.LevelWarn, "Ошибка исходящего запроса", append(attrs, Err(err))...)
+		return nil, err
+	}
+	slog.LogAttrs(req.Context(), slog.LevelDebug, "Исходящий запрос", append(attrs, slog.Int("status", resp.StatusCode))...)
+	return resp, nil
+}
@@ -0,0 +1,237 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"hotel-booking/internal/bookings"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/testdb"
+	"hotel-booking/internal/users"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Модели в том виде, в каком их создавал AutoMigrate до перехода на миграции.
+// Такая схема стоит на всех развёрнутых базах.
+
+type baselineUser struct {
+	gorm.Model
+	Name               string `gorm:"type:varchar(100);not null"`
+	Email              string `gorm:"type:varchar(100);unique;not null"`
+	Password           string `gorm:"not null"`
+	Phone              string `gorm:"type:varchar(15);unique;not null"`
+	Role               string `gorm:"type:varchar(20);default:'client'"`
+	ResetPasswordToken string `gorm:"type:varchar(255)"`
+	ResetTokenExpiry   *time.Time
+	IsVerified         bool   `gorm:"default:false"`
+	VerificationToken  string `gorm:"type:varchar(255)"`
+}
+
+func (baselineUser) TableName() string { return "users" }
+
+type baselineHotel struct {
+	gorm.Model
+	Name          string  `gorm:"type:varchar(100);not null"`
+	Address       string  `gorm:"type:varchar(255);not null"`
+	Description   string  `gorm:"type:text"`
+	OwnerID       uint    `gorm:"not null"`
+	AverageRating float64 `gorm:"default:0"`
+	RatingsCount  int     `gorm:"default:0"`
+}
+
+func (baselineHotel) TableName() string { return "hotels" }
+
+type baselineRoom struct {
+	gorm.Model
+	HotelID       uint    `gorm:"not null"`
+	RoomType      string  `gorm:"type:varchar(50);not null"`
+	Price         float64 `gorm:"not null"`
+	Amenities     string  `gorm:"type:text"`
+	Capacity      int     `gorm:"not null"`
+	Available     bool    `gorm:"default:true"`
+	AverageRating float64 `gorm:"default:0"`
+	RatingsCount  int     `gorm:"default:0"`
+}
+
+func (baselineRoom) TableName() string { return "rooms" }
+
+type baselineBooking struct {
+	gorm.Model
+	RoomID           uint      `gorm:"not null"`
+	UserID           uint      `gorm:"not null"`
+	StartDate        time.Time `gorm:"not null"`
+	EndDate          time.Time `gorm:"not null"`
+	TotalCost        float64   `gorm:"not null"`
+	PaymentStatus    string    `gorm:"type:varchar(20);default:'pending'"`
+	PaymentID        string    `gorm:"type:varchar(50)"`
+	IsOfflineBooking bool      `gorm:"default:false"`
+}
+
+func (baselineBooking) TableName() string { return "bookings" }
+
+func TestMain(m *testing.M) {
+	os.Exit(testdb.Run(m))
+}
+
+var errRollback = errors.New("откат тестовой схемы")
+
+// inBaselineSchema выполняет fn в транзакции с пустой схемой, в которой AutoMigrate создал
+// таблицы исходной версии. Транзакция откатывается, схема после теста не остаётся.
+func inBaselineSchema(t *testing.T, fn func(tx *gorm.DB)) {
+	t.Helper()
+
+	db := testdb.Open(t)
+	schema := fmt.Sprintf("baseline_%d", time.Now().UnixNano())
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("SET LOCAL search_path TO " + schema).Error; err != nil {
+			return err
+		}
+		if err := tx.AutoMigrate(&baselineUser{}, &baselineHotel{}, &baselineRoom{}, &baselineBooking{}); err != nil {
+			return err
+		}
+		fn(tx)
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("исходная схема: %v", err)
+	}
+}
+
+// upgrade применяет все миграции к исходной схеме в tx
+func upgrade(t *testing.T, tx *gorm.DB) {
+	t.Helper()
+
+	migrations, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator := &Migrator{db: tx, migrations: migrations}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("миграция исходной схемы: %v", err)
+	}
+	if err := migrator.Check(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpgradeBaselineSchema(t *testing.T) {
+	inBaselineSchema(t, func(tx *gorm.DB) {
+		user := baselineUser{Name: "Гость", Email: "guest@example.com", Password: "hash", Phone: "+70000000001"}
+		if err := tx.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		hotel := baselineHotel{Name: "Отель", Address: "Адрес", OwnerID: user.ID}
+		if err := tx.Create(&hotel).Error; err != nil {
+			t.Fatal(err)
+		}
+		room := baselineRoom{HotelID: hotel.ID, RoomType: "standard", Price: 1000, Capacity: 2}
+		if err := tx.Create(&room).Error; err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now().AddDate(0, 0, 10)
+		booking := baselineBooking{RoomID: room.ID, UserID: user.ID, StartDate: start, EndDate: start.AddDate(0, 0, 2), TotalCost: 2000}
+		if err := tx.Create(&booking).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		upgrade(t, tx)
+
+		// Старые строки читаются текущими моделями, новые столбцы получили значения по умолчанию
+		var upgradedUser users.User
+		if err := tx.First(&upgradedUser, user.ID).Error; err != nil || upgradedUser.Language != "ru" || upgradedUser.TokenVersion != 0 {
+			t.Errorf("пользователь после миграции: %+v, %v", upgradedUser, err)
+		}
+		var upgradedHotel hotels.Hotel
+		if err := tx.First(&upgradedHotel, hotel.ID).Error; err != nil || upgradedHotel.TaxPercent != 0 {
+			t.Errorf("отель после миграции: %+v, %v", upgradedHotel, err)
+		}
+		var upgradedRoom hotels.Room
+		if err := tx.First(&upgradedRoom, room.ID).Error; err != nil || upgradedRoom.Units != 1 {
+			t.Errorf("номер после миграции: %+v, %v", upgradedRoom, err)
+		}
+		var upgradedBooking bookings.Booking
+		if err := tx.First(&upgradedBooking, booking.ID).Error; err != nil || upgradedBooking.Adults != 1 || upgradedBooking.ExpiresAt != nil {
+			t.Errorf("бронирование после миграции: %+v, %v", upgradedBooking, err)
+		}
+	})
+}
+
+func TestUpgradeBaselineBookingStatuses(t *testing.T) {
+	inBaselineSchema(t, func(tx *gorm.DB) {
+		start := time.Now().AddDate(0, 0, 10)
+		create := func(paymentStatus string, offline, deleted bool) uint {
+			t.Helper()
+			booking := baselineBooking{RoomID: 1, UserID: 1, StartDate: start, EndDate: start.AddDate(0, 0, 1),
+				TotalCost: 1000, PaymentStatus: paymentStatus, IsOfflineBooking: offline}
+			if err := tx.Create(&booking).Error; err != nil {
+				t.Fatal(err)
+			}
+			if deleted {
+				if err := tx.Delete(&booking).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			return booking.ID
+		}
+
+		want := map[uint]bookings.BookingStatus{
+			create("succeeded", false, false): bookings.StatusConfirmed,
+			create("refunded", false, true):   bookings.StatusRefunded,
+			create("pending", false, true):    bookings.StatusCancelled,
+			create("canceled", false, false):  bookings.StatusCancelled,
+			create("pending", true, false):    bookings.StatusConfirmed,
+			create("pending", false, false):   bookings.StatusPendingPayment,
+		}
+		upgrade(t, tx)
+
+		for id, status := range want {
+			var booking bookings.Booking
+			if err := tx.Unscoped().First(&booking, id).Error; err != nil {
+				t.Fatal(err)
+			}
+			if booking.Status != status {
+				t.Errorf("бронирование %d (%s, офлайн %v): статус %s, ожидался %s",
+					id, booking.PaymentStatus, booking.IsOfflineBooking, booking.Status, status)
+			}
+		}
+	})
+}
+
+func TestDowngradeKeepsBaselineData(t *testing.T) {
+	inBaselineSchema(t, func(tx *gorm.DB) {
+		user := baselineUser{Name: "Гость", Email: "guest@example.com", Password: "hash", Phone: "+70000000001"}
+		if err := tx.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		upgrade(t, tx)
+
+		migrations, err := Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		migrator := &Migrator{db: tx, migrations: migrations}
+		if _, err := migrator.Down(len(migrations)); err != nil {
+			t.Fatalf("откат всех миграций: %v", err)
+		}
+
+		// Исходные таблицы и строки остаются, новые таблицы и столбцы удалены
+		var restored baselineUser
+		if err := tx.First(&restored, user.ID).Error; err != nil || restored.Email != user.Email {
+			t.Fatalf("пользователь после отката: %+v, %v", restored, err)
+		}
+		for _, table := range []interface{}{&baselineUser{}, &baselineHotel{}, &baselineRoom{}, &baselineBooking{}} {
+			if !tx.Migrator().HasTable(table) {
+				t.Errorf("таблица %T удалена", table)
+			}
+		}
+		if tx.Migrator().HasTable("sessions") || tx.Migrator().HasColumn(&baselineUser{}, "token_version") {
+			t.Error("объекты, добавленные миграциями, остались после отката")
+		}
+	})
+}
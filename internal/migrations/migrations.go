@@ -0,0 +1,253 @@
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// Файлы миграций называются NNNN_название.up.sql и NNNN_название.down.sql
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// lockID — ключ advisory-блокировки, чтобы миграции не выполнялись одновременно из двух процессов
+const lockID = 20250601
+
+var ErrPending = errors.New("в базе применены не все миграции")
+
+// Migration — шаг изменения схемы со скриптами применения и отката
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// SchemaMigration — запись о применённой миграции
+type SchemaMigration struct {
+	Version   int       `gorm:"primarykey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status — миграция и время её применения; AppliedAt пуст, если миграция не применена
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Load читает встроенные в бинарник миграции в порядке версий.
+// У каждой версии должны быть оба файла, up и down.
+func Load() ([]Migration, error) {
+	return load(files)
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.Glob(fsys, "sql/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, path := range entries {
+		name := strings.TrimPrefix(path, "sql/")
+		m := fileName.FindStringSubmatch(name)
+		if m == nil {
+			return nil, fmt.Errorf("неверное имя файла миграции %s", name)
+		}
+		version, _ := strconv.Atoi(m[1])
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != m[2] {
+			return nil, fmt.Errorf("у миграции %d два названия: %s и %s", version, migration.Name, m[2])
+		}
+		if m[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("у миграции %04d_%s нет файла up или down", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator применяет и откатывает миграции. Каждая миграция выполняется
+// в отдельной транзакции вместе с записью в schema_migrations.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func (m *Migrator) ensureTable() error {
+	return m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name varchar(255) NOT NULL,
+		applied_at timestamptz NOT NULL
+	)`).Error
+}
+
+func applied(db *gorm.DB) (map[int]SchemaMigration, error) {
+	var rows []SchemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[int]SchemaMigration, len(rows))
+	for _, row := range rows {
+		result[row.Version] = row
+	}
+	return result, nil
+}
+
+// Up применяет все ещё не применённые миграции и возвращает их
+func (m *Migrator) Up() ([]Migration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		ran := false
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockID).Error; err != nil {
+				return err
+			}
+			// Пока ждали блокировку, миграцию мог применить другой процесс
+			var count int64
+			if err := tx.Model(&SchemaMigration{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return nil
+			}
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			ran = true
+			return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("миграция %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if ran {
+			done = append(done, migration)
+		}
+	}
+	return done, nil
+}
+
+// Down откатывает steps последних применённых миграций и возвращает их
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		ran := false
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockID).Error; err != nil {
+				return err
+			}
+			result := tx.Where("version = ?", migration.Version).Delete(&SchemaMigration{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return nil
+			}
+			ran = true
+			return tx.Exec(migration.Down).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("откат %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if ran {
+			done = append(done, migration)
+		}
+	}
+	return done, nil
+}
+
+// Status возвращает все известные миграции с отметкой о применении
+func (m *Migrator) Status() ([]Status, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	rows, err := applied(m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if row, ok := rows[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Check возвращает ErrPending, если в базе применены не все миграции из бинарника.
+// Таблицу schema_migrations не создаёт: сервер не должен менять схему сам.
+func (m *Migrator) Check() error {
+	if !m.db.Migrator().HasTable(&SchemaMigration{}) {
+		return fmt.Errorf("%w: таблица schema_migrations не найдена", ErrPending)
+	}
+	rows, err := applied(m.db)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, migration := range m.migrations {
+		if _, ok := rows[migration.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%04d_%s", migration.Version, migration.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %s", ErrPending, strings.Join(pending, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"hotel-booking/internal/auth"
+	"hotel-booking/internal/bookings"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/outbox"
+	"hotel-booking/internal/payments"
+	"hotel-booking/internal/pricing"
+	"hotel-booking/internal/users"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("версии идут не подряд: на месте %d версия %d", i+1, m.Version)
+		}
+	}
+}
+
+func TestLoadRejectsIncompleteMigrations(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"нет down": {
+			"sql/0001_init.up.sql": {Data: []byte("SELECT 1")},
+		},
+		"неверное имя": {
+			"sql/init.up.sql": {Data: []byte("SELECT 1")},
+		},
+		"разные названия": {
+			"sql/0001_init.up.sql":    {Data: []byte("SELECT 1")},
+			"sql/0001_other.down.sql": {Data: []byte("SELECT 1")},
+		},
+	}
+	for name, fsys := range cases {
+		if _, err := load(fsys); err == nil {
+			t.Errorf("%s: ожидалась ошибка", name)
+		}
+	}
+}
+
+// Каждая модель должна появиться в миграциях, иначе на новой базе её таблицы не будет
+func TestMigrationsCreateEveryModelTable(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var up strings.Builder
+	for _, m := range migrations {
+		up.WriteString(m.Up)
+	}
+
+	models := []interface{}{
+		&users.User{}, &auth.Session{}, &hotels.Hotel{}, &hotels.Room{}, &hotels.RoomImage{}, &hotels.Favorite{},
+		&hotels.HotelRating{}, &hotels.RoomRating{}, &hotels.HotelStaff{}, &pricing.PriceRule{}, &pricing.PriceOverride{},
+		&bookings.Booking{}, &bookings.BookingEvent{}, &bookings.CancellationPolicy{}, &payments.WebhookEvent{}, &outbox.Message{},
+	}
+	for _, model := range models {
+		s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(up.String(), "CREATE TABLE IF NOT EXISTS "+s.Table+" (") {
+			t.Errorf("нет миграции, создающей таблицу %s", s.Table)
+		}
+		for _, field := range s.DBNames {
+			if !strings.Contains(up.String(), "    "+field+" ") && !strings.Contains(up.String(), "ADD COLUMN "+field+" ") {
+				t.Errorf("в миграциях нет столбца %s.%s", s.Table, field)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+-- Откат возвращает схему, которую создавал AutoMigrate до перехода на миграции.
+-- Таблицы users, hotels, rooms, room_images, favorites, hotel_ratings, room_ratings и bookings
+-- 0001 на развёрнутых базах только принимает, поэтому они и их данные остаются;
+-- удаляются лишь добавленные в них столбцы и таблицы, которых в исходной схеме не было.
+
+DROP TABLE IF EXISTS outbox_messages;
+DROP TABLE IF EXISTS webhook_events;
+DROP TABLE IF EXISTS cancellation_policies;
+DROP TABLE IF EXISTS booking_events;
+DROP TABLE IF EXISTS price_overrides;
+DROP TABLE IF EXISTS price_rules;
+DROP TABLE IF EXISTS hotel_staffs;
+DROP TABLE IF EXISTS sessions;
+
+DROP INDEX IF EXISTS idx_bookings_expires_at;
+DROP INDEX IF EXISTS idx_bookings_status;
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS adults,
+    DROP COLUMN IF EXISTS children,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS refunded_amount,
+    DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE rooms
+    DROP COLUMN IF EXISTS base_occupancy,
+    DROP COLUMN IF EXISTS extra_guest_price,
+    DROP COLUMN IF EXISTS units;
+ALTER TABLE hotels
+    DROP COLUMN IF EXISTS tax_percent,
+    DROP COLUMN IF EXISTS service_fee;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS token_version,
+    DROP COLUMN IF EXISTS language;
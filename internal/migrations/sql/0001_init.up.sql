@@ -0,0 +1,251 @@
+-- Исходная схема. На новой базе создаёт все таблицы. На базе, которую создал AutoMigrate
+-- до перехода на миграции, таблицы users, hotels, rooms и bookings уже есть, но без
+-- столбцов, добавленных позже: CREATE TABLE IF NOT EXISTS их пропускает, поэтому
+-- недостающие столбцы добавляются отдельно через ADD COLUMN IF NOT EXISTS.
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name varchar(100) NOT NULL,
+    email varchar(100) NOT NULL,
+    password text NOT NULL,
+    phone varchar(15) NOT NULL,
+    role varchar(20) DEFAULT 'client',
+    reset_password_token varchar(255),
+    reset_token_expiry timestamptz,
+    is_verified boolean DEFAULT false,
+    verification_token varchar(255),
+    token_version bigint NOT NULL DEFAULT 0,
+    language varchar(5) NOT NULL DEFAULT 'ru',
+    CONSTRAINT uni_users_email UNIQUE (email),
+    CONSTRAINT uni_users_phone UNIQUE (phone)
+);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version bigint NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language varchar(5) NOT NULL DEFAULT 'ru';
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    replaced_by bigint,
+    user_agent varchar(255),
+    ip varchar(45),
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_token_hash ON sessions (token_hash);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS hotels (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name varchar(100) NOT NULL,
+    address varchar(255) NOT NULL,
+    description text,
+    owner_id bigint NOT NULL,
+    average_rating decimal DEFAULT 0,
+    ratings_count bigint DEFAULT 0,
+    tax_percent decimal DEFAULT 0,
+    service_fee decimal DEFAULT 0
+);
+ALTER TABLE hotels ADD COLUMN IF NOT EXISTS tax_percent decimal DEFAULT 0;
+ALTER TABLE hotels ADD COLUMN IF NOT EXISTS service_fee decimal DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_hotels_deleted_at ON hotels (deleted_at);
+
+CREATE TABLE IF NOT EXISTS rooms (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    hotel_id bigint NOT NULL,
+    room_type varchar(50) NOT NULL,
+    price decimal NOT NULL,
+    amenities text,
+    capacity bigint NOT NULL,
+    base_occupancy bigint DEFAULT 0,
+    extra_guest_price decimal DEFAULT 0,
+    units bigint NOT NULL DEFAULT 1,
+    available boolean DEFAULT true,
+    average_rating decimal DEFAULT 0,
+    ratings_count bigint DEFAULT 0,
+    CONSTRAINT fk_hotels_rooms FOREIGN KEY (hotel_id) REFERENCES hotels(id)
+);
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS base_occupancy bigint DEFAULT 0;
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS extra_guest_price decimal DEFAULT 0;
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS units bigint NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_rooms_deleted_at ON rooms (deleted_at);
+
+CREATE TABLE IF NOT EXISTS room_images (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    room_id bigint NOT NULL,
+    image_url varchar(255) NOT NULL,
+    image_name varchar(100) NOT NULL,
+    CONSTRAINT fk_rooms_images FOREIGN KEY (room_id) REFERENCES rooms(id)
+);
+CREATE INDEX IF NOT EXISTS idx_room_images_deleted_at ON room_images (deleted_at);
+
+CREATE TABLE IF NOT EXISTS favorites (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id bigint,
+    room_id bigint
+);
+CREATE INDEX IF NOT EXISTS idx_favorites_deleted_at ON favorites (deleted_at);
+
+CREATE TABLE IF NOT EXISTS hotel_ratings (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    hotel_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    rating decimal NOT NULL,
+    comment text,
+    CONSTRAINT fk_hotels_ratings FOREIGN KEY (hotel_id) REFERENCES hotels(id),
+    CONSTRAINT chk_hotel_ratings_rating CHECK (rating >= 1 AND rating <= 5)
+);
+CREATE INDEX IF NOT EXISTS idx_hotel_ratings_deleted_at ON hotel_ratings (deleted_at);
+
+CREATE TABLE IF NOT EXISTS room_ratings (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    room_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    rating decimal NOT NULL,
+    comment text,
+    CONSTRAINT fk_rooms_ratings FOREIGN KEY (room_id) REFERENCES rooms(id),
+    CONSTRAINT chk_room_ratings_rating CHECK (rating >= 1 AND rating <= 5)
+);
+CREATE INDEX IF NOT EXISTS idx_room_ratings_deleted_at ON room_ratings (deleted_at);
+
+CREATE TABLE IF NOT EXISTS hotel_staffs (
+    id bigserial PRIMARY KEY,
+    hotel_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    role varchar(20) NOT NULL DEFAULT 'manager',
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_hotel_staffs_user_id ON hotel_staffs (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_hotel_staff_hotel_user ON hotel_staffs (hotel_id,user_id);
+
+CREATE TABLE IF NOT EXISTS price_rules (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    room_id bigint NOT NULL,
+    name varchar(100),
+    start_date date,
+    end_date date,
+    days_of_week varchar(20),
+    price decimal NOT NULL,
+    min_stay bigint DEFAULT 0,
+    priority bigint DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_price_rules_room_id ON price_rules (room_id);
+CREATE INDEX IF NOT EXISTS idx_price_rules_deleted_at ON price_rules (deleted_at);
+
+CREATE TABLE IF NOT EXISTS price_overrides (
+    id bigserial PRIMARY KEY,
+    room_id bigint NOT NULL,
+    date date NOT NULL,
+    price decimal NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_overrides_room_date ON price_overrides (room_id,date);
+
+CREATE TABLE IF NOT EXISTS bookings (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    room_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    start_date timestamptz NOT NULL,
+    end_date timestamptz NOT NULL,
+    adults bigint NOT NULL DEFAULT 1,
+    children bigint NOT NULL DEFAULT 0,
+    total_cost decimal NOT NULL,
+    status varchar(20) DEFAULT 'pending_payment',
+    payment_status varchar(20) DEFAULT 'pending',
+    payment_id varchar(50),
+    refunded_amount decimal DEFAULT 0,
+    is_offline_booking boolean DEFAULT false,
+    expires_at timestamptz
+);
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS adults bigint NOT NULL DEFAULT 1;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS children bigint NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS status varchar(20) DEFAULT 'pending_payment';
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS refunded_amount decimal DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS expires_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_bookings_expires_at ON bookings (expires_at);
+CREATE INDEX IF NOT EXISTS idx_bookings_status ON bookings (status);
+CREATE INDEX IF NOT EXISTS idx_bookings_deleted_at ON bookings (deleted_at);
+
+CREATE TABLE IF NOT EXISTS booking_events (
+    id bigserial PRIMARY KEY,
+    booking_id bigint NOT NULL,
+    from_status varchar(20),
+    to_status varchar(20) NOT NULL,
+    actor_type varchar(20) NOT NULL,
+    actor_id bigint,
+    reason text,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_booking_events_booking_id ON booking_events (booking_id);
+
+CREATE TABLE IF NOT EXISTS cancellation_policies (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    hotel_id bigint NOT NULL,
+    room_id bigint NOT NULL DEFAULT 0,
+    free_until_days bigint NOT NULL DEFAULT 0,
+    penalty_percent decimal NOT NULL DEFAULT 0,
+    non_refundable boolean DEFAULT false
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cancellation_policies_scope ON cancellation_policies (hotel_id,room_id);
+CREATE INDEX IF NOT EXISTS idx_cancellation_policies_deleted_at ON cancellation_policies (deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_events (
+    id bigserial PRIMARY KEY,
+    event_id varchar(150) NOT NULL,
+    payment_id varchar(50) NOT NULL,
+    event varchar(50),
+    status varchar(20),
+    processed_at timestamptz NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_events_event_id ON webhook_events (event_id);
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id bigserial PRIMARY KEY,
+    kind varchar(20) NOT NULL,
+    recipient varchar(255) NOT NULL,
+    subject varchar(255) NOT NULL,
+    body text NOT NULL,
+    text_body text,
+    status varchar(20) NOT NULL DEFAULT 'pending',
+    attempts bigint NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL,
+    last_error text,
+    sent_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_next_attempt_at ON outbox_messages (next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_status ON outbox_messages (status);
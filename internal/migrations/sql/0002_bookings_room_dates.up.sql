@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_bookings_room_dates ON bookings (room_id, start_date, end_date);
@@ -0,0 +1,2 @@
+-- Диспетчер выбирает только ожидающие сообщения, отправленные и dead в индекс не попадают
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_due ON outbox_messages (next_attempt_at) WHERE status = 'pending';
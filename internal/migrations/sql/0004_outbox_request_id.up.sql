@@ -0,0 +1,2 @@
+-- ID запроса, в котором поставлено сообщение: связывает журнал диспетчера с исходным запросом
+ALTER TABLE outbox_messages ADD COLUMN request_id varchar(64) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Пересчёт статусов не откатывается: исходные значения не сохранялись
+SELECT 1;
@@ -0,0 +1,13 @@
+-- Статус бронирований, созданных до появления столбца status. 0001 добавила его со
+-- значением pending_payment, и без пересчёта оплаченные бронирования истекли бы при
+-- первом проходе ExpireDue, а возврат по ним был бы недоступен.
+-- Старые бронирования узнаются по отсутствию записей в журнале booking_events:
+-- новый код пишет журнал с момента создания бронирования.
+UPDATE bookings SET status = CASE
+        WHEN payment_status = 'refunded' THEN 'refunded'
+        WHEN deleted_at IS NOT NULL OR payment_status = 'canceled' THEN 'cancelled'
+        WHEN payment_status = 'succeeded' OR is_offline_booking THEN 'confirmed'
+        ELSE status
+    END
+WHERE status = 'pending_payment'
+    AND NOT EXISTS (SELECT 1 FROM booking_events e WHERE e.booking_id = bookings.id);
@@ -0,0 +1,4 @@
+-- Сумма возврата, определённая в момент отмены оплаченного бронирования. Повторный
+-- возврат после сбоя платёжной системы берёт её, а не пересчитывает по политике отмены.
+-- NULL — сумма не определялась: бронирование не отменялось или отменено до этой миграции.
+ALTER TABLE bookings ADD COLUMN refund_amount decimal;
@@ -0,0 +1,144 @@
+package outbox
+
+import (
+	"context"
+	"hotel-booking/internal/email"
+	"hotel-booking/internal/logging"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SendFunc доставляет письмо. В main подключается email.SendEmail.
+type SendFunc func(to string, msg email.Message) error
+
+// Dispatcher периодически забирает из outbox сообщения, срок которых наступил, и отправляет их.
+// Неудачная попытка откладывает сообщение с экспоненциальной задержкой, после MaxAttempts
+// неудач сообщение переводится в dead. Запускается из main через Start и останавливается через Stop.
+type Dispatcher struct {
+	messages  Repo
+	send      SendFunc
+	cfg       Config
+	stop      chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+	started   bool
+}
+
+func NewDispatcher(messages Repo, send SendFunc, cfg Config) *Dispatcher {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	return &Dispatcher{
+		messages: messages,
+		send:     send,
+		cfg:      cfg,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (d *Dispatcher) Start() {
+	d.startOnce.Do(func() {
+		d.started = true
+		slog.Info("Запуск диспетчера outbox", "interval", d.cfg.Interval.String())
+		go d.run()
+	})
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		// Пока очередь не разобрана, берём следующую пачку без ожидания тика
+		for d.DispatchDue(time.Now()) == d.cfg.BatchSize {
+			select {
+			case <-d.stop:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop останавливает диспетчер и ждёт окончания текущего прохода, но не дольше ctx
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	if !d.started {
+		return nil
+	}
+	d.stopOnce.Do(func() { close(d.stop) })
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DispatchDue отправляет сообщения, срок попытки которых наступил к now.
+// Возвращает число взятых в работу сообщений.
+func (d *Dispatcher) DispatchDue(now time.Time) int {
+	messages, err := d.messages.Claim(now, d.cfg.BatchSize, claimLease)
+	if err != nil {
+		slog.Error("Ошибка при получении сообщений outbox", logging.Err(err))
+		return 0
+	}
+
+	for _, msg := range messages {
+		d.deliver(msg)
+	}
+	return len(messages)
+}
+
+func (d *Dispatcher) deliver(msg Message) {
+	// Запись журнала связывается с запросом, в котором письмо поставлено в очередь
+	ctx := context.Background()
+	if msg.RequestID != "" {
+		ctx = logging.WithRequestID(ctx, msg.RequestID)
+	}
+	logger := slog.With("worker", "outbox", "message_id", msg.ID, "attempt", msg.Attempts+1)
+
+	err := d.send(msg.Recipient, email.Message{Subject: msg.Subject, HTML: msg.Body, Text: msg.TextBody})
+	now := time.Now()
+
+	msg.Attempts++
+	switch {
+	case err == nil:
+		msg.Status = StatusSent
+		msg.SentAt = &now
+		msg.LastError = ""
+	case msg.Attempts >= d.cfg.MaxAttempts:
+		logger.ErrorContext(ctx, "Сообщение outbox не доставлено, попытки исчерпаны", logging.Err(err))
+		msg.Status = StatusDead
+		msg.LastError = err.Error()
+	default:
+		logger.WarnContext(ctx, "Ошибка при отправке сообщения outbox", logging.Err(err))
+		msg.NextAttemptAt = now.Add(backoff(msg.Attempts))
+		msg.LastError = err.Error()
+	}
+
+	if err := d.messages.SaveAttempt(msg); err != nil {
+		logger.ErrorContext(ctx, "Ошибка при обновлении сообщения outbox", logging.Err(err))
+		return
+	}
+	if msg.Status == StatusSent {
+		logger.InfoContext(ctx, "Сообщение outbox отправлено")
+	}
+}
@@ -0,0 +1,138 @@
+package outbox
+
+import (
+	"errors"
+	"hotel-booking/internal/email"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, ожидалось %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestClaimLeasesMessages(t *testing.T) {
+	repo := NewMemoryRepo()
+	first := repo.Enqueue(Email{To: "a@example.com", Message: email.Message{Subject: "1"}})
+	second := repo.Enqueue(Email{To: "b@example.com", Message: email.Message{Subject: "2"}})
+	now := time.Now()
+
+	claimed, err := repo.Claim(now, 1, claimLease)
+	if err != nil || len(claimed) != 1 || claimed[0].ID != first.ID {
+		t.Fatalf("первая пачка: %+v, %v; ожидалось сообщение %d", claimed, err, first.ID)
+	}
+
+	// Взятое сообщение скрыто от другого диспетчера, следующее ещё доступно
+	claimed, _ = repo.Claim(now, 10, claimLease)
+	if len(claimed) != 1 || claimed[0].ID != second.ID {
+		t.Fatalf("вторая пачка: %+v; ожидалось сообщение %d", claimed, second.ID)
+	}
+	if claimed, _ = repo.Claim(now.Add(claimLease-time.Second), 10, claimLease); len(claimed) != 0 {
+		t.Fatalf("до конца аренды выданы сообщения: %+v", claimed)
+	}
+
+	// Диспетчер упал, не сохранив результат: после аренды сообщения берутся снова
+	if claimed, _ = repo.Claim(now.Add(claimLease), 10, claimLease); len(claimed) != 2 {
+		t.Fatalf("после аренды выдано %d сообщений, ожидалось 2", len(claimed))
+	}
+}
+
+func TestDispatcherRetriesUntilDead(t *testing.T) {
+	repo := NewMemoryRepo()
+	sendErr := errors.New("smtp недоступен")
+	sent := 0
+	send := func(to string, msg email.Message) error {
+		if sendErr != nil {
+			return sendErr
+		}
+		sent++
+		return nil
+	}
+	d := NewDispatcher(repo, send, Config{MaxAttempts: 3})
+	msg := repo.Enqueue(Email{To: "guest@example.com", Message: email.Message{Subject: "Бронирование"}})
+
+	// Каждая неудача откладывает сообщение на удваивающуюся задержку
+	now := time.Now()
+	for attempt := 1; attempt < 3; attempt++ {
+		before := time.Now()
+		if n := d.DispatchDue(now); n != 1 {
+			t.Fatalf("попытка %d: взято %d сообщений", attempt, n)
+		}
+		stored, _ := repo.ByID(msg.ID)
+		delay := backoff(attempt)
+		if stored.Status != StatusPending || stored.Attempts != attempt || stored.LastError != sendErr.Error() ||
+			stored.NextAttemptAt.Before(before.Add(delay)) || stored.NextAttemptAt.After(time.Now().Add(delay)) {
+			t.Fatalf("после попытки %d: %+v", attempt, stored)
+		}
+		if n := d.DispatchDue(now); n != 0 {
+			t.Fatalf("попытка %d: сообщение взято до конца задержки", attempt)
+		}
+		now = stored.NextAttemptAt
+	}
+
+	// Последняя попытка переводит сообщение в dead, больше его не берут
+	d.DispatchDue(now)
+	stored, _ := repo.ByID(msg.ID)
+	if stored.Status != StatusDead || stored.Attempts != 3 {
+		t.Fatalf("после исчерпания попыток: %+v", stored)
+	}
+	if n := d.DispatchDue(now.Add(24 * time.Hour)); n != 0 {
+		t.Fatalf("сообщение в dead взято в работу")
+	}
+
+	// Ручная повторная отправка возвращает сообщение в очередь со сброшенным счётчиком
+	if err := repo.Resend(msg.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Resend(msg.ID); !errors.Is(err, ErrNotDead) {
+		t.Fatalf("повторный Resend: %v, ожидалась ErrNotDead", err)
+	}
+	stored, _ = repo.ByID(msg.ID)
+	if stored.Status != StatusPending || stored.Attempts != 0 {
+		t.Fatalf("после Resend: %+v", stored)
+	}
+
+	sendErr = nil
+	if n := d.DispatchDue(time.Now()); n != 1 || sent != 1 {
+		t.Fatalf("после Resend взято %d сообщений, отправлено %d", n, sent)
+	}
+	stored, _ = repo.ByID(msg.ID)
+	if stored.Status != StatusSent || stored.Attempts != 1 || stored.SentAt == nil || stored.LastError != "" {
+		t.Fatalf("после отправки: %+v", stored)
+	}
+}
+
+func TestResendRequiresDead(t *testing.T) {
+	repo := NewMemoryRepo()
+	pending := repo.Enqueue(Email{To: "guest@example.com"})
+
+	tests := []struct {
+		name string
+		id   uint
+	}{
+		{"сообщение ещё в очереди", pending.ID},
+		{"сообщения нет", pending.ID + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := repo.Resend(tt.id); !errors.Is(err, ErrNotDead) {
+				t.Fatalf("Resend: %v, ожидалась ErrNotDead", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler — администрирование очереди исходящих сообщений
+type Handler struct {
+	Messages Repo
+}
+
+func NewHandler(messages Repo) *Handler {
+	return &Handler{Messages: messages}
+}
+
+// MessageView — сообщение outbox без тела: в письмах бывают ссылки сброса пароля и подтверждения почты
+type MessageView struct {
+	ID            uint       `json:"id"`
+	Kind          string     `json:"kind"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// @Security BearerAuth
+// GetOutboxMessagesHandler godoc
+// @Summary Сообщения outbox
+// @Description Возвращает последние сообщения очереди отправки, по умолчанию недоставленные (dead). Тело писем не возвращается.
+// @Tags admin
+// @Produce json
+// @Param status query string false "Статус: pending, sent или dead" default(dead)
+// @Param limit query int false "Количество сообщений, не больше 500" default(100)
+// @Success 200 {array} response.OutboxMessageResponse "Сообщения"
+// @Failure 400 {object} response.ErrorResponse "Неверный статус или лимит"
+// @Failure 403 {object} response.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при получении сообщений"
+// @Router /admin/outbox [get]
+func (h *Handler) GetOutboxMessagesHandler(c *gin.Context) {
+	status := c.DefaultQuery("status", StatusDead)
+	if status != StatusPending && status != StatusSent && status != StatusDead {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный статус"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный лимит"})
+		return
+	}
+
+	messages, err := h.Messages.List(status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении сообщений"})
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+// @Security BearerAuth
+// ResendOutboxMessageHandler godoc
+// @Summary Повторная отправка сообщения
+// @Description Возвращает недоставленное сообщение (dead) в очередь со сброшенным счётчиком попыток
+// @Tags admin
+// @Produce json
+// @Param id path int true "ID сообщения"
+// @Success 200 {object} response.MessageResponse "Сообщение поставлено в очередь"
+// @Failure 400 {object} response.ErrorResponse "Неверный ID"
+// @Failure 403 {object} response.ErrorResponse "Недостаточно прав"
+// @Failure 409 {object} response.ErrorResponse "Сообщение не в статусе dead"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при постановке в очередь"
+// @Router /admin/outbox/{id}/resend [post]
+func (h *Handler) ResendOutboxMessageHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID"})
+		return
+	}
+
+	err = h.Messages.Resend(uint(id))
+	if errors.Is(err, ErrNotDead) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Сообщение не найдено или не в статусе dead"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при постановке в очередь"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Сообщение поставлено в очередь"})
+}
+
+// MailStatus — сводка по очереди исходящих писем
+type MailStatus struct {
+	Counts        map[string]int64 `json:"counts"`                   // Число сообщений в каждом статусе
+	OldestPending *time.Time       `json:"oldest_pending,omitempty"` // Время создания самого старого неотправленного сообщения
+	LastSentAt    *time.Time       `json:"last_sent_at,omitempty"`   // Время последней успешной отправки
+	Recent        []MessageView    `json:"recent"`                   // Последние сообщения во всех статусах
+}
+
+// @Security BearerAuth
+// GetMailStatusHandler godoc
+// @Summary Состояние исходящей почты
+// @Description Возвращает число писем в каждом статусе очереди, возраст самого старого неотправленного письма, время последней отправки и последние письма. Тело писем не возвращается.
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Количество последних писем, не больше 100" default(20)
+// @Success 200 {object} response.MailStatusResponse "Состояние очереди"
+// @Failure 400 {object} response.ErrorResponse "Неверный лимит"
+// @Failure 403 {object} response.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при получении состояния"
+// @Router /admin/mail/status [get]
+func (h *Handler) GetMailStatusHandler(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный лимит"})
+		return
+	}
+
+	status, err := h.Messages.Status(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении состояния"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
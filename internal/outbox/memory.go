@@ -0,0 +1,168 @@
+package outbox
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryRepo хранит сообщения outbox в памяти; используется в тестах
+type MemoryRepo struct {
+	mu       sync.Mutex
+	nextID   uint
+	messages map[uint]*Message
+}
+
+func NewMemoryRepo() *MemoryRepo {
+	return &MemoryRepo{messages: map[uint]*Message{}}
+}
+
+// Enqueue ставит письмо в очередь, как EnqueueEmail после фиксации транзакции
+func (r *MemoryRepo) Enqueue(mail Email) Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	now := time.Now()
+	msg := Message{
+		ID:            r.nextID,
+		Kind:          KindEmail,
+		Recipient:     mail.To,
+		Subject:       mail.Message.Subject,
+		Body:          mail.Message.HTML,
+		TextBody:      mail.Message.Text,
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	r.messages[msg.ID] = &msg
+	return msg
+}
+
+// ByID возвращает сообщение; false, если его нет
+func (r *MemoryRepo) ByID(id uint) (Message, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msg, ok := r.messages[id]
+	if !ok {
+		return Message{}, false
+	}
+	return *msg, true
+}
+
+// sorted возвращает копии сообщений, подходящих под match, в порядке less
+func (r *MemoryRepo) sorted(match func(m *Message) bool, less func(a, b *Message) bool) []Message {
+	var messages []Message
+	for _, msg := range r.messages {
+		if match(msg) {
+			messages = append(messages, *msg)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool { return less(&messages[i], &messages[j]) })
+	return messages
+}
+
+func newestFirst(a, b *Message) bool {
+	return a.ID > b.ID
+}
+
+func (r *MemoryRepo) Claim(now time.Time, limit int, lease time.Duration) ([]Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	due := r.sorted(func(m *Message) bool {
+		return m.Status == StatusPending && !m.NextAttemptAt.After(now)
+	}, func(a, b *Message) bool {
+		if !a.NextAttemptAt.Equal(b.NextAttemptAt) {
+			return a.NextAttemptAt.Before(b.NextAttemptAt)
+		}
+		return a.ID < b.ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for i := range due {
+		due[i].NextAttemptAt = now.Add(lease)
+		r.messages[due[i].ID].NextAttemptAt = due[i].NextAttemptAt
+	}
+	return due, nil
+}
+
+func (r *MemoryRepo) SaveAttempt(msg Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.messages[msg.ID]
+	if !ok {
+		return nil
+	}
+	stored.Status = msg.Status
+	stored.Attempts = msg.Attempts
+	stored.NextAttemptAt = msg.NextAttemptAt
+	stored.LastError = msg.LastError
+	stored.SentAt = msg.SentAt
+	stored.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *MemoryRepo) Resend(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.messages[id]
+	if !ok || stored.Status != StatusDead {
+		return ErrNotDead
+	}
+	stored.Status = StatusPending
+	stored.Attempts = 0
+	stored.NextAttemptAt = time.Now()
+	return nil
+}
+
+func view(msg Message) MessageView {
+	return MessageView{
+		ID:            msg.ID,
+		Kind:          msg.Kind,
+		Recipient:     msg.Recipient,
+		Subject:       msg.Subject,
+		Status:        msg.Status,
+		Attempts:      msg.Attempts,
+		NextAttemptAt: msg.NextAttemptAt,
+		LastError:     msg.LastError,
+		SentAt:        msg.SentAt,
+		CreatedAt:     msg.CreatedAt,
+	}
+}
+
+func (r *MemoryRepo) List(status string, limit int) ([]MessageView, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	views := []MessageView{}
+	for _, msg := range r.sorted(func(m *Message) bool { return m.Status == status }, newestFirst) {
+		if len(views) == limit {
+			break
+		}
+		views = append(views, view(msg))
+	}
+	return views, nil
+}
+
+func (r *MemoryRepo) Status(recent int) (MailStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := MailStatus{
+		Counts: map[string]int64{StatusPending: 0, StatusSent: 0, StatusDead: 0},
+		Recent: []MessageView{},
+	}
+	for _, msg := range r.sorted(func(*Message) bool { return true }, newestFirst) {
+		status.Counts[msg.Status]++
+		if msg.Status == StatusPending && (status.OldestPending == nil || msg.CreatedAt.Before(*status.OldestPending)) {
+			createdAt := msg.CreatedAt
+			status.OldestPending = &createdAt
+		}
+		if msg.SentAt != nil && (status.LastSentAt == nil || msg.SentAt.After(*status.LastSentAt)) {
+			status.LastSentAt = msg.SentAt
+		}
+		if len(status.Recent) < recent {
+			status.Recent = append(status.Recent, view(msg))
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,97 @@
+package outbox
+
+import (
+	"errors"
+	"hotel-booking/internal/email"
+	"hotel-booking/internal/logging"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Статусы сообщения в outbox
+const (
+	StatusPending = "pending" // Ждёт отправки или повторной попытки
+	StatusSent    = "sent"    // Отправлено
+	StatusDead    = "dead"    // Попытки исчерпаны, нужна ручная повторная отправка
+)
+
+// Виды сообщений
+const (
+	KindEmail = "email"
+)
+
+const (
+	DefaultInterval    = 5 * time.Second // Как часто диспетчер забирает сообщения
+	DefaultMaxAttempts = 8               // После стольких неудачных попыток сообщение уходит в dead
+	DefaultBatchSize   = 20              // Сколько сообщений диспетчер берёт за один проход
+
+	baseBackoff = 30 * time.Second // Задержка после первой неудачи, дальше удваивается
+	maxBackoff  = time.Hour
+	claimLease  = 5 * time.Minute // На это время взятое сообщение скрыто от других диспетчеров
+)
+
+var ErrNotDead = errors.New("повторно отправить можно только сообщение в статусе dead")
+
+// Message — сообщение, которое нужно доставить после фиксации бизнес-изменения.
+// Пишется в той же транзакции, что и изменение, и отправляется диспетчером.
+type Message struct {
+	ID            uint      `gorm:"primarykey"`
+	Kind          string    `gorm:"type:varchar(20);not null"`                         // Вид сообщения, пока только email
+	Recipient     string    `gorm:"type:varchar(255);not null"`                        // Адрес получателя
+	Subject       string    `gorm:"type:varchar(255);not null"`                        // Тема письма
+	Body          string    `gorm:"type:text;not null"`                                // HTML-версия письма
+	TextBody      string    `gorm:"type:text"`                                         // Текстовая версия письма
+	Status        string    `gorm:"type:varchar(20);not null;default:'pending';index"` // pending, sent или dead
+	Attempts      int       `gorm:"not null;default:0"`                                // Число выполненных попыток
+	NextAttemptAt time.Time `gorm:"not null;index"`                                    // Не раньше этого времени будет следующая попытка
+	LastError     string    `gorm:"type:text"`                                         // Ошибка последней неудачной попытки
+	RequestID     string    `gorm:"type:varchar(64);not null;default:''"`              // ID запроса, в котором сообщение поставлено в очередь
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (Message) TableName() string {
+	return "outbox_messages"
+}
+
+// Email — письмо, которое ставится в очередь вместе с изменением данных
+type Email struct {
+	To      string
+	Message email.Message
+}
+
+// EnqueueEmail ставит письмо в очередь в транзакции tx. Письмо уйдёт, только если tx зафиксирована.
+// ID запроса берётся из контекста tx (см. gorm.DB.WithContext) и попадает в журнал отправки.
+func EnqueueEmail(tx *gorm.DB, to string, msg email.Message) error {
+	return tx.Create(&Message{
+		RequestID:     logging.RequestID(tx.Statement.Context),
+		Kind:          KindEmail,
+		Recipient:     to,
+		Subject:       msg.Subject,
+		Body:          msg.HTML,
+		TextBody:      msg.Text,
+		Status:        StatusPending,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// backoff возвращает задержку перед следующей попыткой после attempts неудачных
+func backoff(attempts int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
+
+// Config — настройки диспетчера
+type Config struct {
+	Interval    time.Duration
+	MaxAttempts int
+	BatchSize   int
+}
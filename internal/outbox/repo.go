@@ -0,0 +1,129 @@
+package outbox
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repo — сообщения outbox для диспетчера и администратора
+type Repo interface {
+	// Claim берёт до limit сообщений, срок попытки которых наступил к now, и откладывает
+	// их на lease, чтобы параллельно работающий диспетчер (другой экземпляр сервера) их не взял
+	Claim(now time.Time, limit int, lease time.Duration) ([]Message, error)
+	// SaveAttempt сохраняет результат попытки доставки: Status, Attempts, NextAttemptAt, LastError и SentAt
+	SaveAttempt(msg Message) error
+	// Resend возвращает сообщение из dead в очередь с обнулённым счётчиком попыток.
+	// Если сообщения нет или оно не в dead, возвращает ErrNotDead.
+	Resend(id uint) error
+	// List возвращает последние limit сообщений в статусе status
+	List(status string, limit int) ([]MessageView, error)
+	// Status возвращает сводку по очереди с recent последними сообщениями
+	Status(recent int) (MailStatus, error)
+}
+
+// GormRepo хранит сообщения outbox в базе данных
+type GormRepo struct {
+	db *gorm.DB
+}
+
+func NewGormRepo(db *gorm.DB) *GormRepo {
+	return &GormRepo{db: db}
+}
+
+func (r *GormRepo) Claim(now time.Time, limit int, lease time.Duration) ([]Message, error) {
+	var messages []Message
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", StatusPending, now).
+			Order("next_attempt_at, id").
+			Limit(limit).
+			Find(&messages).Error; err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(messages))
+		for i := range messages {
+			ids[i] = messages[i].ID
+			messages[i].NextAttemptAt = now.Add(lease)
+		}
+		return tx.Model(&Message{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return messages, err
+}
+
+func (r *GormRepo) SaveAttempt(msg Message) error {
+	return r.db.Model(&Message{}).Where("id = ?", msg.ID).Updates(map[string]interface{}{
+		"status":          msg.Status,
+		"attempts":        msg.Attempts,
+		"next_attempt_at": msg.NextAttemptAt,
+		"last_error":      msg.LastError,
+		"sent_at":         msg.SentAt,
+	}).Error
+}
+
+func (r *GormRepo) Resend(id uint) error {
+	result := r.db.Model(&Message{}).
+		Where("id = ? AND status = ?", id, StatusDead).
+		Updates(map[string]interface{}{
+			"status":          StatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotDead
+	}
+	return nil
+}
+
+func (r *GormRepo) List(status string, limit int) ([]MessageView, error) {
+	messages := []MessageView{}
+	err := r.db.Model(&Message{}).
+		Where("status = ?", status).
+		Order("id DESC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *GormRepo) Status(recent int) (MailStatus, error) {
+	status := MailStatus{
+		Counts: map[string]int64{StatusPending: 0, StatusSent: 0, StatusDead: 0},
+		Recent: []MessageView{},
+	}
+
+	var counts []struct {
+		Status string
+		Count  int64
+	}
+	if err := r.db.Model(&Message{}).Select("status, count(*) AS count").Group("status").Scan(&counts).Error; err != nil {
+		return status, err
+	}
+	for _, row := range counts {
+		status.Counts[row.Status] = row.Count
+	}
+
+	var oldest Message
+	if err := r.db.Where("status = ?", StatusPending).Order("created_at").Limit(1).Find(&oldest).Error; err != nil {
+		return status, err
+	}
+	if oldest.ID != 0 {
+		status.OldestPending = &oldest.CreatedAt
+	}
+
+	var lastSent Message
+	if err := r.db.Where("status = ?", StatusSent).Order("sent_at DESC").Limit(1).Find(&lastSent).Error; err != nil {
+		return status, err
+	}
+	status.LastSentAt = lastSent.SentAt
+
+	err := r.db.Model(&Message{}).Order("id DESC").Limit(recent).Find(&status.Recent).Error
+	return status, err
+}
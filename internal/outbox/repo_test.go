@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"errors"
+	"fmt"
+	"hotel-booking/internal/email"
+	"hotel-booking/internal/migrations"
+	"hotel-booking/internal/testdb"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testdb.Run(m))
+}
+
+func TestGormRepoClaimAndResend(t *testing.T) {
+	db := testdb.Open(t)
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatalf("загрузка миграций: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("миграция: %v", err)
+	}
+	repo := NewGormRepo(db)
+
+	to := fmt.Sprintf("outbox%d@example.com", time.Now().UnixNano())
+	if err := EnqueueEmail(db, to, email.Message{Subject: "Тест", HTML: "<p>тест</p>"}); err != nil {
+		t.Fatal(err)
+	}
+	var msg Message
+	if err := db.Where("recipient = ?", to).First(&msg).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// claimed возвращает наше сообщение из пачки: в общей базе бывают чужие
+	claimed := func(now time.Time) (Message, bool) {
+		t.Helper()
+		messages, err := repo.Claim(now, 1000, claimLease)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range messages {
+			if m.ID == msg.ID {
+				return m, true
+			}
+		}
+		return Message{}, false
+	}
+
+	now := time.Now()
+	got, ok := claimed(now)
+	if !ok || !got.NextAttemptAt.Equal(now.Add(claimLease)) {
+		t.Fatalf("сообщение не взято или без аренды: %+v", got)
+	}
+	if _, ok := claimed(now); ok {
+		t.Fatal("сообщение взято повторно до конца аренды")
+	}
+
+	got.Attempts, got.Status, got.LastError = 1, StatusDead, "smtp недоступен"
+	if err := repo.SaveAttempt(got); err != nil {
+		t.Fatal(err)
+	}
+	if dead, _ := repo.List(StatusDead, 500); len(dead) == 0 {
+		t.Fatal("сообщение не попало в список dead")
+	}
+
+	if err := repo.Resend(msg.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Resend(msg.ID); !errors.Is(err, ErrNotDead) {
+		t.Fatalf("повторный Resend: %v, ожидалась ErrNotDead", err)
+	}
+	if got, ok := claimed(time.Now()); !ok || got.Attempts != 0 {
+		t.Fatalf("после Resend: %+v, взято %v", got, ok)
+	}
+}
@@ -0,0 +1,234 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// FakeProvider — платёжный провайдер в памяти процесса для разработки и тестов.
+// Ссылка на оплату ведёт на /payments/fake/:id, где платёж можно подтвердить или отменить.
+type FakeProvider struct {
+	mu         sync.Mutex
+	baseURL    string
+	payments   map[string]*Payment
+	returnURLs map[string]string
+	refunded   map[string]float64
+	refunds    map[string]*Refund // по ключу идемпотентности
+}
+
+func NewFakeProvider(baseURL string) *FakeProvider {
+	return &FakeProvider{
+		baseURL:    baseURL,
+		payments:   make(map[string]*Payment),
+		returnURLs: make(map[string]string),
+		refunded:   make(map[string]float64),
+		refunds:    make(map[string]*Refund),
+	}
+}
+
+func (f *FakeProvider) CreatePayment(ctx context.Context, req CreatePaymentRequest) (*Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := uuid.New().String()
+	metadata := make(map[string]string, len(req.Metadata))
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+
+	payment := &Payment{
+		ID:              id,
+		Status:          StatusPending,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		ConfirmationURL: fmt.Sprintf("%s/payments/fake/%s", f.baseURL, id),
+		Metadata:        metadata,
+	}
+	f.payments[id] = payment
+	f.returnURLs[id] = req.ReturnURL
+
+	copied := *payment
+	return &copied, nil
+}
+
+func (f *FakeProvider) GetPayment(ctx context.Context, paymentID string) (*Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payment, ok := f.payments[paymentID]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+	copied := *payment
+	return &copied, nil
+}
+
+func (f *FakeProvider) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Как и ЮKassa, повтор с тем же ключом возвращает первый возврат
+	if refund, ok := f.refunds[req.IdempotenceKey]; ok && req.IdempotenceKey != "" {
+		copied := *refund
+		return &copied, nil
+	}
+
+	payment, ok := f.payments[req.PaymentID]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+	if payment.Status != StatusSucceeded {
+		return nil, fmt.Errorf("возврат невозможен: платёж в статусе %s", payment.Status)
+	}
+	if f.refunded[req.PaymentID]+req.Amount > payment.Amount+0.001 {
+		return nil, fmt.Errorf("сумма возврата превышает сумму платежа")
+	}
+	f.refunded[req.PaymentID] += req.Amount
+
+	refund := &Refund{
+		ID:        uuid.New().String(),
+		PaymentID: req.PaymentID,
+		Status:    StatusSucceeded,
+		Amount:    req.Amount,
+	}
+	if req.IdempotenceKey != "" {
+		f.refunds[req.IdempotenceKey] = refund
+	}
+	copied := *refund
+	return &copied, nil
+}
+
+// Refunded возвращает сумму, уже возвращённую по платежу
+func (f *FakeProvider) Refunded(paymentID string) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.refunded[paymentID]
+}
+
+// VerifyWebhook принимает уведомления в формате ЮKassa и сверяет их с платежами в памяти
+func (f *FakeProvider) VerifyWebhook(r *http.Request, body []byte) (*Notification, error) {
+	var notification yooKassaNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, ErrInvalidWebhook
+	}
+
+	paymentID := notification.Object.ID
+	if notification.Object.PaymentID != "" {
+		paymentID = notification.Object.PaymentID
+	}
+
+	payment, err := f.GetPayment(r.Context(), paymentID)
+	if err != nil {
+		return nil, ErrInvalidWebhook
+	}
+
+	return &Notification{Event: notification.Event, Payment: *payment}, nil
+}
+
+// Confirm имитирует успешную оплату
+func (f *FakeProvider) Confirm(paymentID string) (*Notification, error) {
+	return f.setStatus(paymentID, StatusSucceeded)
+}
+
+// Cancel имитирует отказ от оплаты
+func (f *FakeProvider) Cancel(paymentID string) (*Notification, error) {
+	return f.setStatus(paymentID, StatusCanceled)
+}
+
+func (f *FakeProvider) setStatus(paymentID, status string) (*Notification, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payment, ok := f.payments[paymentID]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+	if payment.Status != StatusPending {
+		return nil, fmt.Errorf("платёж уже в статусе %s", payment.Status)
+	}
+	payment.Status = status
+
+	return &Notification{Event: "payment." + status, Payment: *payment}, nil
+}
+
+func (f *FakeProvider) returnURL(paymentID string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.returnURLs[paymentID]
+}
+
+// FakeCheckoutHandler godoc
+// @Summary Тестовая страница оплаты
+// @Description Имитирует страницу оплаты при PAYMENT_PROVIDER=fake. action=succeed подтверждает платёж, action=cancel отменяет его.
+// @Tags payments
+// @Produce json
+// @Param id path string true "ID платежа"
+// @Param action query string false "succeed или cancel"
+// @Success 200 {object} response.MessageResponse "Состояние платежа"
+// @Success 302 "Перенаправление на return_url"
+// @Failure 400 {object} response.ErrorResponse "Некорректное действие"
+// @Failure 404 {object} response.ErrorResponse "Платёж не найден"
+// @Router /payments/fake/{id} [get]
+func (h *Handler) FakeCheckoutHandler(c *gin.Context) {
+	fake, ok := h.Provider.(*FakeProvider)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Тестовый провайдер не включен"})
+		return
+	}
+
+	paymentID := c.Param("id")
+
+	var (
+		notification *Notification
+		err          error
+	)
+	switch c.Query("action") {
+	case "":
+		payment, err := fake.GetPayment(c.Request.Context(), paymentID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Платёж не найден"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"payment_id": payment.ID,
+			"status":     payment.Status,
+			"amount":     payment.Amount,
+			"succeed":    payment.ConfirmationURL + "?action=succeed",
+			"cancel":     payment.ConfirmationURL + "?action=cancel",
+		})
+		return
+	case "succeed":
+		notification, err = fake.Confirm(paymentID)
+	case "cancel":
+		notification, err = fake.Cancel(paymentID)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректное действие"})
+		return
+	}
+
+	if err == ErrPaymentNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Платёж не найден"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status, err := h.applyNotification(c.Request.Context(), notification); err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	if returnURL := fake.returnURL(paymentID); returnURL != "" {
+		c.Redirect(http.StatusFound, returnURL)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Статус оплаты обновлен"})
+}
@@ -0,0 +1,139 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hotel-booking/internal/bookings"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFakeCheckoutConfirmsBooking(t *testing.T) {
+	h, _, repo, booking := newWebhookTest(t)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/payments/fake/:id", h.FakeCheckoutHandler)
+	r.POST("/payments/callback", h.PaymentCallbackHandler)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+	checkout := "/payments/fake/" + booking.PaymentID
+
+	// Страница оплаты показывает платёж и ссылки на действия
+	w := do(http.MethodGet, checkout, "")
+	var page map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); w.Code != http.StatusOK || err != nil ||
+		page["status"] != StatusPending || page["succeed"] != "http://pay.test"+checkout+"?action=succeed" {
+		t.Fatalf("страница оплаты: %d %s", w.Code, w.Body.String())
+	}
+
+	// Оплата на странице сразу применяется к бронированию
+	if w := do(http.MethodGet, checkout+"?action=succeed", ""); w.Code != http.StatusOK {
+		t.Fatalf("оплата: %d %s", w.Code, w.Body.String())
+	}
+	stored, _ := repo.ByID(booking.ID)
+	if stored.Status != bookings.StatusConfirmed || stored.PaymentStatus != StatusSucceeded {
+		t.Fatalf("после оплаты: статус %s, оплата %s", stored.Status, stored.PaymentStatus)
+	}
+	events, _ := repo.Events(booking.ID)
+
+	// Уведомление о той же оплате принимается и ничего не меняет
+	callback := fmt.Sprintf(`{"type":"notification","event":"payment.succeeded","object":{"id":%q}}`, booking.PaymentID)
+	if w := do(http.MethodPost, "/payments/callback", callback); w.Code != http.StatusOK {
+		t.Fatalf("уведомление: %d %s", w.Code, w.Body.String())
+	}
+	if again, _ := repo.Events(booking.ID); len(again) != len(events) {
+		t.Fatalf("уведомление после оплаты записало события: %d, было %d", len(again), len(events))
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"повторная оплата", checkout + "?action=succeed", http.StatusBadRequest},
+		{"отмена оплаченного платежа", checkout + "?action=cancel", http.StatusBadRequest},
+		{"неизвестное действие", checkout + "?action=refund", http.StatusBadRequest},
+		{"неизвестный платёж", "/payments/fake/unknown?action=succeed", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := do(http.MethodGet, tt.path, ""); w.Code != tt.want {
+			t.Errorf("%s: код %d, ожидался %d: %s", tt.name, w.Code, tt.want, w.Body.String())
+		}
+	}
+}
+
+func TestFakeCheckoutRedirectsToReturnURL(t *testing.T) {
+	h, fake, repo, booking := newWebhookTest(t)
+	h.ReturnURL = "http://app.test/bookings"
+	// Первый платёж создан без адреса возврата: гость отказывается и платит заново
+	if _, err := fake.Cancel(booking.PaymentID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.CreateBookingPayment(context.Background(), &booking); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/payments/fake/:id", h.FakeCheckoutHandler)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/payments/fake/"+booking.PaymentID+"?action=succeed", nil))
+
+	if w.Code != http.StatusFound || w.Header().Get("Location") != h.ReturnURL {
+		t.Fatalf("код %d, Location %q", w.Code, w.Header().Get("Location"))
+	}
+	if stored, _ := repo.ByID(booking.ID); stored.Status != bookings.StatusConfirmed {
+		t.Fatalf("после оплаты статус %s", stored.Status)
+	}
+}
+
+func TestFakeRefundIdempotentPerKey(t *testing.T) {
+	fake := NewFakeProvider("http://pay.test")
+	ctx := context.Background()
+	payment, err := fake.CreatePayment(ctx, CreatePaymentRequest{Amount: 1000, Currency: "RUB"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fake.Refund(ctx, RefundRequest{PaymentID: payment.ID, Amount: 100, IdempotenceKey: "refund-0"}); err == nil {
+		t.Fatal("возврат по неоплаченному платежу прошёл")
+	}
+	if _, err := fake.Confirm(payment.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := fake.Refund(ctx, RefundRequest{PaymentID: payment.ID, Amount: 400, IdempotenceKey: "refund-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Повтор с тем же ключом отдаёт первый возврат, даже с другой суммой
+	again, err := fake.Refund(ctx, RefundRequest{PaymentID: payment.ID, Amount: 900, IdempotenceKey: "refund-1"})
+	if err != nil || again.ID != first.ID || again.Amount != 400 {
+		t.Fatalf("повтор с тем же ключом: %+v, %v; ожидался возврат %+v", again, err, first)
+	}
+	if refunded := fake.Refunded(payment.ID); refunded != 400 {
+		t.Fatalf("возвращено %.2f, ожидалось 400", refunded)
+	}
+
+	// Новый ключ — новый возврат, но не больше суммы платежа
+	second, err := fake.Refund(ctx, RefundRequest{PaymentID: payment.ID, Amount: 600, IdempotenceKey: "refund-2"})
+	if err != nil || second.ID == first.ID {
+		t.Fatalf("второй возврат: %+v, %v", second, err)
+	}
+	if _, err := fake.Refund(ctx, RefundRequest{PaymentID: payment.ID, Amount: 1, IdempotenceKey: "refund-3"}); err == nil {
+		t.Fatal("возврат сверх суммы платежа прошёл")
+	}
+	if refunded := fake.Refunded(payment.ID); refunded != 1000 {
+		t.Fatalf("возвращено %.2f, ожидалось 1000", refunded)
+	}
+}
@@ -1,224 +1,258 @@
 package payments
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"context"
+	"errors"
 	"hotel-booking/internal/bookings"
-	"hotel-booking/internal/storage"
-	"log"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/logging"
+	"io"
+	"log/slog"
 	"net/http"
-	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-type PaymentRequest struct {
-	Amount struct {
-		Value    string `json:"value"`
-		Currency string `json:"currency"`
-	} `json:"amount"`
-	Confirmation struct {
-		Type      string `json:"type"`
-		ReturnURL string `json:"return_url"`
-	} `json:"confirmation"`
-	Capture     bool                   `json:"capture"`
-	Description string                 `json:"description"`
-	Metadata    map[string]interface{} `json:"metadata"`
+// Handler — обработчики оплаты, возврата и уведомлений платёжной системы.
+// Бронирования и журнал уведомлений доступны через репозитории, провайдер подключается в main.
+type Handler struct {
+	Bookings bookings.BookingRepo
+	Rooms    hotels.RoomRepo
+	Events   EventRepo
+	Provider PaymentProvider
+	// ReturnURL — куда провайдер возвращает гостя после оплаты
+	ReturnURL string
+	// WebhookAllowedIPs — сети, с которых принимаются уведомления, вместо адресов провайдера
+	WebhookAllowedIPs []string
+}
+
+func NewHandler(bookingRepo bookings.BookingRepo, rooms hotels.RoomRepo, events EventRepo, provider PaymentProvider, cfg Config) *Handler {
+	return &Handler{
+		Bookings:          bookingRepo,
+		Rooms:             rooms,
+		Events:            events,
+		Provider:          provider,
+		ReturnURL:         cfg.ReturnURL,
+		WebhookAllowedIPs: cfg.WebhookAllowedIPs,
+	}
+}
+
+// booking загружает бронирование по ID из параметра пути
+func (h *Handler) booking(param string) (bookings.Booking, error) {
+	id, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return bookings.Booking{}, err
+	}
+	return h.Bookings.ByID(uint(id))
 }
 
 // @Security BearerAuth
 // @Summary Создание платежа для бронирования
-// @Description Создает платеж через YooKassa для указанного бронирования и возвращает ссылку для оплаты.
+// @Description Создает платеж через платежный провайдер для указанного бронирования и возвращает ссылку для оплаты.
 // @Tags payments
 // @Accept json
 // @Produce json
 // @Param id path int true "Идентификатор бронирования"
 // @Success 200 {object} response.CreatePaymentResponse "Ссылка для оплаты успешно создана"
 // @Failure 400 {object} response.ErrorResponse "Некорректный запрос или бронирование уже оплачено"
+// @Failure 403 {object} response.ErrorResponse "Бронирование не принадлежит вам"
 // @Failure 404 {object} response.ErrorResponse "Бронирование не найдено"
-// @Failure 500 {object} response.ErrorResponse "Внутренняя ошибка сервера или ошибка платежной системы"
+// @Failure 409 {object} response.ErrorResponse "Время на оплату бронирования истекло"
+// @Failure 500 {object} response.ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 502 {object} response.ErrorResponse "Ошибка платежной системы"
 // @Router /bookings/{id}/pay [post]
-func CreatePaymentHandler(c *gin.Context) {
-	bookingID := c.Param("id")
-
-	var booking bookings.Booking
-	if err := storage.DB.First(&booking, bookingID).Error; err != nil {
+func (h *Handler) CreatePaymentHandler(c *gin.Context) {
+	booking, err := h.booking(c.Param("id"))
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Бронирование не найдено"})
 		return
 	}
 
-	if booking.PaymentStatus == "succeeded" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Бронирование уже оплачено"})
+	if booking.UserID != c.GetUint("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Бронирование не принадлежит вам"})
 		return
 	}
 
-	paymentRequest := PaymentRequest{
-		Capture:     true,
-		Description: fmt.Sprintf("Оплата бронирования %s", bookingID),
-		Metadata:    map[string]interface{}{"booking_id": bookingID}, // Указываем booking_id
-	}
-	paymentRequest.Amount.Value = fmt.Sprintf("%.2f", booking.TotalCost)
-	paymentRequest.Amount.Currency = "RUB"
-	paymentRequest.Confirmation.Type = "redirect"
-	paymentRequest.Confirmation.ReturnURL = "http://localhost:8080/payment/success"
-
-	requestBody, err := json.Marshal(paymentRequest)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании платежа"})
+	paymentURL, err := h.CreateBookingPayment(c.Request.Context(), &booking)
+	switch {
+	case errors.Is(err, ErrAlreadyPaid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Бронирование уже оплачено"})
 		return
-	}
-
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", "https://api.yookassa.ru/v3/payments", bytes.NewBuffer(requestBody))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании запроса"})
+	case errors.Is(err, ErrNotPending):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Бронирование не ожидает оплаты"})
 		return
-	}
-	req.SetBasicAuth(os.Getenv("YOKASSA_SHOP_ID"), os.Getenv("YOKASSA_SECRET_KEY"))
-	req.Header.Set("Content-Type", "application/json")
-
-	// Генерация и установка Idempotence-Key
-	idempotenceKey := uuid.New().String()
-	req.Header.Set("Idempotence-Key", idempotenceKey)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подключении к ЮKassa"})
-		return
-	}
-	defer resp.Body.Close()
-
-	var responseData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обработки ответа от ЮKassa"})
+	case errors.Is(err, ErrHoldExpired):
+		c.JSON(http.StatusConflict, gin.H{"error": "Время на оплату бронирования истекло"})
 		return
-	}
-
-	// Проверка статуса ответа
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		c.JSON(resp.StatusCode, responseData)
+	case errors.Is(err, ErrProviderError):
+		slog.ErrorContext(c.Request.Context(), "Ошибка при создании платежа", "booking_id", booking.ID, logging.Err(err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Ошибка платежной системы"})
 		return
-	}
-
-	paymentID, ok := responseData["id"].(string)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить PaymentID"})
-		return
-	}
-
-	booking.PaymentID = paymentID // Сохраняем PaymentID
-	if err := storage.DB.Save(&booking).Error; err != nil {
+	case err != nil:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении данных бронирования"})
 		return
 	}
 
-	// Проверка наличия confirmation_url
-	confirmation, ok := responseData["confirmation"].(map[string]interface{})
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить платёжную ссылку"})
-		return
-	}
-
-	confirmationURL, ok := confirmation["confirmation_url"].(string)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить платёжную ссылку"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"payment_url": confirmationURL})
+	c.JSON(http.StatusOK, gin.H{"payment_url": paymentURL})
 }
 
 // PaymentCallbackHandler обрабатывает уведомления о статусе оплаты от платежной системы.
 // @Summary Webhook для обработки статуса оплаты
-// @Description Обрабатывает уведомления от платежной системы и обновляет статус оплаты для указанного бронирования.
+// @Description Обрабатывает уведомления от платежной системы и обновляет статус оплаты для указанного бронирования. Уведомление принимается только с адресов платежной системы, состояние платежа перезапрашивается у провайдера, повторные уведомления игнорируются.
 // @Tags payments
 // @Accept json
 // @Produce json
 // @Param request body PaymentCallbackRequest true "Данные вебхука от платежной системы"
 // @Success 200 {object} response.SuccessResponse "Статус оплаты обновлен"
 // @Failure 400 {object} response.ErrorResponse "Некорректные данные запроса"
+// @Failure 403 {object} response.ErrorResponse "Недоверенный источник уведомления"
 // @Failure 404 {object} response.ErrorResponse "Бронирование не найдено"
 // @Failure 500 {object} response.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /payments/callback [post]
-func PaymentCallbackHandler(c *gin.Context) {
-	var callbackData map[string]interface{}
-	if err := json.NewDecoder(c.Request.Body).Decode(&callbackData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные"})
+func (h *Handler) PaymentCallbackHandler(c *gin.Context) {
+	if !h.isWebhookSourceAllowed(c.ClientIP()) {
+		slog.WarnContext(c.Request.Context(), "Webhook с недоверенного адреса отклонён", "client_ip", c.ClientIP())
+		c.JSON(http.StatusForbidden, gin.H{"error": "Недоверенный источник уведомления"})
 		return
 	}
 
-	log.Printf("Получен Webhook: %v\n", callbackData)
-
-	// Извлекаем объект "object" из Webhook
-	object, ok := callbackData["object"].(map[string]interface{})
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный формат 'object'"})
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные"})
 		return
 	}
 
-	// Проверяем статус оплаты
-	paymentStatus, ok := object["status"].(string)
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный формат поля 'status'"})
+	notification, err := h.Provider.VerifyWebhook(c.Request, body)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Webhook не прошёл проверку", logging.Err(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные"})
 		return
 	}
 
-	paymentID, ok := object["id"].(string)
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный формат поля 'id'"})
-		return
-	}
+	// Тело уведомления в журнал не пишется, только событие и ID платежа
+	slog.InfoContext(c.Request.Context(), "Получен webhook", "event", notification.Event, "payment_id", notification.Payment.ID)
 
-	// Обновляем статус бронирования по PaymentID
-	var booking bookings.Booking
-	if err := storage.DB.Where("payment_id = ?", paymentID).First(&booking).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Бронирование не найдено"})
+	if status, err := h.applyNotification(c.Request.Context(), notification); err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Проверяем наличие и формат metadata
-	metadata, ok := object["metadata"].(map[string]interface{})
-	if !ok || len(metadata) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Поле 'metadata' отсутствует или пусто"})
-		return
+	c.JSON(http.StatusOK, gin.H{"message": "Статус оплаты обновлен"})
+}
+
+// applyNotification переносит статус платежа из уведомления в бронирование.
+// Повторно доставленные уведомления и откат статуса назад ничего не меняют.
+// Оплата истёкшего или отменённого бронирования сразу возвращается гостю; если возврат
+// не прошёл, транзакция откатывается и платёжная система доставит уведомление повторно.
+// Возвращает HTTP-статус и ошибку, если уведомление применить не удалось.
+func (h *Handler) applyNotification(ctx context.Context, notification *Notification) (int, error) {
+	payment := notification.Payment
+
+	// Возвраты проводятся синхронно в RefundPaymentHandler, уведомление о них только подтверждаем
+	if strings.HasPrefix(notification.Event, "refund.") {
+		return http.StatusOK, nil
 	}
 
-	// Проверяем наличие booking_id
-	bookingIDRaw, ok := metadata["booking_id"]
+	// Проверяем наличие booking_id в metadata
+	bookingID, ok := payment.Metadata["booking_id"]
 	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Поле 'booking_id' отсутствует в 'metadata'"})
-		return
+		return http.StatusBadRequest, errors.New("Поле 'booking_id' отсутствует в 'metadata'")
 	}
 
-	// Преобразуем booking_id в строку
-	bookingID := fmt.Sprintf("%v", bookingIDRaw)
-
-	// Проверяем существование бронирования
-	if err := storage.DB.First(&booking, bookingID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Бронирование не найдено"})
-		return
+	event := WebhookEvent{
+		EventID:     webhookEventID(notification),
+		PaymentID:   payment.ID,
+		Event:       notification.Event,
+		Status:      payment.Status,
+		ProcessedAt: time.Now(),
 	}
 
-	// Обновляем статус оплаты
-	booking.PaymentStatus = paymentStatus
-	if err := storage.DB.Save(&booking).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении статуса оплаты"})
-		return
-	}
+	// status задаёт ответ, если уведомление отклонено при применении к бронированию
+	status := http.StatusOK
+	err := h.Events.Apply(ctx, event, func(repo bookings.BookingRepo, booking *bookings.Booking) error {
+		if bookingID != strconv.FormatUint(uint64(booking.ID), 10) {
+			status = http.StatusBadRequest
+			return errors.New("Платёж не относится к бронированию")
+		}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Статус оплаты обновлен"})
+		if booking.PaymentStatus == payment.Status {
+			return nil
+		}
+
+		if !canChangePaymentStatus(booking.PaymentStatus, payment.Status) {
+			// Запоздавшее уведомление подтверждаем: на ответ не 2xx платёжная система
+			// повторяет доставку ещё сутки
+			slog.WarnContext(ctx, "Уведомление со старым статусом оплаты проигнорировано", "booking_id", booking.ID, "payment_id", payment.ID, "from", booking.PaymentStatus, "to", payment.Status)
+			return nil
+		}
+
+		// Обновляем статус оплаты
+		if err := repo.SetPaymentStatus(booking, payment.Status); err != nil {
+			status = http.StatusInternalServerError
+			return errors.New("Ошибка при обновлении статуса оплаты")
+		}
+
+		// Переводим бронирование по жизненному циклу
+		var target bookings.BookingStatus
+		switch payment.Status {
+		case StatusSucceeded:
+			target = bookings.StatusConfirmed
+		case StatusCanceled:
+			target = bookings.StatusCancelled
+		default:
+			return nil
+		}
+		if target == bookings.StatusConfirmed && (booking.Status == bookings.StatusExpired || booking.Status == bookings.StatusCancelled) {
+			// Гость заплатил за бронирование, которого у него уже нет. Возврат идёт через repo:
+			// бронирование заблокировано транзакцией уведомления
+			if err := bookings.RefundLatePayment(ctx, h.gateway(), repo, booking); err != nil {
+				slog.ErrorContext(ctx, "Не удалось вернуть оплату отменённого бронирования", "booking_id", booking.ID, "payment_id", payment.ID, logging.Err(err))
+				status = http.StatusInternalServerError
+				return errors.New("Ошибка при возврате оплаты")
+			}
+			slog.WarnContext(ctx, "Оплата отменённого бронирования возвращена", "booking_id", booking.ID, "booking_status", booking.Status, "payment_id", payment.ID, "amount", booking.TotalCost)
+			return nil
+		}
+		if !bookings.CanTransition(booking.Status, target) {
+			slog.WarnContext(ctx, "Статус платежа не меняет бронирование", "booking_id", booking.ID, "booking_status", booking.Status, "payment_id", payment.ID, "payment_status", payment.Status)
+			return nil
+		}
+		if err := repo.Transition(booking, target, bookings.PaymentActor, notification.Event); err != nil {
+			status = http.StatusInternalServerError
+			return errors.New("Ошибка при обновлении статуса бронирования")
+		}
+		return nil
+	})
+	switch {
+	case status != http.StatusOK:
+		return status, err
+	case errors.Is(err, ErrEventProcessed):
+		// Уведомление уже обработано
+		return http.StatusOK, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound, errors.New("Бронирование не найдено")
+	case err != nil:
+		slog.ErrorContext(ctx, "Ошибка при сохранении уведомления", "payment_id", payment.ID, logging.Err(err))
+		return http.StatusInternalServerError, errors.New("Ошибка при сохранении уведомления")
+	}
+
+	return http.StatusOK, nil
 }
 
 type PaymentCallbackRequest struct {
-	Object PaymentObject `json:"object"` // Основной объект данных
+	Event  string        `json:"event" example:"payment.succeeded"` // Тип события
+	Object PaymentObject `json:"object"`                            // Основной объект данных
 }
 
 // PaymentObject описывает объект `object`, содержащий статус и метаданные.
 type PaymentObject struct {
-	Status   string          `json:"status" example:"succeeded"` // Статус оплаты
-	Metadata PaymentMetadata `json:"metadata"`                   // Метаданные оплаты
+	ID       string          `json:"id" example:"2d6f0a5c-000f-5000-9000-1b2c3d4e5f60"` // ID платежа
+	Status   string          `json:"status" example:"succeeded"`                        // Статус оплаты
+	Metadata PaymentMetadata `json:"metadata"`                                          // Метаданные оплаты
 }
 
 // PaymentMetadata описывает объект `metadata` с деталями бронирования.
@@ -228,25 +262,26 @@ type PaymentMetadata struct {
 
 // RefundPaymentHandler обрабатывает запрос на возврат платежа.
 // @Summary Обработка возврата платежа
-// @Description Обрабатывает запрос на возврат платежа за бронирование. Проверяет права доступа пользователя, статус оплаты и выполняет возврат через API YooKassa.
+// @Description Отменяет оплаченное бронирование и возвращает сумму по политике отмены отеля или номера. Проверяет права доступа пользователя и статус оплаты.
 // @Tags payments
 // @Accept json
 // @Produce json
 // @Param id path string true "ID бронирования"
 // @Security ApiKeyAuth
-// @Success 200 {object} response.MessageResponse "Оплата отменена"
-// @Failure 400 {object} response.ErrorResponse "Бронирование не оплачено или ID платежа отсутствует"
+// @Success 200 {object} response.CancellationResponse "Оплата возвращена"
+// @Failure 400 {object} response.ErrorResponse "Бронирование не оплачено, ID платежа отсутствует или возврат не положен"
 // @Failure 403 {object} response.ErrorResponse "У вас нет прав на отмену этого бронирования"
 // @Failure 404 {object} response.ErrorResponse "Бронирование не найдено"
+// @Failure 409 {object} response.ErrorResponse "Статус бронирования изменился или возврат уже выполняется"
 // @Failure 500 {object} response.ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 502 {object} response.ErrorResponse "Ошибка платежной системы при возврате"
 // @Router /bookings/{id}/refund [post]
-func RefundPaymentHandler(c *gin.Context) {
-	bookingID := c.Param("id")
+func (h *Handler) RefundPaymentHandler(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
 	// Находим бронирование
-	var booking bookings.Booking
-	if err := storage.DB.First(&booking, bookingID).Error; err != nil {
+	booking, err := h.booking(c.Param("id"))
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Бронирование не найдено"})
 		return
 	}
@@ -258,86 +293,88 @@ func RefundPaymentHandler(c *gin.Context) {
 	}
 
 	// Проверяем статус оплаты
-	if booking.PaymentStatus != "succeeded" {
+	if booking.PaymentStatus == StatusRefundPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Возврат по бронированию уже выполняется"})
+		return
+	}
+	if booking.PaymentStatus != StatusSucceeded {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Бронирование не оплачено"})
 		return
 	}
 
+	// Отменённое бронирование тоже допускается: повтор возврата, если прошлый не прошёл
+	if booking.Status != bookings.StatusConfirmed && booking.Status != bookings.StatusCancelled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Возврат для бронирования в этом статусе невозможен"})
+		return
+	}
+
 	// Проверяем наличие payment_id (должен быть сохранен при создании платежа)
 	if booking.PaymentID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID платежа отсутствует для данного бронирования"})
 		return
 	}
 
-	// Формируем запрос на возврат
-	refundRequest := map[string]interface{}{
-		"payment_id": booking.PaymentID,
-		"amount": map[string]interface{}{
-			"value":    fmt.Sprintf("%.2f", booking.TotalCost),
-			"currency": "RUB",
-		},
-	}
-
-	// Конвертируем запрос в JSON
-	refundBody, err := json.Marshal(refundRequest)
+	// Сумма возврата, определённая при отмене, а для неотменённого бронирования — по политике отмены
+	amount, err := bookings.RefundableAmount(h.Bookings, h.Rooms, booking, time.Now())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании запроса на возврат"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при расчёте суммы возврата"})
 		return
 	}
-
-	// Отправляем запрос на возврат
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", "https://api.yookassa.ru/v3/refunds", bytes.NewBuffer(refundBody))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании запроса на возврат"})
+	if amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "По условиям отмены возврат не положен"})
 		return
 	}
 
-	req.SetBasicAuth(os.Getenv("YOKASSA_SHOP_ID"), os.Getenv("YOKASSA_SECRET_KEY"))
-	req.Header.Set("Content-Type", "application/json")
-
-	// Добавляем уникальный Idempotence-Key
-	idempotenceKey := uuid.New().String()
-	req.Header.Set("Idempotence-Key", idempotenceKey)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подключении к API YooKassa"})
+	refund, err := bookings.CancelWithRefund(c.Request.Context(), h.gateway(), h.Bookings, h.Rooms, &booking, bookings.UserActor(userID), "Возврат оплаты")
+	if errors.Is(err, bookings.ErrStaleBooking) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Статус бронирования изменился, повторите запрос"})
 		return
 	}
-	defer resp.Body.Close()
-
-	// Обрабатываем ответ от API
-	var responseData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обработке ответа от API YooKassa"})
+	if errors.Is(err, bookings.ErrRefundInProgress) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Возврат по бронированию уже выполняется"})
 		return
 	}
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		c.JSON(resp.StatusCode, responseData)
+	if errors.Is(err, bookings.ErrRefundFailed) {
+		slog.ErrorContext(c.Request.Context(), "Ошибка при возврате платежа", "booking_id", booking.ID, "payment_id", booking.PaymentID, logging.Err(err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Ошибка платежной системы при возврате"})
 		return
 	}
-
-	// Обновляем статус бронирования
-	booking.PaymentStatus = "refunded"
-	if err := storage.DB.Save(&booking).Error; err != nil {
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении статуса бронирования"})
 		return
 	}
 
-	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
-		// Update booking status
-		booking.PaymentStatus = "refunded"
+	c.JSON(http.StatusOK, gin.H{"message": "Оплата возвращена и номер освобожден", "refund_amount": refund})
+}
 
-		// Delete the booking to free up the room
-		if err := storage.DB.Delete(&booking).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении бронирования"})
-			return
-		}
+// bookingGateway подключает платёжную систему к бронированиям
+type bookingGateway struct {
+	h *Handler
+}
 
-		c.JSON(http.StatusOK, gin.H{"message": "Оплата отменена и номер освобожден"})
-		return
+// BookingGateway возвращает реализацию bookings.PaymentGateway поверх провайдера обработчика h
+func BookingGateway(h *Handler) bookings.PaymentGateway {
+	return bookingGateway{h: h}
+}
+
+func (h *Handler) gateway() bookings.PaymentGateway {
+	return BookingGateway(h)
+}
+
+func (g bookingGateway) CreatePayment(ctx context.Context, booking *bookings.Booking) (string, string, error) {
+	payment, err := g.h.newPayment(ctx, booking)
+	if err != nil {
+		return "", "", err
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Оплата отменена"})
+	return payment.ID, payment.ConfirmationURL, nil
+}
+
+func (g bookingGateway) Refund(ctx context.Context, paymentID string, amount float64, idempotenceKey string) error {
+	_, err := g.h.Provider.Refund(ctx, RefundRequest{
+		PaymentID:      paymentID,
+		Amount:         amount,
+		Currency:       "RUB",
+		IdempotenceKey: idempotenceKey,
+	})
+	return err
 }
@@ -0,0 +1,41 @@
+package payments
+
+import (
+	"context"
+	"hotel-booking/internal/bookings"
+	"sync"
+)
+
+// MemoryEventRepo хранит журнал уведомлений в памяти; используется в тестах.
+// Уведомления применяются по одному, как под блокировкой строки бронирования в базе.
+// Изменения бронирования, сделанные до ошибки apply, не откатываются.
+type MemoryEventRepo struct {
+	bookings bookings.BookingRepo
+
+	mu     sync.Mutex
+	events map[string]WebhookEvent
+}
+
+func NewMemoryEventRepo(bookingRepo bookings.BookingRepo) *MemoryEventRepo {
+	return &MemoryEventRepo{bookings: bookingRepo, events: map[string]WebhookEvent{}}
+}
+
+func (r *MemoryEventRepo) Apply(ctx context.Context, event WebhookEvent, apply func(repo bookings.BookingRepo, booking *bookings.Booking) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.events[event.EventID]; ok {
+		return ErrEventProcessed
+	}
+
+	booking, err := r.bookings.ByPaymentID(event.PaymentID)
+	if err != nil {
+		return err
+	}
+	if err := apply(r.bookings, &booking); err != nil {
+		return err
+	}
+
+	event.ID = uint(len(r.events) + 1)
+	r.events[event.EventID] = event
+	return nil
+}
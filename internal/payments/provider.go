@@ -0,0 +1,96 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Статусы платежа, общие для всех провайдеров
+const (
+	StatusPending           = "pending"
+	StatusWaitingForCapture = "waiting_for_capture"
+	StatusSucceeded         = "succeeded"
+	StatusCanceled          = "canceled"
+	StatusRefunded          = "refunded"
+	// StatusRefundPending — возврат отправлен в платёжную систему, но ещё не отмечен в бронировании.
+	// Статус только наш: пока он стоит, второй возврат по бронированию не начинается.
+	StatusRefundPending = "refund_pending"
+)
+
+var (
+	ErrPaymentNotFound = errors.New("платёж не найден")
+	ErrInvalidWebhook  = errors.New("некорректное уведомление")
+)
+
+// PaymentProvider описывает платёжный шлюз, через который проходят оплаты и возвраты
+type PaymentProvider interface {
+	// CreatePayment создаёт платёж и возвращает его вместе со ссылкой на оплату
+	CreatePayment(ctx context.Context, req CreatePaymentRequest) (*Payment, error)
+	// GetPayment возвращает актуальное состояние платежа у провайдера
+	GetPayment(ctx context.Context, paymentID string) (*Payment, error)
+	// Refund возвращает указанную сумму по платежу
+	Refund(ctx context.Context, req RefundRequest) (*Refund, error)
+	// VerifyWebhook проверяет и разбирает уведомление, пришедшее на /payments/callback
+	VerifyWebhook(r *http.Request, body []byte) (*Notification, error)
+}
+
+type CreatePaymentRequest struct {
+	Amount      float64
+	Currency    string
+	Description string
+	ReturnURL   string
+	Metadata    map[string]string
+}
+
+type Payment struct {
+	ID              string
+	Status          string
+	Amount          float64
+	Currency        string
+	ConfirmationURL string
+	Metadata        map[string]string
+}
+
+type RefundRequest struct {
+	PaymentID string
+	Amount    float64
+	Currency  string
+	// IdempotenceKey — ключ повтора: провайдер не проводит второй возврат с тем же ключом
+	IdempotenceKey string
+}
+
+type Refund struct {
+	ID        string
+	PaymentID string
+	Status    string
+	Amount    float64
+}
+
+// Notification — разобранное уведомление провайдера об изменении платежа
+type Notification struct {
+	Event   string
+	Payment Payment
+}
+
+// Config — настройки платёжной подсистемы
+type Config struct {
+	Provider          string   // yookassa (по умолчанию) или fake
+	ShopID            string   // Для yookassa
+	SecretKey         string   // Для yookassa
+	BackendURL        string   // Адрес API, на котором fake показывает страницу оплаты
+	ReturnURL         string   // Куда провайдер возвращает гостя после оплаты
+	WebhookAllowedIPs []string // Сети, с которых принимаются уведомления, вместо адресов провайдера
+}
+
+// NewProvider создаёт платёжный провайдер (yookassa или fake), выбранный в настройках
+func NewProvider(cfg Config) (PaymentProvider, error) {
+	switch cfg.Provider {
+	case "", "yookassa":
+		return NewYooKassaProvider(cfg.ShopID, cfg.SecretKey), nil
+	case "fake":
+		return NewFakeProvider(cfg.BackendURL), nil
+	}
+	return nil, fmt.Errorf("неизвестный платёжный провайдер: %s", cfg.Provider)
+}
@@ -0,0 +1,52 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"hotel-booking/internal/bookings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrEventProcessed — уведомление с таким EventID уже обработано
+var ErrEventProcessed = errors.New("уведомление уже обработано")
+
+// EventRepo — журнал уведомлений платёжной системы
+type EventRepo interface {
+	// Apply записывает event в журнал и в той же транзакции вызывает apply для бронирования
+	// с платежом event.PaymentID, заблокированного до её конца. apply получает репозиторий
+	// бронирований, работающий в этой транзакции. Если apply вернул ошибку, событие не
+	// записывается и при повторной доставке обрабатывается заново.
+	// Уже записанное событие возвращает ErrEventProcessed без вызова apply, не найденное
+	// бронирование — gorm.ErrRecordNotFound.
+	Apply(ctx context.Context, event WebhookEvent, apply func(repo bookings.BookingRepo, booking *bookings.Booking) error) error
+}
+
+// GormEventRepo хранит журнал уведомлений в базе данных
+type GormEventRepo struct {
+	db *gorm.DB
+}
+
+func NewGormEventRepo(db *gorm.DB) *GormEventRepo {
+	return &GormEventRepo{db: db}
+}
+
+func (r *GormEventRepo) Apply(ctx context.Context, event WebhookEvent, apply func(repo bookings.BookingRepo, booking *bookings.Booking) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&event)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrEventProcessed
+		}
+
+		// Блокируем бронирование, чтобы параллельные уведомления применялись по очереди
+		var booking bookings.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("payment_id = ?", event.PaymentID).First(&booking).Error; err != nil {
+			return err
+		}
+		return apply(bookings.NewGormBookingRepo(tx), &booking)
+	})
+}
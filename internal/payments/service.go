@@ -0,0 +1,89 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotel-booking/internal/bookings"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrAlreadyPaid   = errors.New("бронирование уже оплачено")
+	ErrNotPending    = errors.New("бронирование не ожидает оплаты")
+	ErrHoldExpired   = errors.New("время на оплату бронирования истекло")
+	ErrProviderError = errors.New("ошибка платежной системы")
+)
+
+// CreateBookingPayment создаёт у провайдера платёж за бронирование, сохраняет его ID
+// в бронировании и возвращает ссылку на оплату. Если у бронирования уже есть
+// платёж, который ждёт оплаты, возвращается его ссылка: иначе гость мог бы оплатить
+// по старой ссылке платёж, о котором бронирование уже не знает.
+func (h *Handler) CreateBookingPayment(ctx context.Context, booking *bookings.Booking) (string, error) {
+	if booking.PaymentStatus == StatusSucceeded {
+		return "", ErrAlreadyPaid
+	}
+	if booking.Status != bookings.StatusPendingPayment {
+		return "", ErrNotPending
+	}
+	// Планировщик мог ещё не успеть перевести бронирование в expired
+	if booking.ExpiresAt != nil && time.Now().After(*booking.ExpiresAt) {
+		return "", ErrHoldExpired
+	}
+
+	if booking.PaymentID != "" {
+		url, err := h.existingPaymentURL(ctx, booking.PaymentID)
+		if err != nil || url != "" {
+			return url, err
+		}
+	}
+
+	payment, err := h.newPayment(ctx, booking)
+	if err != nil {
+		return "", err
+	}
+
+	// Сохраняем PaymentID
+	if err := h.Bookings.SetPaymentID(booking, payment.ID); err != nil {
+		return "", err
+	}
+	return payment.ConfirmationURL, nil
+}
+
+// newPayment создаёт у провайдера платёж на полную стоимость бронирования
+func (h *Handler) newPayment(ctx context.Context, booking *bookings.Booking) (*Payment, error) {
+	bookingID := strconv.FormatUint(uint64(booking.ID), 10)
+	payment, err := h.Provider.CreatePayment(ctx, CreatePaymentRequest{
+		Amount:      booking.TotalCost,
+		Currency:    "RUB",
+		Description: fmt.Sprintf("Оплата бронирования %s", bookingID),
+		ReturnURL:   h.ReturnURL,
+		Metadata:    map[string]string{"booking_id": bookingID}, // Указываем booking_id
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProviderError, err)
+	}
+	return payment, nil
+}
+
+// existingPaymentURL возвращает ссылку на оплату платежа paymentID, если он ещё ждёт оплаты.
+// Пустая ссылка без ошибки означает, что платёж отменён или не найден и нужен новый.
+func (h *Handler) existingPaymentURL(ctx context.Context, paymentID string) (string, error) {
+	payment, err := h.Provider.GetPayment(ctx, paymentID)
+	if errors.Is(err, ErrPaymentNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrProviderError, err)
+	}
+
+	switch payment.Status {
+	case StatusPending:
+		return payment.ConfirmationURL, nil
+	case StatusWaitingForCapture, StatusSucceeded:
+		// Гость уже заплатил, уведомление ещё не пришло
+		return "", ErrAlreadyPaid
+	}
+	return "", nil
+}
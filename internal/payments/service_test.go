@@ -0,0 +1,59 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"hotel-booking/internal/bookings"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/users"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateBookingPaymentReusesPendingPayment(t *testing.T) {
+	fake := NewFakeProvider("http://pay.test")
+
+	store := hotels.NewMemory()
+	room := hotels.Room{HotelID: 1, Price: 1000, Capacity: 2, Units: 1}
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatal(err)
+	}
+	repo := bookings.NewMemoryBookingRepo(store.Rooms(), users.NewMemoryUserRepo())
+	h := NewHandler(repo, store.Rooms(), NewMemoryEventRepo(repo), fake, Config{})
+	start := time.Now().AddDate(0, 0, 10)
+	booking := bookings.Booking{RoomID: room.ID, UserID: 7, StartDate: start, EndDate: start.AddDate(0, 0, 1), TotalCost: 1000}
+	if err := repo.Create(&booking, bookings.SystemActor, "тест"); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	first, err := h.CreateBookingPayment(ctx, &booking)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstID := booking.PaymentID
+
+	// Платёж ещё ждёт оплаты: гость получает ту же ссылку
+	again, err := h.CreateBookingPayment(ctx, &booking)
+	if err != nil || again != first || booking.PaymentID != firstID {
+		t.Fatalf("повторный запрос: %q (%s), %v; ожидалась ссылка %q (%s)", again, booking.PaymentID, err, first, firstID)
+	}
+
+	// Гость отказался от оплаты: создаётся новый платёж
+	if _, err := fake.Cancel(firstID); err != nil {
+		t.Fatal(err)
+	}
+	second, err := h.CreateBookingPayment(ctx, &booking)
+	if err != nil || second == first || booking.PaymentID == firstID || !strings.HasSuffix(second, booking.PaymentID) {
+		t.Fatalf("после отмены платежа: %q (%s), %v", second, booking.PaymentID, err)
+	}
+
+	// Новый платёж оплачен, уведомление ещё не пришло
+	if _, err := fake.Confirm(booking.PaymentID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.CreateBookingPayment(ctx, &booking); !errors.Is(err, ErrAlreadyPaid) {
+		t.Fatalf("после оплаты: %v, ожидалась ErrAlreadyPaid", err)
+	}
+}
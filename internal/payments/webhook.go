@@ -0,0 +1,101 @@
+package payments
+
+import (
+	"net"
+	"strings"
+	"time"
+)
+
+// WebhookEvent — уже обработанное уведомление платёжной системы.
+// Повторная доставка того же события не меняет бронирование.
+type WebhookEvent struct {
+	ID          uint      `gorm:"primarykey"`
+	EventID     string    `gorm:"type:varchar(150);uniqueIndex;not null"`
+	PaymentID   string    `gorm:"type:varchar(50);not null"`
+	Event       string    `gorm:"type:varchar(50)"`
+	Status      string    `gorm:"type:varchar(20)"`
+	ProcessedAt time.Time `gorm:"not null"`
+}
+
+// webhookSource реализуют провайдеры, которые присылают уведомления только с известных адресов
+type webhookSource interface {
+	WebhookSourceRanges() []string
+}
+
+// paymentStatusRank задаёт порядок статусов платежа: статус нельзя сменить на статус с меньшим рангом
+var paymentStatusRank = map[string]int{
+	StatusPending:           0,
+	StatusWaitingForCapture: 1,
+	StatusSucceeded:         2,
+	StatusCanceled:          2,
+	StatusRefundPending:     3,
+	StatusRefunded:          4,
+}
+
+// canChangePaymentStatus проверяет, что переход между статусами платежа идёт только вперёд
+func canChangePaymentStatus(from, to string) bool {
+	toRank, ok := paymentStatusRank[to]
+	if !ok {
+		return false
+	}
+	if from == "" {
+		return true
+	}
+	fromRank, ok := paymentStatusRank[from]
+	if !ok {
+		return true
+	}
+	return toRank > fromRank
+}
+
+func webhookEventID(notification *Notification) string {
+	return notification.Event + ":" + notification.Payment.ID + ":" + notification.Payment.Status
+}
+
+// webhookAllowList возвращает сети, с которых принимаются уведомления.
+// Handler.WebhookAllowedIPs переопределяет адреса провайдера; пустой результат отключает проверку.
+func (h *Handler) webhookAllowList() []*net.IPNet {
+	ranges := h.WebhookAllowedIPs
+	if source, ok := h.Provider.(webhookSource); ok && len(ranges) == 0 {
+		ranges = source.WebhookSourceRanges()
+	}
+
+	var networks []*net.IPNet
+	for _, r := range ranges {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		if !strings.Contains(r, "/") {
+			if strings.Contains(r, ":") {
+				r += "/128"
+			} else {
+				r += "/32"
+			}
+		}
+		_, network, err := net.ParseCIDR(r)
+		if err != nil {
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+func (h *Handler) isWebhookSourceAllowed(clientIP string) bool {
+	networks := h.webhookAllowList()
+	if len(networks) == 0 {
+		return true
+	}
+
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
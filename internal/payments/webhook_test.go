@@ -0,0 +1,219 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"hotel-booking/internal/bookings"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/users"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCanChangePaymentStatus(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"", StatusPending, true},
+		{"", StatusSucceeded, true},
+		{StatusPending, StatusWaitingForCapture, true},
+		{StatusPending, StatusSucceeded, true},
+		{StatusPending, StatusCanceled, true},
+		{StatusWaitingForCapture, StatusSucceeded, true},
+		{StatusSucceeded, StatusRefundPending, true},
+		{StatusRefundPending, StatusRefunded, true},
+		{StatusSucceeded, StatusRefunded, true},
+		{"unknown", StatusSucceeded, true},
+		{StatusPending, "unknown", false},
+		{StatusWaitingForCapture, StatusPending, false},
+		{StatusSucceeded, StatusWaitingForCapture, false},
+		{StatusSucceeded, StatusCanceled, false},
+		{StatusCanceled, StatusSucceeded, false},
+		{StatusSucceeded, StatusSucceeded, false},
+		{StatusRefunded, StatusSucceeded, false},
+		{StatusRefunded, StatusRefundPending, false},
+	}
+	for _, tt := range tests {
+		if got := canChangePaymentStatus(tt.from, tt.to); got != tt.want {
+			t.Errorf("canChangePaymentStatus(%q, %q) = %v, ожидалось %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestIsWebhookSourceAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider PaymentProvider
+		allowed  []string
+		clientIP string
+		want     bool
+	}{
+		{"адрес из сети ЮKassa", NewYooKassaProvider("", ""), nil, "185.71.76.10", true},
+		{"отдельный адрес ЮKassa", NewYooKassaProvider("", ""), nil, "77.75.156.11", true},
+		{"IPv6 ЮKassa", NewYooKassaProvider("", ""), nil, "2a02:5180::1", true},
+		{"чужой адрес", NewYooKassaProvider("", ""), nil, "203.0.113.5", false},
+		{"нераспознанный адрес", NewYooKassaProvider("", ""), nil, "", false},
+		{"настройка заменяет адреса провайдера", NewYooKassaProvider("", ""), []string{"203.0.113.0/24"}, "185.71.76.10", false},
+		{"адрес из настройки", NewYooKassaProvider("", ""), []string{" 203.0.113.5 "}, "203.0.113.5", true},
+		{"некорректные записи пропускаются", NewYooKassaProvider("", ""), []string{"bad", "198.51.100.0/24"}, "198.51.100.7", true},
+		{"провайдер без адресов не проверяет источник", NewFakeProvider("http://pay.test"), nil, "203.0.113.5", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{Provider: tt.provider, WebhookAllowedIPs: tt.allowed}
+			if got := h.isWebhookSourceAllowed(tt.clientIP); got != tt.want {
+				t.Fatalf("isWebhookSourceAllowed(%q) = %v, ожидалось %v", tt.clientIP, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaymentCallbackTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{Provider: NewFakeProvider("http://pay.test"), WebhookAllowedIPs: []string{"185.71.76.0/27"}}
+	router := gin.New()
+	if err := router.SetTrustedProxies([]string{"10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	router.POST("/payments/callback", h.PaymentCallbackHandler)
+
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		forwardedFor  string
+		wantForbidden bool
+	}{
+		{"прямой запрос с адреса платёжной системы", "185.71.76.10:443", "", false},
+		{"прямой запрос с чужого адреса", "203.0.113.5:443", "", true},
+		{"через доверенный прокси", "10.0.0.1:80", "185.71.76.10", false},
+		{"через доверенный прокси с чужого адреса", "10.0.0.1:80", "203.0.113.5", true},
+		{"подделанный заголовок без прокси", "203.0.113.5:443", "185.71.76.10", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/payments/callback", strings.NewReader("{}"))
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Пропущенный запрос доходит до проверки тела и отклоняется как некорректный
+			if forbidden := w.Code == http.StatusForbidden; forbidden != tt.wantForbidden {
+				t.Fatalf("код %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// newWebhookTest создаёт обработчик с фиктивным провайдером и бронирование с платежом
+func newWebhookTest(t *testing.T) (*Handler, *FakeProvider, *bookings.MemoryBookingRepo, bookings.Booking) {
+	t.Helper()
+	fake := NewFakeProvider("http://pay.test")
+	store := hotels.NewMemory()
+	room := hotels.Room{HotelID: 1, Price: 1000, Capacity: 2, Units: 1}
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatal(err)
+	}
+	repo := bookings.NewMemoryBookingRepo(store.Rooms(), users.NewMemoryUserRepo())
+	h := NewHandler(repo, store.Rooms(), NewMemoryEventRepo(repo), fake, Config{})
+	start := time.Now().AddDate(0, 0, 10)
+	booking := bookings.Booking{RoomID: room.ID, UserID: 7, StartDate: start, EndDate: start.AddDate(0, 0, 1), TotalCost: 1000}
+	if err := repo.Create(&booking, bookings.SystemActor, "тест"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.CreateBookingPayment(context.Background(), &booking); err != nil {
+		t.Fatal(err)
+	}
+	return h, fake, repo, booking
+}
+
+// notification возвращает уведомление о платеже в статусе status
+func notification(t *testing.T, fake *FakeProvider, paymentID, status string) *Notification {
+	t.Helper()
+	payment, err := fake.GetPayment(context.Background(), paymentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payment.Status = status
+	return &Notification{Event: "payment." + status, Payment: *payment}
+}
+
+func TestApplyNotificationStatusOrder(t *testing.T) {
+	tests := []struct {
+		name              string
+		paymentStatus     string
+		notified          string
+		wantPaymentStatus string
+		wantStatus        bookings.BookingStatus
+	}{
+		{"оплата подтверждает бронирование", StatusPending, StatusSucceeded, StatusSucceeded, bookings.StatusConfirmed},
+		{"ожидание подтверждения", StatusPending, StatusWaitingForCapture, StatusWaitingForCapture, bookings.StatusPendingPayment},
+		{"отказ от оплаты отменяет бронирование", StatusPending, StatusCanceled, StatusCanceled, bookings.StatusCancelled},
+		{"запоздавшая оплата после возврата", StatusRefunded, StatusSucceeded, StatusRefunded, bookings.StatusPendingPayment},
+		{"запоздавшее ожидание после оплаты", StatusSucceeded, StatusWaitingForCapture, StatusSucceeded, bookings.StatusPendingPayment},
+		{"отмена после оплаты", StatusSucceeded, StatusCanceled, StatusSucceeded, bookings.StatusPendingPayment},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, fake, repo, booking := newWebhookTest(t)
+			if tt.paymentStatus != booking.PaymentStatus {
+				if err := repo.SetPaymentStatus(&booking, tt.paymentStatus); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Откат статуса назад подтверждается 200, чтобы платёжная система не повторяла доставку
+			status, err := h.applyNotification(context.Background(), notification(t, fake, booking.PaymentID, tt.notified))
+			if status != http.StatusOK || err != nil {
+				t.Fatalf("applyNotification: %d, %v", status, err)
+			}
+			stored, _ := repo.ByID(booking.ID)
+			if stored.PaymentStatus != tt.wantPaymentStatus || stored.Status != tt.wantStatus {
+				t.Fatalf("бронирование: статус %s, оплата %s; ожидалось %s, %s", stored.Status, stored.PaymentStatus, tt.wantStatus, tt.wantPaymentStatus)
+			}
+		})
+	}
+}
+
+func TestApplyNotificationDuplicateEvent(t *testing.T) {
+	h, fake, repo, booking := newWebhookTest(t)
+	ctx := context.Background()
+	paid, err := fake.Confirm(booking.PaymentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status, err := h.applyNotification(ctx, paid); status != http.StatusOK || err != nil {
+		t.Fatalf("первое уведомление: %d, %v", status, err)
+	}
+	events, _ := repo.Events(booking.ID)
+
+	// Событие с тем же ID не применяется повторно
+	event := WebhookEvent{EventID: webhookEventID(paid), PaymentID: booking.PaymentID}
+	err = h.Events.Apply(ctx, event, func(bookings.BookingRepo, *bookings.Booking) error {
+		t.Fatal("повторное событие применено к бронированию")
+		return nil
+	})
+	if !errors.Is(err, ErrEventProcessed) {
+		t.Fatalf("повторное событие: %v, ожидалась ErrEventProcessed", err)
+	}
+	if status, err := h.applyNotification(ctx, paid); status != http.StatusOK || err != nil {
+		t.Fatalf("повторное уведомление: %d, %v", status, err)
+	}
+	if again, _ := repo.Events(booking.ID); len(again) != len(events) {
+		t.Fatalf("повторное уведомление записало события: %d, было %d", len(again), len(events))
+	}
+
+	// Уведомление о другом статусе того же платежа — новое событие
+	if webhookEventID(notification(t, fake, booking.PaymentID, StatusRefunded)) == webhookEventID(paid) {
+		t.Fatal("у уведомлений с разными статусами одинаковый ID события")
+	}
+}
@@ -0,0 +1,247 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hotel-booking/internal/logging"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const yooKassaAPIURL = "https://api.yookassa.ru/v3"
+
+// Адреса, с которых ЮKassa отправляет уведомления
+var yooKassaWebhookRanges = []string{
+	"185.71.76.0/27",
+	"185.71.77.0/27",
+	"77.75.153.0/25",
+	"77.75.156.11",
+	"77.75.156.35",
+	"77.75.154.128/25",
+	"2a02:5180::/32",
+}
+
+// YooKassaProvider работает с API ЮKassa
+type YooKassaProvider struct {
+	shopID    string
+	secretKey string
+	baseURL   string
+	client    *http.Client
+}
+
+func NewYooKassaProvider(shopID, secretKey string) *YooKassaProvider {
+	return &YooKassaProvider{
+		shopID:    shopID,
+		secretKey: secretKey,
+		baseURL:   yooKassaAPIURL,
+		client:    &http.Client{Timeout: 15 * time.Second, Transport: &logging.Transport{}},
+	}
+}
+
+type yooKassaAmount struct {
+	Value    string `json:"value"`
+	Currency string `json:"currency"`
+}
+
+type yooKassaPaymentRequest struct {
+	Amount       yooKassaAmount `json:"amount"`
+	Confirmation struct {
+		Type      string `json:"type"`
+		ReturnURL string `json:"return_url"`
+	} `json:"confirmation"`
+	Capture     bool              `json:"capture"`
+	Description string            `json:"description"`
+	Metadata    map[string]string `json:"metadata"`
+}
+
+type yooKassaPayment struct {
+	ID           string         `json:"id"`
+	Status       string         `json:"status"`
+	Amount       yooKassaAmount `json:"amount"`
+	Confirmation struct {
+		Type            string `json:"type"`
+		ConfirmationURL string `json:"confirmation_url"`
+	} `json:"confirmation"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+type yooKassaRefundRequest struct {
+	PaymentID string         `json:"payment_id"`
+	Amount    yooKassaAmount `json:"amount"`
+}
+
+type yooKassaRefund struct {
+	ID        string         `json:"id"`
+	PaymentID string         `json:"payment_id"`
+	Status    string         `json:"status"`
+	Amount    yooKassaAmount `json:"amount"`
+}
+
+type yooKassaNotification struct {
+	Type   string `json:"type"`
+	Event  string `json:"event"`
+	Object struct {
+		ID        string `json:"id"`
+		PaymentID string `json:"payment_id"` // заполнен в уведомлениях о возвратах
+	} `json:"object"`
+}
+
+func (p *YooKassaProvider) CreatePayment(ctx context.Context, req CreatePaymentRequest) (*Payment, error) {
+	body := yooKassaPaymentRequest{
+		Amount:      yooKassaAmount{Value: formatAmount(req.Amount), Currency: req.Currency},
+		Capture:     true,
+		Description: req.Description,
+		Metadata:    req.Metadata,
+	}
+	body.Confirmation.Type = "redirect"
+	body.Confirmation.ReturnURL = req.ReturnURL
+
+	var payment yooKassaPayment
+	if err := p.do(ctx, http.MethodPost, "/payments", "", body, &payment); err != nil {
+		return nil, err
+	}
+	if payment.ID == "" {
+		return nil, fmt.Errorf("ЮKassa не вернула ID платежа")
+	}
+	if payment.Confirmation.ConfirmationURL == "" {
+		return nil, fmt.Errorf("ЮKassa не вернула ссылку на оплату")
+	}
+
+	return payment.toPayment(), nil
+}
+
+func (p *YooKassaProvider) GetPayment(ctx context.Context, paymentID string) (*Payment, error) {
+	var payment yooKassaPayment
+	if err := p.do(ctx, http.MethodGet, "/payments/"+paymentID, "", nil, &payment); err != nil {
+		return nil, err
+	}
+	return payment.toPayment(), nil
+}
+
+func (p *YooKassaProvider) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	body := yooKassaRefundRequest{
+		PaymentID: req.PaymentID,
+		Amount:    yooKassaAmount{Value: formatAmount(req.Amount), Currency: req.Currency},
+	}
+
+	var refund yooKassaRefund
+	if err := p.do(ctx, http.MethodPost, "/refunds", req.IdempotenceKey, body, &refund); err != nil {
+		return nil, err
+	}
+
+	amount, _ := strconv.ParseFloat(refund.Amount.Value, 64)
+	return &Refund{
+		ID:        refund.ID,
+		PaymentID: refund.PaymentID,
+		Status:    refund.Status,
+		Amount:    amount,
+	}, nil
+}
+
+// VerifyWebhook не доверяет телу уведомления: платёж перезапрашивается у ЮKassa,
+// и в результат попадает его актуальное состояние
+func (p *YooKassaProvider) VerifyWebhook(r *http.Request, body []byte) (*Notification, error) {
+	var notification yooKassaNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, ErrInvalidWebhook
+	}
+	if notification.Type != "notification" || notification.Object.ID == "" {
+		return nil, ErrInvalidWebhook
+	}
+
+	paymentID := notification.Object.ID
+	if notification.Object.PaymentID != "" {
+		paymentID = notification.Object.PaymentID
+	}
+
+	payment, err := p.GetPayment(r.Context(), paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	return &Notification{
+		Event:   notification.Event,
+		Payment: *payment,
+	}, nil
+}
+
+func (p *YooKassaProvider) WebhookSourceRanges() []string {
+	return yooKassaWebhookRanges
+}
+
+// do выполняет запрос к API ЮKassa и разбирает ответ в out. POST-запросы отправляются
+// с Idempotence-Key: переданным idempotenceKey или, если он пуст, случайным.
+func (p *YooKassaProvider) do(ctx context.Context, method, path, idempotenceKey string, in, out interface{}) error {
+	var reqBody io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewBuffer(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.shopID, p.secretKey)
+	req.Header.Set("Content-Type", "application/json")
+	if method == http.MethodPost {
+		if idempotenceKey == "" {
+			idempotenceKey = uuid.New().String()
+		}
+		req.Header.Set("Idempotence-Key", idempotenceKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка при подключении к ЮKassa: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения ответа ЮKassa: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrPaymentNotFound
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("ЮKassa вернула статус %d: %s", resp.StatusCode, data)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("ошибка обработки ответа ЮKassa: %w", err)
+	}
+	return nil
+}
+
+func (p yooKassaPayment) toPayment() *Payment {
+	amount, _ := strconv.ParseFloat(p.Amount.Value, 64)
+
+	metadata := make(map[string]string, len(p.Metadata))
+	for k, v := range p.Metadata {
+		metadata[k] = fmt.Sprintf("%v", v)
+	}
+
+	return &Payment{
+		ID:              p.ID,
+		Status:          p.Status,
+		Amount:          amount,
+		Currency:        p.Amount.Currency,
+		ConfirmationURL: p.Confirmation.ConfirmationURL,
+		Metadata:        metadata,
+	}
+}
+
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
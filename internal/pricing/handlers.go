@@ -0,0 +1,399 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"hotel-booking/internal/hotels"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler — обработчики правил цены, календаря цен и расчёта стоимости.
+// Данные читаются только через репозитории, которые подключаются в main.
+type Handler struct {
+	Prices PriceRepo
+	Hotels hotels.HotelRepo
+	Rooms  hotels.RoomRepo
+}
+
+func NewHandler(prices PriceRepo, hotelRepo hotels.HotelRepo, rooms hotels.RoomRepo) *Handler {
+	return &Handler{Prices: prices, Hotels: hotelRepo, Rooms: rooms}
+}
+
+// parseID разбирает ID из параметра пути
+func parseID(value string) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 64)
+	return uint(id), err
+}
+
+type PriceRuleInput struct {
+	Name       string  `json:"name"`
+	StartDate  string  `json:"start_date" example:"2025-06-01"` // Начало периода (YYYY-MM-DD), необязательно
+	EndDate    string  `json:"end_date" example:"2025-08-31"`   // Конец периода включительно (YYYY-MM-DD), необязательно
+	DaysOfWeek []int   `json:"days_of_week" example:"5,6"`      // Дни недели 1-7 (пн-вс), пусто — все дни
+	Price      float64 `json:"price" binding:"required,gt=0"`
+	MinStay    int     `json:"min_stay" binding:"min=0"`
+	Priority   int     `json:"priority"`
+}
+
+type PriceOverrideInput struct {
+	Prices []PriceOverrideEntry `json:"prices" binding:"required,dive"`
+}
+
+type PriceOverrideEntry struct {
+	Date  string  `json:"date" binding:"required" example:"2025-12-31"`
+	Price float64 `json:"price" binding:"required,gt=0"`
+}
+
+// ownedRoom загружает номер и проверяет, что он принадлежит текущему владельцу.
+// При ошибке сам отвечает клиенту и возвращает false.
+func (h *Handler) ownedRoom(c *gin.Context) (hotels.Room, bool) {
+	ownerID := c.GetUint("user_id")
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
+		return hotels.Room{}, false
+	}
+	room, err := h.Rooms.ByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
+		return hotels.Room{}, false
+	}
+
+	hotel, err := h.Hotels.ByID(room.HotelID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Отель не найден"})
+		return hotels.Room{}, false
+	}
+
+	if hotel.OwnerID != ownerID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Номер не принадлежит вам"})
+		return hotels.Room{}, false
+	}
+
+	return room, true
+}
+
+func parseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// @Security BearerAuth
+// CreatePriceRuleHandler godoc
+// @Summary Создание правила цены
+// @Description Добавляет правило цены за ночь для номера: сезон, дни недели, минимальный срок проживания. Доступно только владельцу.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param id path int true "ID номера"
+// @Param input body PriceRuleInput true "Правило цены"
+// @Success 201 {object} response.PriceRuleResponse "Созданное правило"
+// @Failure 400 {object} response.ErrorResponse "Ошибка валидации"
+// @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} response.ErrorResponse "Номер не найден"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при создании правила"
+// @Router /owners/rooms/{id}/price-rules [post]
+func (h *Handler) CreatePriceRuleHandler(c *gin.Context) {
+	room, ok := h.ownedRoom(c)
+	if !ok {
+		return
+	}
+
+	var input PriceRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	startDate, err := parseOptionalDate(input.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректная дата начала"})
+		return
+	}
+	endDate, err := parseOptionalDate(input.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректная дата окончания"})
+		return
+	}
+	if startDate != nil && endDate != nil && endDate.Before(*startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Дата окончания раньше даты начала"})
+		return
+	}
+
+	days := make([]string, 0, len(input.DaysOfWeek))
+	for _, d := range input.DaysOfWeek {
+		if d < 1 || d > 7 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Некорректный день недели: %d", d)})
+			return
+		}
+		days = append(days, strconv.Itoa(d))
+	}
+
+	rule := PriceRule{
+		RoomID:     room.ID,
+		Name:       input.Name,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		DaysOfWeek: strings.Join(days, ","),
+		Price:      input.Price,
+		MinStay:    input.MinStay,
+		Priority:   input.Priority,
+	}
+
+	if err := h.Prices.CreateRule(&rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании правила"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// @Security BearerAuth
+// GetPriceRulesHandler godoc
+// @Summary Правила цены номера
+// @Description Возвращает правила цены номера. Доступно только владельцу.
+// @Tags pricing
+// @Produce json
+// @Param id path int true "ID номера"
+// @Success 200 {array} response.PriceRuleResponse "Правила цены"
+// @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} response.ErrorResponse "Номер не найден"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при получении правил"
+// @Router /owners/rooms/{id}/price-rules [get]
+func (h *Handler) GetPriceRulesHandler(c *gin.Context) {
+	room, ok := h.ownedRoom(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.Prices.Rules(room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении правил"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// @Security BearerAuth
+// DeletePriceRuleHandler godoc
+// @Summary Удаление правила цены
+// @Description Удаляет правило цены номера. Доступно только владельцу.
+// @Tags pricing
+// @Produce json
+// @Param id path int true "ID номера"
+// @Param rule_id path int true "ID правила"
+// @Success 200 {object} response.MessageResponse "Правило удалено"
+// @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} response.ErrorResponse "Правило не найдено"
+// @Router /owners/rooms/{id}/price-rules/{rule_id} [delete]
+func (h *Handler) DeletePriceRuleHandler(c *gin.Context) {
+	room, ok := h.ownedRoom(c)
+	if !ok {
+		return
+	}
+
+	ruleID, err := parseID(c.Param("rule_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Правило не найдено"})
+		return
+	}
+	deleted, err := h.Prices.DeleteRule(room.ID, ruleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении правила"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Правило не найдено"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Правило удалено"})
+}
+
+// @Security BearerAuth
+// SetPriceOverridesHandler godoc
+// @Summary Цены на конкретные ночи
+// @Description Задаёт цены на отдельные даты в календаре номера. Цена из календаря важнее правил. Доступно только владельцу.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param id path int true "ID номера"
+// @Param input body PriceOverrideInput true "Цены по датам"
+// @Success 200 {object} response.MessageResponse "Цены сохранены"
+// @Failure 400 {object} response.ErrorResponse "Ошибка валидации"
+// @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} response.ErrorResponse "Номер не найден"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при сохранении цен"
+// @Router /owners/rooms/{id}/prices [put]
+func (h *Handler) SetPriceOverridesHandler(c *gin.Context) {
+	room, ok := h.ownedRoom(c)
+	if !ok {
+		return
+	}
+
+	var input PriceOverrideInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	overrides := make([]PriceOverride, 0, len(input.Prices))
+	for _, entry := range input.Prices {
+		date, err := time.Parse("2006-01-02", entry.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Некорректная дата: %s", entry.Date)})
+			return
+		}
+		overrides = append(overrides, PriceOverride{RoomID: room.ID, Date: date, Price: entry.Price})
+	}
+
+	if err := h.Prices.SetOverrides(overrides); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении цен"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Цены сохранены"})
+}
+
+// @Security BearerAuth
+// GetPriceOverridesHandler godoc
+// @Summary Календарь цен номера
+// @Description Возвращает цены, заданные на отдельные даты. Доступно только владельцу.
+// @Tags pricing
+// @Produce json
+// @Param id path int true "ID номера"
+// @Success 200 {array} response.PriceOverrideResponse "Цены по датам"
+// @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} response.ErrorResponse "Номер не найден"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при получении цен"
+// @Router /owners/rooms/{id}/prices [get]
+func (h *Handler) GetPriceOverridesHandler(c *gin.Context) {
+	room, ok := h.ownedRoom(c)
+	if !ok {
+		return
+	}
+
+	overrides, err := h.Prices.Overrides(room.ID, time.Time{}, time.Time{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении цен"})
+		return
+	}
+
+	c.JSON(http.StatusOK, overrides)
+}
+
+// @Security BearerAuth
+// DeletePriceOverrideHandler godoc
+// @Summary Удаление цены на дату
+// @Description Удаляет цену из календаря номера, ночь снова считается по правилам. Доступно только владельцу.
+// @Tags pricing
+// @Produce json
+// @Param id path int true "ID номера"
+// @Param date path string true "Дата (YYYY-MM-DD)"
+// @Success 200 {object} response.MessageResponse "Цена удалена"
+// @Failure 400 {object} response.ErrorResponse "Некорректная дата"
+// @Failure 403 {object} response.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} response.ErrorResponse "Цена на эту дату не задана"
+// @Router /owners/rooms/{id}/prices/{date} [delete]
+func (h *Handler) DeletePriceOverrideHandler(c *gin.Context) {
+	room, ok := h.ownedRoom(c)
+	if !ok {
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректная дата"})
+		return
+	}
+
+	deleted, err := h.Prices.DeleteOverride(room.ID, date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении цены"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Цена на эту дату не задана"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Цена удалена"})
+}
+
+// GetRoomQuoteHandler godoc
+// @Summary Расчёт стоимости проживания
+// @Description Возвращает цены по ночам, налоги, сборы и итоговую стоимость проживания в номере. Ничего не бронирует; при бронировании используется тот же расчёт.
+// @Tags pricing
+// @Produce json
+// @Param id path int true "ID номера"
+// @Param start_date query string true "Дата заезда (YYYY-MM-DD)"
+// @Param end_date query string true "Дата выезда (YYYY-MM-DD)"
+// @Param guests query int false "Количество гостей, по умолчанию 1"
+// @Success 200 {object} response.QuoteResponse "Расчёт стоимости"
+// @Failure 400 {object} response.ErrorResponse "Некорректные параметры"
+// @Failure 404 {object} response.ErrorResponse "Номер не найден"
+// @Failure 500 {object} response.ErrorResponse "Ошибка при расчёте стоимости"
+// @Router /rooms/{id}/quote [get]
+func (h *Handler) GetRoomQuoteHandler(c *gin.Context) {
+	startDate, err := time.Parse("2006-01-02", c.Query("start_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректная дата заезда"})
+		return
+	}
+	endDate, err := time.Parse("2006-01-02", c.Query("end_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректная дата выезда"})
+		return
+	}
+	if len(Nights(startDate, endDate)) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Дата выезда должна быть позже даты заезда"})
+		return
+	}
+
+	guests, err := strconv.Atoi(c.DefaultQuery("guests", "1"))
+	if err != nil || guests < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректное количество гостей"})
+		return
+	}
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
+		return
+	}
+	room, err := h.Rooms.ByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Номер не найден"})
+		return
+	}
+
+	if guests > room.Capacity {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Номер вмещает не более %d гостей", room.Capacity)})
+		return
+	}
+
+	quote, err := QuoteRoom(h.Prices, h.Hotels, room, startDate, endDate, guests)
+	var minStayErr *MinStayError
+	if errors.As(err, &minStayErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Минимальный срок проживания — %d ноч.", minStayErr.MinStay)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при расчёте стоимости"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
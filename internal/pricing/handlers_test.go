@@ -0,0 +1,56 @@
+package pricing
+
+import (
+	"encoding/json"
+	"hotel-booking/internal/hotels"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRoomIDMustBeNumeric(t *testing.T) {
+	store := hotels.NewMemory()
+	hotel := hotels.Hotel{Name: "Тестовый отель", OwnerID: 1}
+	if err := store.Hotels().Create(&hotel); err != nil {
+		t.Fatal(err)
+	}
+	room := hotels.Room{HotelID: hotel.ID, Price: 1000, Capacity: 2}
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(NewMemoryPriceRepo(), store.Hotels(), store.Rooms())
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/rooms/:id/quote", h.GetRoomQuoteHandler)
+	r.GET("/owners/rooms/:id/price-rules", func(c *gin.Context) {
+		c.Set("user_id", hotel.OwnerID)
+	}, h.GetPriceRulesHandler)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := serve("/rooms/2/quote?start_date=2025-06-01&end_date=2025-06-03")
+	if w.Code != http.StatusOK {
+		t.Fatalf("расчёт стоимости: код %d, %s", w.Code, w.Body.String())
+	}
+	var quote Quote
+	if err := json.Unmarshal(w.Body.Bytes(), &quote); err != nil || quote.Total != 2000 {
+		t.Fatalf("расчёт стоимости: %+v, %v", quote, err)
+	}
+
+	// ID из пути не должен попадать в запрос как есть
+	for _, id := range []string{"1%20OR%201=1", "0)%20OR%20(SELECT%20true", "2abc", "-1"} {
+		if w := serve("/rooms/" + id + "/quote?start_date=2025-06-01&end_date=2025-06-03"); w.Code != http.StatusNotFound {
+			t.Errorf("расчёт для номера %q: код %d, ожидался 404", id, w.Code)
+		}
+		if w := serve("/owners/rooms/" + id + "/price-rules"); w.Code != http.StatusNotFound {
+			t.Errorf("правила номера %q: код %d, ожидался 404", id, w.Code)
+		}
+	}
+}
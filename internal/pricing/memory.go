@@ -0,0 +1,114 @@
+package pricing
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryPriceRepo хранит правила и календарь цен в памяти; используется в тестах
+type MemoryPriceRepo struct {
+	mu        sync.Mutex
+	nextID    uint
+	rules     []PriceRule
+	overrides map[uint]map[string]PriceOverride
+}
+
+func NewMemoryPriceRepo() *MemoryPriceRepo {
+	return &MemoryPriceRepo{overrides: map[uint]map[string]PriceOverride{}}
+}
+
+func (r *MemoryPriceRepo) id() uint {
+	r.nextID++
+	return r.nextID
+}
+
+func (r *MemoryPriceRepo) Rules(roomID uint) ([]PriceRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var rules []PriceRule
+	for _, rule := range r.rules {
+		if rule.RoomID == roomID {
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority > rules[j].Priority
+		}
+		return rules[i].ID > rules[j].ID
+	})
+	return rules, nil
+}
+
+func (r *MemoryPriceRepo) CreateRule(rule *PriceRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	rule.ID = r.id()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	r.rules = append(r.rules, *rule)
+	return nil
+}
+
+func (r *MemoryPriceRepo) DeleteRule(roomID, ruleID uint) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, rule := range r.rules {
+		if rule.ID == ruleID && rule.RoomID == roomID {
+			r.rules = append(r.rules[:i], r.rules[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *MemoryPriceRepo) Overrides(roomID uint, from, to time.Time) ([]PriceOverride, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var overrides []PriceOverride
+	for _, override := range r.overrides[roomID] {
+		if !from.IsZero() && override.Date.Before(from) || !to.IsZero() && override.Date.After(to) {
+			continue
+		}
+		overrides = append(overrides, override)
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Date.Before(overrides[j].Date) })
+	return overrides, nil
+}
+
+func (r *MemoryPriceRepo) SetOverrides(overrides []PriceOverride) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, override := range overrides {
+		byDate := r.overrides[override.RoomID]
+		if byDate == nil {
+			byDate = map[string]PriceOverride{}
+			r.overrides[override.RoomID] = byDate
+		}
+		key := override.Date.Format("2006-01-02")
+		if existing, ok := byDate[key]; ok {
+			override.ID = existing.ID
+			override.CreatedAt = existing.CreatedAt
+		} else {
+			override.ID = r.id()
+			override.CreatedAt = now
+		}
+		override.UpdatedAt = now
+		byDate[key] = override
+	}
+	return nil
+}
+
+func (r *MemoryPriceRepo) DeleteOverride(roomID uint, date time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := date.Format("2006-01-02")
+	if _, ok := r.overrides[roomID][key]; !ok {
+		return false, nil
+	}
+	delete(r.overrides[roomID], key)
+	return true, nil
+}
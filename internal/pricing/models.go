@@ -0,0 +1,31 @@
+package pricing
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PriceRule — правило цены за ночь для типа номера: сезон, дни недели, минимальный срок проживания.
+// Если на ночь подходит несколько правил, действует правило с большим Priority.
+type PriceRule struct {
+	gorm.Model
+	RoomID     uint       `gorm:"not null;index"`    // ID номера
+	Name       string     `gorm:"type:varchar(100)"` // Название (высокий сезон, выходные и т.д.)
+	StartDate  *time.Time `gorm:"type:date"`         // Начало периода, пусто — без ограничения
+	EndDate    *time.Time `gorm:"type:date"`         // Конец периода включительно, пусто — без ограничения
+	DaysOfWeek string     `gorm:"type:varchar(20)"`  // Дни недели 1-7 (пн-вс) через запятую, пусто — все дни
+	Price      float64    `gorm:"not null"`          // Цена за ночь
+	MinStay    int        `gorm:"default:0"`         // Минимальное количество ночей
+	Priority   int        `gorm:"default:0"`         // Приоритет среди пересекающихся правил
+}
+
+// PriceOverride — цена на конкретную ночь из календаря, важнее любых правил
+type PriceOverride struct {
+	ID        uint      `gorm:"primarykey"`
+	RoomID    uint      `gorm:"not null;uniqueIndex:idx_price_overrides_room_date"`
+	Date      time.Time `gorm:"type:date;not null;uniqueIndex:idx_price_overrides_room_date"`
+	Price     float64   `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
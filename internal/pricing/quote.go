@@ -0,0 +1,198 @@
+package pricing
+
+import (
+	"fmt"
+	"hotel-booking/internal/hotels"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Источник цены за ночь
+const (
+	SourceBase     = "base"     // базовая цена номера
+	SourceRule     = "rule"     // правило PriceRule
+	SourceOverride = "override" // цена из календаря
+)
+
+// MinStayError — период короче минимального срока проживания
+type MinStayError struct {
+	MinStay int
+}
+
+func (e *MinStayError) Error() string {
+	return fmt.Sprintf("минимальный срок проживания — %d ноч.", e.MinStay)
+}
+
+type NightPrice struct {
+	Date       time.Time `json:"date"`
+	Price      float64   `json:"price"` // Цена ночи, включая доплату за гостей
+	Source     string    `json:"source"`
+	RuleID     *uint     `json:"rule_id,omitempty"`
+	ExtraGuest float64   `json:"extra_guest"` // Доплата за гостей сверх base_occupancy
+}
+
+type Quote struct {
+	RoomID        uint         `json:"room_id"`
+	Nights        int          `json:"nights"`
+	Guests        int          `json:"guests"`
+	NightlyPrices []NightPrice `json:"nightly_prices"`
+	Subtotal      float64      `json:"subtotal"` // Сумма цен за ночи
+	Taxes         float64      `json:"taxes"`    // Налог отеля от Subtotal
+	Fees          float64      `json:"fees"`     // Сервисный сбор отеля
+	Total         float64      `json:"total"`    // Итого к оплате
+}
+
+// Date возвращает календарную дату t в её собственном часовом поясе как полночь UTC.
+// Так хранятся даты заезда и выезда: 2025-06-02T00:00:00+03:00 от клиента — это 2 июня.
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Nights возвращает ночи проживания: даты от заезда включительно до выезда исключительно.
+// Даты берутся в UTC, а не в поясе значения: бронирования из базы приходят в поясе сервера,
+// и та же полночь UTC в нём может оказаться предыдущим днём.
+func Nights(start, end time.Time) []time.Time {
+	first := Date(start.UTC())
+	last := Date(end.UTC())
+
+	var nights []time.Time
+	for night := first; night.Before(last); night = night.AddDate(0, 0, 1) {
+		nights = append(nights, night)
+	}
+	return nights
+}
+
+// Quoter возвращает QuoteRoom, привязанный к репозиториям цен и отелей
+func Quoter(prices PriceRepo, hotelRepo hotels.HotelRepo) func(room hotels.Room, start, end time.Time, guests int) (*Quote, error) {
+	return func(room hotels.Room, start, end time.Time, guests int) (*Quote, error) {
+		return QuoteRoom(prices, hotelRepo, room, start, end, guests)
+	}
+}
+
+// QuoteRoom рассчитывает стоимость проживания в номере по ночам.
+// Цена ночи берётся из календаря, иначе из подходящего правила с наибольшим приоритетом, иначе room.Price.
+// За каждого гостя сверх room.BaseOccupancy к ночи добавляется room.ExtraGuestPrice.
+// К сумме за ночи добавляются налог и сервисный сбор отеля.
+func QuoteRoom(prices PriceRepo, hotelRepo hotels.HotelRepo, room hotels.Room, start, end time.Time, guests int) (*Quote, error) {
+	nights := Nights(start, end)
+	if len(nights) == 0 {
+		return nil, fmt.Errorf("период должен содержать минимум одну ночь")
+	}
+
+	hotel, err := hotelRepo.ByID(room.HotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := prices.Rules(room.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := prices.Overrides(room.ID, nights[0], nights[len(nights)-1])
+	if err != nil {
+		return nil, err
+	}
+	overrideByDate := make(map[string]PriceOverride, len(overrides))
+	for _, override := range overrides {
+		overrideByDate[override.Date.Format("2006-01-02")] = override
+	}
+
+	extraGuestCharge := float64(ExtraGuests(room, guests)) * room.ExtraGuestPrice
+
+	quote := &Quote{RoomID: room.ID, Nights: len(nights), Guests: guests}
+	minStay := 0
+	for _, night := range nights {
+		nightPrice := NightPrice{Date: night, Price: room.Price, Source: SourceBase}
+
+		if rule := matchRule(rules, night); rule != nil {
+			ruleID := rule.ID
+			nightPrice.Price = rule.Price
+			nightPrice.Source = SourceRule
+			nightPrice.RuleID = &ruleID
+			if rule.MinStay > minStay {
+				minStay = rule.MinStay
+			}
+		}
+
+		if override, ok := overrideByDate[night.Format("2006-01-02")]; ok {
+			nightPrice.Price = override.Price
+			nightPrice.Source = SourceOverride
+			nightPrice.RuleID = nil
+		}
+
+		nightPrice.ExtraGuest = extraGuestCharge
+		nightPrice.Price += extraGuestCharge
+
+		quote.NightlyPrices = append(quote.NightlyPrices, nightPrice)
+		quote.Subtotal += nightPrice.Price
+	}
+
+	if len(nights) < minStay {
+		return nil, &MinStayError{MinStay: minStay}
+	}
+
+	quote.Subtotal = roundMoney(quote.Subtotal)
+	quote.Taxes = roundMoney(quote.Subtotal * hotel.TaxPercent / 100)
+	quote.Fees = roundMoney(hotel.ServiceFee)
+	quote.Total = roundMoney(quote.Subtotal + quote.Taxes + quote.Fees)
+	return quote, nil
+}
+
+// ExtraGuests возвращает число гостей сверх включённых в цену номера
+func ExtraGuests(room hotels.Room, guests int) int {
+	included := room.BaseOccupancy
+	if included <= 0 {
+		included = room.Capacity
+	}
+	if guests <= included {
+		return 0
+	}
+	return guests - included
+}
+
+// matchRule выбирает правило для ночи; rules отсортированы по убыванию приоритета
+func matchRule(rules []PriceRule, night time.Time) *PriceRule {
+	for i := range rules {
+		if rules[i].Applies(night) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// Applies проверяет, действует ли правило в указанную ночь
+func (r PriceRule) Applies(night time.Time) bool {
+	day := night.Format("2006-01-02")
+	if r.StartDate != nil && day < r.StartDate.Format("2006-01-02") {
+		return false
+	}
+	if r.EndDate != nil && day > r.EndDate.Format("2006-01-02") {
+		return false
+	}
+
+	if r.DaysOfWeek == "" {
+		return true
+	}
+	weekday := isoWeekday(night)
+	for _, d := range strings.Split(r.DaysOfWeek, ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(d)); err == nil && n == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// isoWeekday возвращает номер дня недели: 1 — понедельник, 7 — воскресенье
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,267 @@
+package pricing
+
+import (
+	"errors"
+	"hotel-booking/internal/hotels"
+	"testing"
+	"time"
+)
+
+// date разбирает дату в формате 2006-01-02
+func date(t *testing.T, value string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func datePtr(t *testing.T, value string) *time.Time {
+	t.Helper()
+	d := date(t, value)
+	return &d
+}
+
+func TestPriceRuleApplies(t *testing.T) {
+	// 2025-06-01 — воскресенье, 2025-06-02 — понедельник, 2025-06-07 — суббота
+	tests := []struct {
+		name  string
+		rule  PriceRule
+		night string
+		want  bool
+	}{
+		{"без ограничений", PriceRule{}, "2025-06-02", true},
+		{"до начала периода", PriceRule{StartDate: datePtr(t, "2025-06-03")}, "2025-06-02", false},
+		{"первая ночь периода", PriceRule{StartDate: datePtr(t, "2025-06-02")}, "2025-06-02", true},
+		{"последняя ночь периода включительно", PriceRule{EndDate: datePtr(t, "2025-06-02")}, "2025-06-02", true},
+		{"после конца периода", PriceRule{EndDate: datePtr(t, "2025-06-01")}, "2025-06-02", false},
+		{"понедельник — 1", PriceRule{DaysOfWeek: "1"}, "2025-06-02", true},
+		{"суббота в выходных", PriceRule{DaysOfWeek: "6,7"}, "2025-06-07", true},
+		{"воскресенье — 7", PriceRule{DaysOfWeek: "6,7"}, "2025-06-01", true},
+		{"воскресенье не 0", PriceRule{DaysOfWeek: "0"}, "2025-06-01", false},
+		{"будний день не в выходных", PriceRule{DaysOfWeek: "6,7"}, "2025-06-02", false},
+		{"пробелы в списке дней", PriceRule{DaysOfWeek: " 5, 1 "}, "2025-06-02", true},
+		{"мусор в списке дней", PriceRule{DaysOfWeek: "пн,x"}, "2025-06-02", false},
+		{"день недели вне периода", PriceRule{DaysOfWeek: "1", StartDate: datePtr(t, "2025-06-03")}, "2025-06-02", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Applies(date(t, tt.night)); got != tt.want {
+				t.Errorf("Applies(%s) = %v, ожидалось %v", tt.night, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtraGuests(t *testing.T) {
+	tests := []struct {
+		name   string
+		room   hotels.Room
+		guests int
+		want   int
+	}{
+		{"гостей меньше включённых", hotels.Room{Capacity: 4, BaseOccupancy: 2}, 1, 0},
+		{"ровно включённые", hotels.Room{Capacity: 4, BaseOccupancy: 2}, 2, 0},
+		{"сверх включённых", hotels.Room{Capacity: 4, BaseOccupancy: 2}, 4, 2},
+		{"без base_occupancy включена вся вместимость", hotels.Room{Capacity: 3}, 3, 0},
+		{"без base_occupancy сверх вместимости", hotels.Room{Capacity: 3}, 4, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtraGuests(tt.room, tt.guests); got != tt.want {
+				t.Errorf("ExtraGuests = %d, ожидалось %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteRoom(t *testing.T) {
+	type night struct {
+		price  float64
+		source string
+		rule   string // Название правила для SourceRule
+	}
+	tests := []struct {
+		name      string
+		hotel     hotels.Hotel
+		room      hotels.Room
+		rules     []PriceRule // Создаются по порядку: у следующего правила ID больше
+		overrides map[string]float64
+		start     string
+		end       string
+		guests    int
+		nights    []night
+		taxes     float64
+		fees      float64
+		total     float64
+	}{
+		{
+			name:  "базовая цена",
+			room:  hotels.Room{Price: 1000, Capacity: 2},
+			start: "2025-06-02", end: "2025-06-04", guests: 2,
+			nights: []night{{1000, SourceBase, ""}, {1000, SourceBase, ""}},
+			total:  2000,
+		},
+		{
+			name:      "календарь важнее правила",
+			room:      hotels.Room{Price: 1000, Capacity: 2},
+			rules:     []PriceRule{{Name: "сезон", Price: 1500, Priority: 100}},
+			overrides: map[string]float64{"2025-06-03": 700},
+			start:     "2025-06-02", end: "2025-06-04", guests: 2,
+			nights: []night{{1500, SourceRule, "сезон"}, {700, SourceOverride, ""}},
+			total:  2200,
+		},
+		{
+			name: "правило с большим приоритетом важнее",
+			room: hotels.Room{Price: 1000, Capacity: 2},
+			rules: []PriceRule{
+				{Name: "высокий", Price: 3000, Priority: 10},
+				{Name: "низкий", Price: 2000, Priority: 1},
+			},
+			start: "2025-06-02", end: "2025-06-03", guests: 2,
+			nights: []night{{3000, SourceRule, "высокий"}},
+			total:  3000,
+		},
+		{
+			name: "при равном приоритете действует более новое правило",
+			room: hotels.Room{Price: 1000, Capacity: 2},
+			rules: []PriceRule{
+				{Name: "старое", Price: 2000, Priority: 5},
+				{Name: "новое", Price: 2500, Priority: 5},
+			},
+			start: "2025-06-02", end: "2025-06-03", guests: 2,
+			nights: []night{{2500, SourceRule, "новое"}},
+			total:  2500,
+		},
+		{
+			name: "правило выходных, воскресенье — 7",
+			room: hotels.Room{Price: 1000, Capacity: 2},
+			rules: []PriceRule{
+				{Name: "выходные", DaysOfWeek: "6,7", Price: 1800, Priority: 1},
+				{Name: "июнь", StartDate: datePtr(t, "2025-06-01"), EndDate: datePtr(t, "2025-06-30"), Price: 1200},
+			},
+			// Пятница, суббота, воскресенье, понедельник
+			start: "2025-06-06", end: "2025-06-10", guests: 2,
+			nights: []night{{1200, SourceRule, "июнь"}, {1800, SourceRule, "выходные"}, {1800, SourceRule, "выходные"}, {1200, SourceRule, "июнь"}},
+			total:  6000,
+		},
+		{
+			name:      "доплата за гостей сверх base_occupancy в каждую ночь",
+			room:      hotels.Room{Price: 1000, Capacity: 4, BaseOccupancy: 2, ExtraGuestPrice: 300},
+			overrides: map[string]float64{"2025-06-03": 500},
+			start:     "2025-06-02", end: "2025-06-04", guests: 4,
+			nights: []night{{1600, SourceBase, ""}, {1100, SourceOverride, ""}},
+			total:  2700,
+		},
+		{
+			name:  "налог и сбор округляются до копеек",
+			hotel: hotels.Hotel{TaxPercent: 12.5, ServiceFee: 99.999},
+			room:  hotels.Room{Price: 1111.11, Capacity: 2},
+			start: "2025-06-02", end: "2025-06-05", guests: 1,
+			nights: []night{{1111.11, SourceBase, ""}, {1111.11, SourceBase, ""}, {1111.11, SourceBase, ""}},
+			// 3333.33 * 12.5% = 416.66625
+			taxes: 416.67, fees: 100, total: 3850,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := hotels.NewMemory()
+			hotel := tt.hotel
+			if err := store.Hotels().Create(&hotel); err != nil {
+				t.Fatal(err)
+			}
+			room := tt.room
+			room.HotelID = hotel.ID
+			if err := store.Rooms().Create(&room); err != nil {
+				t.Fatal(err)
+			}
+
+			prices := NewMemoryPriceRepo()
+			ruleNames := map[uint]string{}
+			for _, rule := range tt.rules {
+				rule.RoomID = room.ID
+				if err := prices.CreateRule(&rule); err != nil {
+					t.Fatal(err)
+				}
+				ruleNames[rule.ID] = rule.Name
+			}
+			var overrides []PriceOverride
+			for day, price := range tt.overrides {
+				overrides = append(overrides, PriceOverride{RoomID: room.ID, Date: date(t, day), Price: price})
+			}
+			if err := prices.SetOverrides(overrides); err != nil {
+				t.Fatal(err)
+			}
+
+			quote, err := QuoteRoom(prices, store.Hotels(), room, date(t, tt.start), date(t, tt.end), tt.guests)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if quote.Nights != len(tt.nights) || len(quote.NightlyPrices) != len(tt.nights) {
+				t.Fatalf("ночей %d, ожидалось %d: %+v", quote.Nights, len(tt.nights), quote.NightlyPrices)
+			}
+			for i, want := range tt.nights {
+				got := quote.NightlyPrices[i]
+				name := ""
+				if got.RuleID != nil {
+					name = ruleNames[*got.RuleID]
+				}
+				if got.Price != want.price || got.Source != want.source || name != want.rule {
+					t.Errorf("ночь %s: %.2f (%s %q), ожидалось %.2f (%s %q)",
+						got.Date.Format("2006-01-02"), got.Price, got.Source, name, want.price, want.source, want.rule)
+				}
+			}
+			if quote.Taxes != tt.taxes || quote.Fees != tt.fees || quote.Total != tt.total {
+				t.Errorf("налог %.2f, сбор %.2f, итого %.2f; ожидалось %.2f, %.2f, %.2f",
+					quote.Taxes, quote.Fees, quote.Total, tt.taxes, tt.fees, tt.total)
+			}
+		})
+	}
+}
+
+func TestQuoteRoomMinStay(t *testing.T) {
+	store := hotels.NewMemory()
+	hotel := hotels.Hotel{}
+	if err := store.Hotels().Create(&hotel); err != nil {
+		t.Fatal(err)
+	}
+	room := hotels.Room{HotelID: hotel.ID, Price: 1000, Capacity: 2}
+	if err := store.Rooms().Create(&room); err != nil {
+		t.Fatal(err)
+	}
+	prices := NewMemoryPriceRepo()
+	// Минимум три ночи, если проживание захватывает выходные
+	if err := prices.CreateRule(&PriceRule{RoomID: room.ID, DaysOfWeek: "6,7", Price: 1500, MinStay: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		start, end string
+		minStay    int // 0 — расчёт проходит
+	}{
+		{"короче минимума на выходных", "2025-06-06", "2025-06-08", 3},
+		{"ровно минимум", "2025-06-06", "2025-06-09", 0},
+		{"будни без правила", "2025-06-02", "2025-06-03", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := QuoteRoom(prices, store.Hotels(), room, date(t, tt.start), date(t, tt.end), 1)
+			if tt.minStay == 0 {
+				if err != nil {
+					t.Fatalf("ошибка: %v", err)
+				}
+				return
+			}
+			var minStayErr *MinStayError
+			if !errors.As(err, &minStayErr) || minStayErr.MinStay != tt.minStay {
+				t.Fatalf("результат %+v, ошибка %v; ожидалась MinStayError{%d}", quote, err, tt.minStay)
+			}
+		})
+	}
+
+	if _, err := QuoteRoom(prices, store.Hotels(), room, date(t, "2025-06-02"), date(t, "2025-06-02"), 1); err == nil {
+		t.Error("период без ночей: ожидалась ошибка")
+	}
+}
@@ -0,0 +1,76 @@
+package pricing
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PriceRepo — правила цены и календарь цен номеров
+type PriceRepo interface {
+	// Rules возвращает правила номера по убыванию приоритета, среди равных — сначала новые
+	Rules(roomID uint) ([]PriceRule, error)
+	CreateRule(rule *PriceRule) error
+	// DeleteRule удаляет правило номера; false, если его не было
+	DeleteRule(roomID, ruleID uint) (bool, error)
+	// Overrides возвращает цены календаря номера с from по to включительно в порядке дат;
+	// нулевые from и to не ограничивают период
+	Overrides(roomID uint, from, to time.Time) ([]PriceOverride, error)
+	// SetOverrides создаёт цены на даты или заменяет уже заданные
+	SetOverrides(overrides []PriceOverride) error
+	// DeleteOverride удаляет цену на дату; false, если она не была задана
+	DeleteOverride(roomID uint, date time.Time) (bool, error)
+}
+
+// GormPriceRepo хранит правила и календарь цен в базе данных
+type GormPriceRepo struct {
+	db *gorm.DB
+}
+
+func NewGormPriceRepo(db *gorm.DB) *GormPriceRepo {
+	return &GormPriceRepo{db: db}
+}
+
+func (r *GormPriceRepo) Rules(roomID uint) ([]PriceRule, error) {
+	var rules []PriceRule
+	err := r.db.Where("room_id = ?", roomID).Order("priority DESC, id DESC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *GormPriceRepo) CreateRule(rule *PriceRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *GormPriceRepo) DeleteRule(roomID, ruleID uint) (bool, error) {
+	result := r.db.Where("id = ? AND room_id = ?", ruleID, roomID).Delete(&PriceRule{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *GormPriceRepo) Overrides(roomID uint, from, to time.Time) ([]PriceOverride, error) {
+	query := r.db.Where("room_id = ?", roomID)
+	if !from.IsZero() {
+		query = query.Where("date >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("date <= ?", to)
+	}
+	var overrides []PriceOverride
+	err := query.Order("date").Find(&overrides).Error
+	return overrides, err
+}
+
+func (r *GormPriceRepo) SetOverrides(overrides []PriceOverride) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "room_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price", "updated_at"}),
+	}).Create(&overrides).Error
+}
+
+func (r *GormPriceRepo) DeleteOverride(roomID uint, date time.Time) (bool, error) {
+	result := r.db.Where("room_id = ? AND date = ?", roomID, date).Delete(&PriceOverride{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -17,7 +17,9 @@ type SuccessResponse struct {
 }
 
 type TokenResponse struct {
-	Token string `json:"token" example:"Ваш токен"`
+	Token        string `json:"token" example:"Ваш токен"`                 // Access-токен, действует 15 минут
+	RefreshToken string `json:"refresh_token" example:"Ваш refresh-токен"` // Обменивается на новую пару через /auth/refresh
+	Role         string `json:"role" example:"client"`
 }
 
 type MessageResponse struct {
@@ -37,25 +39,71 @@ type HotelResponse struct {
 	Address     string         `json:"address"`
 	Description string         `json:"description"`
 	OwnerID     uint           `json:"owner_id"`
+	TaxPercent  float64        `json:"tax_percent"` // Налог, % от стоимости проживания
+	ServiceFee  float64        `json:"service_fee"` // Сервисный сбор за бронирование
 	Rooms       []RoomResponse `json:"rooms"`
 }
 
 type RoomResponse struct {
-	HotelID   uint    `json:"hotel_id"`  // ID отеля
-	RoomType  string  `json:"room_type"` // Тип номера (стандартный, люкс и т.д.)
-	Price     float64 `json:"price"`     // Цена за ночь
-	Amenities string  `json:"amenities"` // Удобства
-	Capacity  int     `json:"capacity"`  // Количество гостей
-	Available bool    `json:"available"` // Наличие
+	HotelID         uint    `json:"hotel_id"`          // ID отеля
+	RoomType        string  `json:"room_type"`         // Тип номера (стандартный, люкс и т.д.)
+	Price           float64 `json:"price"`             // Цена за ночь
+	Amenities       string  `json:"amenities"`         // Удобства
+	Capacity        int     `json:"capacity"`          // Количество гостей
+	Units           int     `json:"units"`             // Количество номеров этого типа
+	BaseOccupancy   int     `json:"base_occupancy"`    // Гостей, включённых в цену; 0 — все
+	ExtraGuestPrice float64 `json:"extra_guest_price"` // Доплата за гостя сверх base_occupancy за ночь
+	Available       bool    `json:"available"`         // Наличие
+}
+
+type RoomAvailabilityResponse struct {
+	RoomID uint                     `json:"room_id"`
+	Units  int                      `json:"units"` // Количество номеров этого типа
+	Nights []NightAvailabilityEntry `json:"nights"`
+}
+
+type NightAvailabilityEntry struct {
+	Date      string `json:"date" example:"2025-01-31"`
+	Available int    `json:"available"` // Свободных номеров на эту ночь
 }
 
 type BookingResponse struct {
-	RoomID        uint      `json:"room_id"`
-	UserID        uint      `json:"user_id"`
-	StartDate     time.Time `json:"start_date"`
-	EndDate       time.Time `json:"end_date"`
-	TotalCost     float64   `json:"total_cost"`     //Итоговая стоимость
-	PaymentStatus string    `json:"payment_status"` //Статус оплаты
+	RoomID         uint       `json:"room_id"`
+	UserID         uint       `json:"user_id"`
+	StartDate      time.Time  `json:"start_date"`
+	EndDate        time.Time  `json:"end_date"`
+	Adults         int        `json:"adults"`          //Количество взрослых
+	Children       int        `json:"children"`        //Количество детей
+	TotalCost      float64    `json:"total_cost"`      //Итоговая стоимость
+	RefundedAmount float64    `json:"refunded_amount"` //Сумма возврата при отмене
+	RefundAmount   *float64   `json:"refund_amount"`   //Сумма к возврату, определённая при отмене
+	Status         string     `json:"status"`          //Статус бронирования
+	PaymentStatus  string     `json:"payment_status"`  //Статус оплаты
+	ExpiresAt      *time.Time `json:"expires_at"`      //Срок оплаты
+}
+
+type BookingEventResponse struct {
+	BookingID  uint      `json:"booking_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ActorType  string    `json:"actor_type"` // user, system или payment
+	ActorID    *uint     `json:"actor_id"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type CancellationResponse struct {
+	Message      string  `json:"message"`
+	RefundAmount float64 `json:"refund_amount"` // Сумма возврата по политике отмены
+}
+
+type CancellationPolicyResponse struct {
+	ID             uint    `json:"ID"`
+	HotelID        uint    `json:"HotelID"`
+	RoomID         uint    `json:"RoomID"`         // 0 — политика всего отеля
+	FreeUntilDays  int     `json:"FreeUntilDays"`  // Бесплатная отмена не позднее чем за N дней до заезда
+	PenaltyPercent float64 `json:"PenaltyPercent"` // Штраф в % от стоимости при более поздней отмене
+	NonRefundable  bool    `json:"NonRefundable"`  // Невозвратный тариф
 }
 
 type CreatePaymentResponse struct {
@@ -75,3 +123,101 @@ type RoomRatingResponse struct {
 	Rating  float64 `json:"rating"`
 	Comment string  `json:"comment"`
 }
+
+type PriceRuleResponse struct {
+	ID         uint    `json:"ID"`
+	RoomID     uint    `json:"RoomID"`
+	Name       string  `json:"Name"`
+	StartDate  string  `json:"StartDate" example:"2025-06-01T00:00:00Z"`
+	EndDate    string  `json:"EndDate" example:"2025-08-31T00:00:00Z"`
+	DaysOfWeek string  `json:"DaysOfWeek" example:"5,6"` // Дни недели 1-7 (пн-вс)
+	Price      float64 `json:"Price"`
+	MinStay    int     `json:"MinStay"`
+	Priority   int     `json:"Priority"`
+}
+
+type PriceOverrideResponse struct {
+	ID     uint    `json:"ID"`
+	RoomID uint    `json:"RoomID"`
+	Date   string  `json:"Date" example:"2025-12-31T00:00:00Z"`
+	Price  float64 `json:"Price"`
+}
+
+type NightPriceResponse struct {
+	Date       string  `json:"date" example:"2025-06-01T00:00:00Z"`
+	Price      float64 `json:"price"`                 // Цена ночи, включая доплату за гостей
+	Source     string  `json:"source" example:"rule"` // base, rule или override
+	RuleID     uint    `json:"rule_id,omitempty"`     // ID правила, если цена взята из правила
+	ExtraGuest float64 `json:"extra_guest"`           // Доплата за гостей сверх base_occupancy
+}
+
+type QuoteResponse struct {
+	RoomID        uint                 `json:"room_id"`
+	Nights        int                  `json:"nights"`
+	Guests        int                  `json:"guests"`
+	NightlyPrices []NightPriceResponse `json:"nightly_prices"`
+	Subtotal      float64              `json:"subtotal"` // Сумма цен за ночи
+	Taxes         float64              `json:"taxes"`    // Налог отеля
+	Fees          float64              `json:"fees"`     // Сервисный сбор отеля
+	Total         float64              `json:"total"`    // Итого к оплате
+}
+
+type HotelStaffResponse struct {
+	ID        uint      `json:"ID"`
+	HotelID   uint      `json:"HotelID"`
+	UserID    uint      `json:"UserID"`
+	Role      string    `json:"Role" example:"manager"`
+	CreatedAt time.Time `json:"CreatedAt"`
+}
+
+type CreatedBookingResponse struct {
+	BookingResponse
+	PaymentURL string `json:"payment_url" example:"ссылка на оплату"` // Ссылка для оплаты
+}
+
+type OutboxMessageResponse struct {
+	ID            uint       `json:"id"`
+	Kind          string     `json:"kind" example:"email"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status" example:"dead"` // pending, sent или dead
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error"` // Ошибка последней неудачной попытки
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type MailDiagnosticStepResponse struct {
+	Name       string `json:"name" example:"starttls"` // connect, greeting, ehlo, starttls, auth, send или quit
+	OK         bool   `json:"ok"`
+	Detail     string `json:"detail,omitempty" example:"TLS 1.3, TLS_AES_128_GCM_SHA256"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type MailDiagnosticsResponse struct {
+	Transport string                       `json:"transport" example:"smtp"` // smtp, file или memory
+	Server    string                       `json:"server,omitempty" example:"smtp.example.com:587"`
+	TLSMode   string                       `json:"tls_mode,omitempty" example:"starttls"`
+	From      string                       `json:"from,omitempty"`
+	Steps     []MailDiagnosticStepResponse `json:"steps"`
+	OK        bool                         `json:"ok"`
+	Error     string                       `json:"error,omitempty"` // Ошибка первого неудачного шага
+}
+
+type MailStatusResponse struct {
+	Counts        map[string]int64        `json:"counts"`                   // Число писем в статусах pending, sent и dead
+	OldestPending *time.Time              `json:"oldest_pending,omitempty"` // Самое старое неотправленное письмо
+	LastSentAt    *time.Time              `json:"last_sent_at,omitempty"`   // Последняя успешная отправка
+	Recent        []OutboxMessageResponse `json:"recent"`
+}
+
+type HealthResponse struct {
+	Status string `json:"status" example:"ok"`
+}
+
+type ReadinessResponse struct {
+	Status string            `json:"status" example:"ok"` // ok, unavailable или draining
+	Checks map[string]string `json:"checks"`              // ok или unavailable для database, smtp и storage
+}
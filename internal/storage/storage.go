@@ -1,31 +1,63 @@
 package storage
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
 
-func ConnectDatabase() {
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	dbname := os.Getenv("DB_NAME")
+// Config — параметры подключения к PostgreSQL
+type Config struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+}
 
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname)
+func (c Config) DSN() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode)
+}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+func ConnectDatabase(cfg Config) error {
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{Logger: newLogger()})
 	if err != nil {
-		log.Fatal("Ошибка подключения к базе данных:", err)
+		return err
 	}
 
 	DB = db
-	fmt.Println("Подключение к базе данных успешно!")
+	slog.Info("Подключение к базе данных успешно", "host", cfg.Host, "database", cfg.Name)
+	return nil
+}
+
+// Ping проверяет, что база данных отвечает
+func Ping(ctx context.Context) error {
+	if DB == nil {
+		return fmt.Errorf("база данных не подключена")
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// newLogger пишет в журнал slog только медленные запросы и ошибки.
+// Значения параметров запросов не журналируются: в них бывают пароли и персональные данные.
+func newLogger() logger.Interface {
+	return logger.New(slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn), logger.Config{
+		SlowThreshold:             500 * time.Millisecond,
+		LogLevel:                  logger.Warn,
+		IgnoreRecordNotFoundError: true,
+		ParameterizedQueries:      true,
+	})
 }
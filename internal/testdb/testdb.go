@@ -0,0 +1,116 @@
+// Package testdb даёт интеграционным тестам базу PostgreSQL.
+//
+// Если задан TEST_DATABASE_DSN, тесты работают с этой базой. Иначе пакет сам запускает
+// временный PostgreSQL 16 (embedded-postgres) на свободном порту — один на процесс тестов;
+// бинарные файлы скачиваются при первом запуске и кешируются в ~/.embedded-postgres-go.
+// Пакет тестов, использующий базу, останавливает сервер в TestMain через Run.
+//
+// Если базу получить не удалось, тест проваливается при заданной переменной CI
+// и пропускается с причиной при локальном запуске.
+package testdb
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	once     sync.Once
+	dsn      string
+	startErr error
+	server   *embeddedpostgres.EmbeddedPostgres
+	dir      string
+)
+
+// Run выполняет тесты пакета и останавливает PostgreSQL, если он был запущен для них
+func Run(m *testing.M) int {
+	code := m.Run()
+	if server != nil {
+		if err := server.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "testdb: остановка PostgreSQL: %v\n", err)
+		}
+		os.RemoveAll(dir)
+	}
+	return code
+}
+
+// Open подключается к тестовой базе. Миграции вызывающий применяет сам.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	once.Do(start)
+	if startErr != nil {
+		if os.Getenv("CI") != "" {
+			t.Fatalf("тестовая база PostgreSQL недоступна: %v", startErr)
+		}
+		t.Skipf("тестовая база PostgreSQL недоступна: %v; задайте TEST_DATABASE_DSN", startErr)
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("подключение к базе: %v", err)
+	}
+	return db
+}
+
+// start выбирает TEST_DATABASE_DSN или запускает временный PostgreSQL
+func start() {
+	if dsn = os.Getenv("TEST_DATABASE_DSN"); dsn != "" {
+		return
+	}
+
+	port, err := freePort()
+	if err != nil {
+		startErr = err
+		return
+	}
+	dir, err = os.MkdirTemp("", "hotel-booking-pg-")
+	if err != nil {
+		startErr = err
+		return
+	}
+
+	// Журнал PostgreSQL нужен только при ошибке запуска
+	var log bytes.Buffer
+	pg := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+		Version(embeddedpostgres.V16).
+		Port(port).
+		Database("hotel_test").
+		Username("test").
+		Password("test").
+		RuntimePath(dir).
+		StartTimeout(time.Minute).
+		Logger(&log))
+	if err := pg.Start(); err != nil {
+		startErr = fmt.Errorf("запуск embedded-postgres: %w", err)
+		if output := strings.TrimSpace(log.String()); output != "" {
+			startErr = fmt.Errorf("%w\n%s", startErr, output)
+		}
+		os.RemoveAll(dir)
+		return
+	}
+
+	server = pg
+	dsn = fmt.Sprintf("host=localhost port=%d user=test password=test dbname=hotel_test sslmode=disable", port)
+}
+
+// freePort возвращает свободный TCP-порт на localhost
+func freePort() (uint32, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return uint32(l.Addr().(*net.TCPAddr).Port), nil
+}
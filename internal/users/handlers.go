@@ -1,12 +1,21 @@
 package users
 
 import (
-	"hotel-booking/internal/storage"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Handler — обработчики администрирования пользователей
+type Handler struct {
+	Users UserRepo
+}
+
+func NewHandler(users UserRepo) *Handler {
+	return &Handler{Users: users}
+}
+
 type UpdateRoleInput struct {
 	Role string `json:"role" binding:"required" enums:"owner,admin,client,manager"`
 }
@@ -26,16 +35,7 @@ type UpdateRoleInput struct {
 // @Failure 404 {object} response.ErrorResponse "Пользователь не найден"
 // @Failure 500 {object} response.ErrorResponse "Не удалось обновить роль"
 // @Router /admin/users/{id}/role [put]
-func UpdateRoleHandler(c *gin.Context) {
-	// Проверка роли администратора
-	role := c.GetString("role")
-	if role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Только администратор может изменять роли"})
-		return
-	}
-
-	// Получаем ID пользователя
-	userID := c.Param("id")
+func (h *Handler) UpdateRoleHandler(c *gin.Context) {
 
 	var input UpdateRoleInput
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -49,15 +49,19 @@ func UpdateRoleHandler(c *gin.Context) {
 	}
 
 	// Проверяем наличие пользователя
-	var user User
-	if err := storage.DB.First(&user, userID).Error; err != nil {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+		return
+	}
+	user, err := h.Users.ByID(uint(userID))
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
 		return
 	}
 
-	// Обновляем роль
-	user.Role = input.Role
-	if err := storage.DB.Save(&user).Error; err != nil {
+	// Обновляем роль; новая версия токенов отзывает токены со старой ролью
+	if err := h.Users.UpdateRole(&user, input.Role); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось обновить роль"})
 		return
 	}
@@ -76,15 +80,9 @@ func UpdateRoleHandler(c *gin.Context) {
 // @Failure 403 {object} response.ErrorResponse "Только администратор может просматривать пользователей"
 // @Failure 500 {object} response.ErrorResponse "Ошибка при получении пользователей"
 // @Router /admin/users [get]
-func GetUsersHandler(c *gin.Context) {
-	role := c.GetString("role")
-	if role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Только администратор может просматривать пользователей"})
-		return
-	}
-
-	var users []User
-	if err := storage.DB.Find(&users).Error; err != nil {
+func (h *Handler) GetUsersHandler(c *gin.Context) {
+	users, err := h.Users.List()
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении пользователей"})
 		return
 	}
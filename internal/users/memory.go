@@ -0,0 +1,173 @@
+package users
+
+import (
+	"context"
+	"hotel-booking/internal/email"
+	"hotel-booking/internal/outbox"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryUserRepo хранит пользователей в памяти; используется в тестах обработчиков
+type MemoryUserRepo struct {
+	mu     sync.Mutex
+	users  map[uint]User
+	emails []outbox.Email
+	nextID uint
+}
+
+func NewMemoryUserRepo() *MemoryUserRepo {
+	return &MemoryUserRepo{users: map[uint]User{}}
+}
+
+func (r *MemoryUserRepo) ByID(id uint) (User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return User{}, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (r *MemoryUserRepo) ByPhone(phone string) (User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Phone == phone {
+			return user, nil
+		}
+	}
+	return User{}, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryUserRepo) ByEmail(email string) (User, error) {
+	return r.find(func(user User) bool { return user.Email == email })
+}
+
+func (r *MemoryUserRepo) ByVerificationToken(token string) (User, error) {
+	return r.find(func(user User) bool { return token != "" && user.VerificationToken == token })
+}
+
+func (r *MemoryUserRepo) ByResetToken(token string) (User, error) {
+	return r.find(func(user User) bool { return token != "" && user.ResetPasswordToken == token })
+}
+
+func (r *MemoryUserRepo) find(match func(User) bool) (User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if match(user) {
+			return user, nil
+		}
+	}
+	return User{}, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryUserRepo) List() ([]User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	users := make([]User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *MemoryUserRepo) Create(user *User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	user.ID = r.nextID
+	// Значения по умолчанию из схемы
+	if user.Role == "" {
+		user.Role = "client"
+	}
+	if user.Language == "" {
+		user.Language = "ru"
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *MemoryUserRepo) UpdateRole(user *User, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.users[user.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	stored.Role = role
+	stored.TokenVersion++
+	r.users[user.ID] = stored
+	*user = stored
+	return nil
+}
+
+func (r *MemoryUserRepo) Verify(user *User) error {
+	return r.update(user, func(stored *User) {
+		stored.IsVerified = true
+		stored.VerificationToken = ""
+	})
+}
+
+func (r *MemoryUserRepo) SetResetToken(ctx context.Context, user *User, token string, expiry time.Time, msg email.Message) error {
+	if err := r.update(user, func(stored *User) {
+		stored.ResetPasswordToken = token
+		stored.ResetTokenExpiry = &expiry
+	}); err != nil {
+		return err
+	}
+	r.queue(*user, msg)
+	return nil
+}
+
+func (r *MemoryUserRepo) SetPassword(user *User, passwordHash string) error {
+	return r.update(user, func(stored *User) {
+		stored.Password = passwordHash
+		stored.ResetPasswordToken = ""
+		stored.ResetTokenExpiry = nil
+		stored.TokenVersion++
+	})
+}
+
+func (r *MemoryUserRepo) SetVerificationToken(ctx context.Context, user *User, token string, msg email.Message) error {
+	if err := r.update(user, func(stored *User) {
+		stored.VerificationToken = token
+	}); err != nil {
+		return err
+	}
+	r.queue(*user, msg)
+	return nil
+}
+
+func (r *MemoryUserRepo) queue(user User, msg email.Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emails = append(r.emails, outbox.Email{To: user.Email, Message: msg})
+}
+
+// Emails возвращает письма, поставленные в очередь
+func (r *MemoryUserRepo) Emails() []outbox.Email {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]outbox.Email(nil), r.emails...)
+}
+
+// update применяет change к сохранённому пользователю и копирует результат в user
+func (r *MemoryUserRepo) update(user *User, change func(stored *User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.users[user.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	change(&stored)
+	r.users[user.ID] = stored
+	*user = stored
+	return nil
+}
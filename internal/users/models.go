@@ -16,5 +16,7 @@ type User struct {
 	ResetPasswordToken string     `gorm:"type:varchar(255)"`                 // Токен для восстановления пароля
 	ResetTokenExpiry   *time.Time // Время токена
 	IsVerified         bool       `gorm:"default:false"`
-	VerificationToken  string     `gorm:"type:varchar(255)"` // Токен для подтверждения почты
+	VerificationToken  string     `gorm:"type:varchar(255)"`                     // Токен для подтверждения почты
+	TokenVersion       int        `gorm:"not null;default:0"`                    // Меняется при смене роли или пароля, выданные токены перестают действовать
+	Language           string     `gorm:"type:varchar(5);not null;default:'ru'"` // Язык писем: ru или en
 }
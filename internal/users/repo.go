@@ -0,0 +1,155 @@
+package users
+
+import (
+	"context"
+	"hotel-booking/internal/email"
+	"hotel-booking/internal/outbox"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserRepo — доступ к пользователям. Не найденный пользователь возвращается
+// как gorm.ErrRecordNotFound в обеих реализациях.
+type UserRepo interface {
+	ByID(id uint) (User, error)
+	ByPhone(phone string) (User, error)
+	ByEmail(email string) (User, error)
+	ByVerificationToken(token string) (User, error)
+	ByResetToken(token string) (User, error)
+	List() ([]User, error)
+	Create(user *User) error
+	// UpdateRole меняет роль и увеличивает TokenVersion, отзывая выданные токены
+	UpdateRole(user *User, role string) error
+	// Verify отмечает почту подтверждённой и сбрасывает токен подтверждения
+	Verify(user *User) error
+	// SetResetToken сохраняет токен сброса пароля и ставит письмо msg в очередь в одной транзакции:
+	// письмо уйдёт, только если токен записан
+	SetResetToken(ctx context.Context, user *User, token string, expiry time.Time, msg email.Message) error
+	// SetPassword меняет хеш пароля, сбрасывает токен сброса и увеличивает TokenVersion
+	SetPassword(user *User, passwordHash string) error
+	// SetVerificationToken сохраняет новый токен подтверждения почты и ставит письмо msg
+	// в очередь в одной транзакции: письмо уйдёт, только если токен записан
+	SetVerificationToken(ctx context.Context, user *User, token string, msg email.Message) error
+}
+
+// GormUserRepo хранит пользователей в базе данных
+type GormUserRepo struct {
+	db *gorm.DB
+}
+
+func NewGormUserRepo(db *gorm.DB) *GormUserRepo {
+	return &GormUserRepo{db: db}
+}
+
+func (r *GormUserRepo) ByID(id uint) (User, error) {
+	var user User
+	err := r.db.First(&user, id).Error
+	return user, err
+}
+
+func (r *GormUserRepo) ByPhone(phone string) (User, error) {
+	var user User
+	err := r.db.Where("phone = ?", phone).First(&user).Error
+	return user, err
+}
+
+func (r *GormUserRepo) ByEmail(email string) (User, error) {
+	var user User
+	err := r.db.Where("email = ?", email).First(&user).Error
+	return user, err
+}
+
+func (r *GormUserRepo) ByVerificationToken(token string) (User, error) {
+	var user User
+	err := r.db.Where("verification_token = ? AND verification_token <> ''", token).First(&user).Error
+	return user, err
+}
+
+func (r *GormUserRepo) ByResetToken(token string) (User, error) {
+	var user User
+	err := r.db.Where("reset_password_token = ? AND reset_password_token <> ''", token).First(&user).Error
+	return user, err
+}
+
+func (r *GormUserRepo) List() ([]User, error) {
+	var users []User
+	err := r.db.Find(&users).Error
+	return users, err
+}
+
+func (r *GormUserRepo) Create(user *User) error {
+	return r.db.Create(user).Error
+}
+
+func (r *GormUserRepo) UpdateRole(user *User, role string) error {
+	if err := r.db.Model(user).Updates(map[string]interface{}{
+		"role":          role,
+		"token_version": gorm.Expr("token_version + 1"),
+	}).Error; err != nil {
+		return err
+	}
+	user.Role = role
+	user.TokenVersion++
+	return nil
+}
+
+func (r *GormUserRepo) Verify(user *User) error {
+	if err := r.db.Model(user).Updates(map[string]interface{}{
+		"is_verified":        true,
+		"verification_token": "",
+	}).Error; err != nil {
+		return err
+	}
+	user.IsVerified = true
+	user.VerificationToken = ""
+	return nil
+}
+
+func (r *GormUserRepo) SetResetToken(ctx context.Context, user *User, token string, expiry time.Time, msg email.Message) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"reset_password_token": token,
+			"reset_token_expiry":   expiry,
+		}).Error; err != nil {
+			return err
+		}
+		return outbox.EnqueueEmail(tx, user.Email, msg)
+	})
+	if err != nil {
+		return err
+	}
+	user.ResetPasswordToken = token
+	user.ResetTokenExpiry = &expiry
+	return nil
+}
+
+func (r *GormUserRepo) SetPassword(user *User, passwordHash string) error {
+	if err := r.db.Model(user).Updates(map[string]interface{}{
+		"password":             passwordHash,
+		"reset_password_token": "",
+		"reset_token_expiry":   nil,
+		"token_version":        gorm.Expr("token_version + 1"),
+	}).Error; err != nil {
+		return err
+	}
+	user.Password = passwordHash
+	user.ResetPasswordToken = ""
+	user.ResetTokenExpiry = nil
+	user.TokenVersion++
+	return nil
+}
+
+func (r *GormUserRepo) SetVerificationToken(ctx context.Context, user *User, token string, msg email.Message) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("verification_token", token).Error; err != nil {
+			return err
+		}
+		return outbox.EnqueueEmail(tx, user.Email, msg)
+	})
+	if err != nil {
+		return err
+	}
+	user.VerificationToken = token
+	return nil
+}
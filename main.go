@@ -1,23 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
 	_ "hotel-booking/docs"
 	"hotel-booking/internal/auth"
 	"hotel-booking/internal/bookings"
+	"hotel-booking/internal/config"
 	"hotel-booking/internal/email"
+	"hotel-booking/internal/health"
 	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/logging"
+	"hotel-booking/internal/migrations"
+	"hotel-booking/internal/outbox"
 	"hotel-booking/internal/payments"
 	"hotel-booking/internal/storage"
-	"hotel-booking/internal/users"
-	"log"
+	"log/slog"
+	"net/http"
+	"net/mail"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/gin-contrib/cors"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 )
 
 // @Title Система бронирования номеров
@@ -25,92 +33,187 @@ import (
 // @in header
 // @name Authorization
 func main() {
-	key := os.Getenv("TEST_ENV")
-	if key == "" {
-		log.Println("\nПеременной среды нет, используется .env")
-		// Загружаем .env
-		err := godotenv.Load()
-		if err != nil {
-			log.Fatal("Ошибка загрузки .env файла")
-		}
+	// Настройки из config.yaml, .env и переменных окружения
+	// До загрузки настроек журнал пишется с уровнем и форматом по умолчанию
+	if err := logging.Setup(os.Stdout, logging.Config{}); err != nil {
+		fatal("Ошибка настройки журнала", err)
+	}
+	// Подкоманде migrate нужны только база и журнал
+	migrate := len(os.Args) > 1 && os.Args[1] == "migrate"
+	load := config.Load
+	if migrate {
+		load = config.LoadMigrate
+	}
+	cfg, err := load("")
+	if err != nil {
+		fatal("Ошибка загрузки настроек", err)
+	}
+	if err := logging.Setup(os.Stdout, logging.Config{Level: cfg.Log.Level, Format: cfg.Log.Format}); err != nil {
+		fatal("Ошибка настройки журнала", err)
 	}
 
 	// Подключение базы данных
-	storage.ConnectDatabase()
+	if err := storage.ConnectDatabase(storage.Config{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		Name:     cfg.Database.Name,
+		SSLMode:  cfg.Database.SSLMode,
+	}); err != nil {
+		fatal("Ошибка подключения к базе данных", err)
+	}
+
+	// hotel-booking migrate up|down|status управляет схемой и завершается
+	if migrate {
+		runMigrate(os.Args[2:])
+		return
+	}
+
+	// Сервер не запускается на схеме, к которой применены не все миграции
+	migrator, err := migrations.New(storage.DB)
+	if err != nil {
+		fatal("Ошибка загрузки миграций", err)
+	}
+	if err := migrator.Check(); err != nil {
+		fatal("Схема базы данных не актуальна, выполните migrate up", err)
+	}
+
+	// Ключи подписи токенов
+	tokenService, err := auth.TokenServiceFromSpec(cfg.JWT.Keys, cfg.JWT.Key, cfg.JWT.ActiveKID)
+	if err != nil {
+		fatal("Ошибка настройки ключей JWT", err)
+	}
+	auth.SetTokenService(tokenService)
+	auth.SetLinkURLs(cfg.URLs.Backend, cfg.URLs.Frontend)
+
+	// Выбор платёжного провайдера
+	paymentsCfg := payments.Config{
+		Provider:          cfg.Payments.Provider,
+		ShopID:            cfg.Payments.ShopID,
+		SecretKey:         cfg.Payments.SecretKey,
+		BackendURL:        cfg.URLs.Backend,
+		ReturnURL:         cfg.Payments.ReturnURL,
+		WebhookAllowedIPs: cfg.Payments.WebhookAllowedIPs,
+	}
+	provider, err := payments.NewProvider(paymentsCfg)
+	if err != nil {
+		fatal("Ошибка настройки платёжного провайдера", err)
+	}
+	checker := health.NewChecker(cfg.Server.ReadinessTimeout())
+	checker.Add("database", storage.Ping)
+	handlers := newHandlers(storage.DB, checker, provider, paymentsCfg)
+
+	// Время на оплату и ссылка на список бронирований в письмах
+	bookings.SetHoldDuration(cfg.Booking.Hold())
+	bookings.SetBookingsURL(strings.TrimRight(cfg.URLs.Frontend, "/") + "/my-bookings")
+
+	mailer, err := email.NewMailer(email.MailerConfig{
+		Transport: cfg.Mail.Transport,
+		Dir:       cfg.Mail.Dir,
+		SMTP: email.SMTPConfig{
+			Host:     cfg.Mail.Host,
+			Port:     cfg.Mail.Port,
+			Username: cfg.Mail.Username,
+			Password: cfg.Mail.Password,
+			From:     mail.Address{Name: cfg.Mail.FromName, Address: cfg.Mail.From},
+			TLSMode:  cfg.Mail.TLS,
+		},
+	})
+	if err != nil {
+		fatal("Ошибка настройки почты", err)
+	}
+	email.SetMailer(mailer)
+	checker.Add("smtp", func(ctx context.Context) error { return email.Ping(ctx, mailer) })
+
+	hotels.SetWebDAVConfig(hotels.WebDAVConfig{
+		Endpoint:  cfg.WebDAV.Endpoint,
+		Username:  cfg.WebDAV.Username,
+		Password:  cfg.WebDAV.Password,
+		PublicURL: cfg.WebDAV.PublicURL,
+	})
+	if cfg.WebDAV.Enabled() {
+		checker.Add("storage", hotels.CheckStorage)
+	}
 
-	// Выполнение миграций
-	err := storage.DB.AutoMigrate(&users.User{}, &hotels.Favorite{}, &hotels.Hotel{}, &hotels.Room{}, &hotels.HotelRating{}, &hotels.RoomRating{}, &hotels.RoomImage{}, &bookings.Booking{})
+	r, err := newRouter(cfg.Server, handlers)
 	if err != nil {
-		log.Fatal("Ошибка миграции:", err)
+		fatal("Ошибка настройки доверенных прокси", err)
 	}
 
-	r := gin.Default()
+	expiryScheduler := bookings.NewExpiryScheduler(handlers.bookings.Bookings, handlers.bookings.Users, cfg.Booking.ExpiryInterval())
+	expiryScheduler.Start()
+
+	outboxDispatcher := outbox.NewDispatcher(handlers.outbox.Messages, email.SendEmail, outbox.Config{
+		Interval:    cfg.Outbox.Interval(),
+		MaxAttempts: cfg.Outbox.MaxAttempts,
+	})
+	outboxDispatcher.Start()
+
+	srv := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           r,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout(),
+		ReadTimeout:       cfg.Server.ReadTimeout(),
+		WriteTimeout:      cfg.Server.WriteTimeout(),
+		IdleTimeout:       cfg.Server.IdleTimeout(),
+	}
+	go func() {
+		slog.Info("Запуск сервера", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("Ошибка запуска сервера", err)
+		}
+	}()
+
+	// Ожидаем сигнал завершения и останавливаем сервер, планировщик и диспетчер outbox
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+	slog.Info("Остановка сервера")
+
+	// /readyz сразу отвечает 503, запросы, которые уже выполняются, дорабатывают
+	checker.Drain()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout())
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Error("Ошибка остановки сервера", logging.Err(err))
+	}
+	if err := expiryScheduler.Stop(ctx); err != nil {
+		slog.Error("Ошибка остановки планировщика бронирований", logging.Err(err))
+	}
+	if err := outboxDispatcher.Stop(ctx); err != nil {
+		slog.Error("Ошибка остановки диспетчера outbox", logging.Err(err))
+	}
+	if err := mailer.Close(); err != nil {
+		slog.Error("Ошибка закрытия почтового соединения", logging.Err(err))
+	}
+}
+
+// newRouter собирает gin с middleware и всеми маршрутами API.
+// Журнал запросов и паники пишутся через slog с ID запроса.
+func newRouter(cfg config.ServerConfig, handlers apiHandlers) (*gin.Engine, error) {
+	r := gin.New()
+	r.Use(logging.Middleware(), logging.Recovery())
+
+	// Адрес клиента берётся из X-Forwarded-For только от доверенных прокси
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, err
+	}
 
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "https://hotel-booking-sandy.vercel.app"}, // Укажи адрес фронтенда React
+		AllowOrigins:     cfg.CORSOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Authorization", "Content-Type"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Authorization", "Content-Type", logging.HeaderRequestID},
+		ExposeHeaders:    []string{"Content-Length", logging.HeaderRequestID},
 		AllowCredentials: true,
 	}))
 
-	{
-		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-
-		r.POST("/auth/register", auth.RegisterHandler)
-		r.POST("/auth/login", auth.LoginHandler)
-
-		r.GET("/hotels", hotels.GetHotelsHandler)
-		r.GET("/rooms", hotels.GetRoomsHandler)
-		r.GET("/rooms/:id/bookings", bookings.GetRoomBookingsHandler)
-
-		r.GET("/email/test", email.SendTestEmailHandler)
-		r.POST("/auth/reset-password-request", auth.ResetPasswordRequestHandler)
-		r.POST("/auth/reset-password", auth.ResetPasswordHandler)
-		r.GET("/auth/verify", auth.VerifyHandler)
-
-		r.GET("hotels/:hotel_id/rate", hotels.GetHotelsRatingsHandler)
-		r.GET("rooms/:id/rate", hotels.GetRoomsRatingsHandler)
-	}
-
-	authorized := r.Group("/")
-	{
-		authorized.Use(auth.AuthMiddleware())
-		authorized.POST("/bookings", bookings.CreateBookingHandler)
-		authorized.GET("/bookings/my", bookings.GetYourBookingsHandler)
-		authorized.POST("/bookings/:id/pay", payments.CreatePaymentHandler)
-		authorized.DELETE("/bookings/:id", bookings.CancelBookingHandler)
-		authorized.POST("/bookings/:id/refund", payments.RefundPaymentHandler)
-		authorized.POST("/favorites/:room_id", hotels.AddToFavoritesHandler)
-		authorized.GET("/favorites", hotels.GetFavoritesHandler)
-		authorized.DELETE("/favorites/:room_id", hotels.RemoveFromFavoritesHandler)
-		authorized.POST("/booking/offline", bookings.CreateOfflineBookingHandler)
-		authorized.POST("/auth/send-verification", auth.SendVerifiHandler)
-		authorized.POST("/hotels/:hotel_id/rate", hotels.RateHotelHandler)
-		authorized.POST("/rooms/:room_id/rate", hotels.RateRoomHandler)
-	}
-	r.POST("/payments/callback", payments.PaymentCallbackHandler)
-
-	owners := authorized.Group("/owners")
-	{
-		owners.POST("/hotels", hotels.CreateHotelHandler)
-		owners.POST("/rooms", hotels.CreateRoomHandler)
-		owners.GET("/hotels", hotels.GetOwnerHotelsHandler)
-		owners.GET("/bookings", bookings.GetOwnerBookingsHandler)
-		owners.PUT("/:id/room", hotels.ChangeRoomHandler)
-		owners.DELETE("/:id/room", hotels.DeleteRoomHandler)
-		owners.GET("/rooms", hotels.GetOwnerRoomsHandler)
-		owners.POST("/rooms/:id/images", hotels.UploadRoomImagesHandler)
-		owners.DELETE("/rooms/:id/images/:image_id", hotels.DeleteRoomImageHandler)
-	}
-
-	admins := authorized.Group("/admin")
-	{
-		admins.GET("/users", users.GetUsersHandler)
-		admins.PUT("/users/:id/role", users.UpdateRoleHandler)
-	}
-
-	if err := r.Run(":8080"); err != nil {
-		log.Fatal("Ошибка запуска сервера:", err)
-	}
+	registerRoutes(r, auth.AuthMiddleware(handlers.auth.Sessions), handlers)
+	return r, nil
+}
+
+// fatal пишет ошибку в журнал и завершает процесс
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.Err(err))
+	os.Exit(1)
 }
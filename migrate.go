@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"hotel-booking/internal/migrations"
+	"hotel-booking/internal/storage"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = "использование: hotel-booking migrate up | down [N] | status"
+
+// runMigrate выполняет подкоманду migrate: up применяет все новые миграции,
+// down откатывает N последних (по умолчанию одну), status показывает состояние схемы
+func runMigrate(args []string) {
+	migrator, err := migrations.New(storage.DB)
+	if err != nil {
+		fatal("Ошибка загрузки миграций", err)
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := migrator.Up()
+		for _, m := range done {
+			fmt.Printf("применена %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fatal("Ошибка миграции", err)
+		}
+		if len(done) == 0 {
+			fmt.Println("схема актуальна")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				fmt.Fprintln(os.Stderr, "число миграций для отката должно быть положительным, получено", args[1])
+				os.Exit(2)
+			}
+		}
+		done, err := migrator.Down(steps)
+		for _, m := range done {
+			fmt.Printf("откачена %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fatal("Ошибка миграции", err)
+		}
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			fatal("Ошибка миграции", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ВЕРСИЯ\tНАЗВАНИЕ\tПРИМЕНЕНА")
+		for _, s := range statuses {
+			appliedAt := "нет"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		w.Flush()
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"hotel-booking/internal/auth"
+	"hotel-booking/internal/bookings"
+	"hotel-booking/internal/email"
+	"hotel-booking/internal/health"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/outbox"
+	"hotel-booking/internal/payments"
+	"hotel-booking/internal/pricing"
+	"hotel-booking/internal/users"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"gorm.io/gorm"
+)
+
+// apiHandlers — обработчики, получающие данные через репозитории
+type apiHandlers struct {
+	auth     *auth.Handler
+	users    *users.Handler
+	hotels   *hotels.Handler
+	bookings *bookings.Handler
+	payments *payments.Handler
+	pricing  *pricing.Handler
+	outbox   *outbox.Handler
+	health   *health.Checker
+}
+
+// newHandlers собирает обработчики поверх репозиториев, хранящих данные в db, и платёжного
+// провайдера provider. Проверки готовности добавляются в health отдельно, по мере настройки зависимостей.
+func newHandlers(db *gorm.DB, checker *health.Checker, provider payments.PaymentProvider, paymentsCfg payments.Config) apiHandlers {
+	userRepo := users.NewGormUserRepo(db)
+	hotelRepo := hotels.NewGormHotelRepo(db)
+	roomRepo := hotels.NewGormRoomRepo(db)
+	bookingRepo := bookings.NewGormBookingRepo(db)
+	priceRepo := pricing.NewGormPriceRepo(db)
+
+	paymentHandler := payments.NewHandler(bookingRepo, roomRepo, payments.NewGormEventRepo(db), provider, paymentsCfg)
+	bookingHandler := bookings.NewHandler(bookingRepo, hotelRepo, roomRepo, userRepo, pricing.Quoter(priceRepo, hotelRepo))
+	bookingHandler.Payments = payments.BookingGateway(paymentHandler)
+
+	return apiHandlers{
+		auth:     auth.NewHandler(userRepo, auth.NewGormSessionRepo(db)),
+		users:    users.NewHandler(userRepo),
+		hotels:   hotels.NewHandler(hotelRepo, roomRepo, hotels.NewGormRatingRepo(db), userRepo),
+		bookings: bookingHandler,
+		payments: paymentHandler,
+		pricing:  pricing.NewHandler(priceRepo, hotelRepo, roomRepo),
+		outbox:   outbox.NewHandler(outbox.NewGormRepo(db)),
+		health:   checker,
+	}
+}
+
+// registerRoutes регистрирует маршруты API. authMiddleware проверяет токен и заполняет
+// user_id и role; права на отдельные маршруты задаются через auth.RequirePermission.
+func registerRoutes(r *gin.Engine, authMiddleware gin.HandlerFunc, h apiHandlers) {
+	{
+		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+		r.GET("/healthz", h.health.HealthzHandler)
+		r.GET("/readyz", h.health.ReadyzHandler)
+
+		r.POST("/auth/register", h.auth.RegisterHandler)
+		r.POST("/auth/login", h.auth.LoginHandler)
+		r.POST("/auth/refresh", h.auth.RefreshHandler)
+		r.POST("/auth/logout", h.auth.LogoutHandler)
+		r.GET("/.well-known/jwks.json", auth.JWKSHandler)
+
+		r.GET("/hotels", h.hotels.GetHotelsHandler)
+		r.GET("/rooms", h.hotels.GetRoomsHandler)
+		r.GET("/rooms/:id/bookings", h.bookings.GetRoomBookingsHandler)
+		r.GET("/rooms/:id/availability", h.bookings.GetRoomAvailabilityHandler)
+		r.GET("/rooms/:id/quote", h.pricing.GetRoomQuoteHandler)
+		r.GET("/rooms/:id/cancellation-policy", h.bookings.GetRoomCancellationPolicyHandler)
+
+		r.POST("/auth/reset-password-request", h.auth.ResetPasswordRequestHandler)
+		r.POST("/auth/reset-password", h.auth.ResetPasswordHandler)
+		r.GET("/auth/verify", h.auth.VerifyHandler)
+
+		r.GET("hotels/:hotel_id/rate", h.hotels.GetHotelsRatingsHandler)
+		r.GET("rooms/:id/rate", h.hotels.GetRoomsRatingsHandler)
+	}
+
+	authorized := r.Group("/")
+	{
+		authorized.Use(authMiddleware)
+		authorized.POST("/bookings", h.bookings.CreateBookingHandler)
+		authorized.GET("/bookings/my", h.bookings.GetYourBookingsHandler)
+		authorized.POST("/bookings/:id/pay", h.payments.CreatePaymentHandler)
+		authorized.DELETE("/bookings/:id", h.bookings.CancelBookingHandler)
+		authorized.GET("/bookings/:id/events", h.bookings.GetBookingEventsHandler)
+		authorized.POST("/bookings/:id/refund", h.payments.RefundPaymentHandler)
+		authorized.POST("/favorites/:room_id", h.hotels.AddToFavoritesHandler)
+		authorized.GET("/favorites", h.hotels.GetFavoritesHandler)
+		authorized.DELETE("/favorites/:room_id", h.hotels.RemoveFromFavoritesHandler)
+		authorized.POST("/booking/offline", auth.RequirePermission(auth.PermOfflineBookings), h.bookings.CreateOfflineBookingHandler)
+		authorized.POST("/auth/send-verification", h.auth.SendVerifiHandler)
+		authorized.POST("/hotels/:hotel_id/rate", h.hotels.RateHotelHandler)
+		authorized.POST("/rooms/:room_id/rate", h.hotels.RateRoomHandler)
+	}
+	r.POST("/payments/callback", h.payments.PaymentCallbackHandler)
+	if _, ok := h.payments.Provider.(*payments.FakeProvider); ok {
+		r.GET("/payments/fake/:id", h.payments.FakeCheckoutHandler)
+	}
+
+	// Владельцы и менеджеры отелей; конкретные действия ограничены матрицей прав
+	owners := authorized.Group("/owners", auth.RequireRole(auth.RoleOwner, auth.RoleManager))
+	{
+		owners.POST("/hotels", auth.RequirePermission(auth.PermManageHotels), h.hotels.CreateHotelHandler)
+		owners.POST("/rooms", auth.RequirePermission(auth.PermManageRooms), h.hotels.CreateRoomHandler)
+		owners.GET("/hotels", auth.RequirePermission(auth.PermManageHotels), h.hotels.GetOwnerHotelsHandler)
+		owners.GET("/hotels/:id/staff", auth.RequirePermission(auth.PermManageStaff), h.hotels.GetHotelStaffHandler)
+		owners.POST("/hotels/:id/staff", auth.RequirePermission(auth.PermManageStaff), h.hotels.AddHotelStaffHandler)
+		owners.DELETE("/hotels/:id/staff/:user_id", auth.RequirePermission(auth.PermManageStaff), h.hotels.RemoveHotelStaffHandler)
+		owners.GET("/bookings", auth.RequirePermission(auth.PermHotelBookings), h.bookings.GetOwnerBookingsHandler)
+		owners.PUT("/bookings/:id/status", auth.RequirePermission(auth.PermBookingStatus), h.bookings.UpdateBookingStatusHandler)
+		owners.PUT("/:id/room", auth.RequirePermission(auth.PermEditRooms), h.hotels.ChangeRoomHandler)
+		owners.DELETE("/:id/room", auth.RequirePermission(auth.PermManageRooms), h.hotels.DeleteRoomHandler)
+		owners.GET("/rooms", auth.RequirePermission(auth.PermEditRooms), h.hotels.GetOwnerRoomsHandler)
+		owners.POST("/rooms/:id/images", auth.RequirePermission(auth.PermEditRooms), h.hotels.UploadRoomImagesHandler)
+		owners.DELETE("/rooms/:id/images/:image_id", auth.RequirePermission(auth.PermEditRooms), h.hotels.DeleteRoomImageHandler)
+		owners.POST("/rooms/:id/price-rules", auth.RequirePermission(auth.PermManageRooms), h.pricing.CreatePriceRuleHandler)
+		owners.GET("/rooms/:id/price-rules", auth.RequirePermission(auth.PermManageRooms), h.pricing.GetPriceRulesHandler)
+		owners.DELETE("/rooms/:id/price-rules/:rule_id", auth.RequirePermission(auth.PermManageRooms), h.pricing.DeletePriceRuleHandler)
+		owners.PUT("/rooms/:id/prices", auth.RequirePermission(auth.PermManageRooms), h.pricing.SetPriceOverridesHandler)
+		owners.GET("/rooms/:id/prices", auth.RequirePermission(auth.PermManageRooms), h.pricing.GetPriceOverridesHandler)
+		owners.DELETE("/rooms/:id/prices/:date", auth.RequirePermission(auth.PermManageRooms), h.pricing.DeletePriceOverrideHandler)
+		owners.PUT("/hotels/:id/cancellation-policy", auth.RequirePermission(auth.PermManageHotels), h.bookings.SetHotelCancellationPolicyHandler)
+		owners.DELETE("/hotels/:id/cancellation-policy", auth.RequirePermission(auth.PermManageHotels), h.bookings.DeleteHotelCancellationPolicyHandler)
+		owners.PUT("/rooms/:id/cancellation-policy", auth.RequirePermission(auth.PermManageRooms), h.bookings.SetRoomCancellationPolicyHandler)
+		owners.DELETE("/rooms/:id/cancellation-policy", auth.RequirePermission(auth.PermManageRooms), h.bookings.DeleteRoomCancellationPolicyHandler)
+	}
+
+	admins := authorized.Group("/admin", auth.RequireRole(auth.RoleAdmin))
+	{
+		admins.GET("/users", auth.RequirePermission(auth.PermManageUsers), h.users.GetUsersHandler)
+		admins.PUT("/users/:id/role", auth.RequirePermission(auth.PermManageUsers), h.users.UpdateRoleHandler)
+		admins.GET("/outbox", auth.RequirePermission(auth.PermManageOutbox), h.outbox.GetOutboxMessagesHandler)
+		admins.POST("/outbox/:id/resend", auth.RequirePermission(auth.PermManageOutbox), h.outbox.ResendOutboxMessageHandler)
+		admins.POST("/mail/test", auth.RequirePermission(auth.PermMailDiagnostics), email.MailTestHandler)
+		admins.GET("/mail/status", auth.RequirePermission(auth.PermMailDiagnostics), h.outbox.GetMailStatusHandler)
+	}
+}
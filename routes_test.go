@@ -0,0 +1,236 @@
+package main
+
+import (
+	"hotel-booking/internal/auth"
+	"hotel-booking/internal/bookings"
+	"hotel-booking/internal/health"
+	"hotel-booking/internal/hotels"
+	"hotel-booking/internal/outbox"
+	"hotel-booking/internal/payments"
+	"hotel-booking/internal/pricing"
+	"hotel-booking/internal/users"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testRoleHeader   = "X-Test-Role"
+	testUnauthorized = "test: требуется авторизация"
+	forbiddenMessage = "Недостаточно прав"
+)
+
+var allRoles = []string{"client", "owner", "manager", "admin"}
+
+// routeAccess — кому доступен маршрут: public — без входа, иначе только ролям roles
+type routeAccess struct {
+	public bool
+	roles  []string
+}
+
+var (
+	public        = routeAccess{public: true}
+	authenticated = routeAccess{roles: allRoles}
+	ownerOnly     = routeAccess{roles: []string{"owner"}}
+	hotelStaff    = routeAccess{roles: []string{"owner", "manager"}}
+	adminOnly     = routeAccess{roles: []string{"admin"}}
+)
+
+// expectedAccess перечисляет все маршруты API. Новый маршрут без записи здесь роняет тест.
+var expectedAccess = map[string]routeAccess{
+	"GET /swagger/*any":                  public,
+	"GET /healthz":                       public,
+	"GET /readyz":                        public,
+	"POST /auth/register":                public,
+	"POST /auth/login":                   public,
+	"POST /auth/refresh":                 public,
+	"POST /auth/logout":                  public,
+	"GET /.well-known/jwks.json":         public,
+	"GET /hotels":                        public,
+	"GET /rooms":                         public,
+	"GET /rooms/:id/bookings":            public,
+	"GET /rooms/:id/availability":        public,
+	"GET /rooms/:id/quote":               public,
+	"GET /rooms/:id/cancellation-policy": public,
+	"POST /auth/reset-password-request":  public,
+	"POST /auth/reset-password":          public,
+	"GET /auth/verify":                   public,
+	"GET /hotels/:hotel_id/rate":         public,
+	"GET /rooms/:id/rate":                public,
+	"POST /payments/callback":            public,
+	"GET /payments/fake/:id":             public,
+
+	"POST /bookings":               authenticated,
+	"GET /bookings/my":             authenticated,
+	"POST /bookings/:id/pay":       authenticated,
+	"DELETE /bookings/:id":         authenticated,
+	"GET /bookings/:id/events":     authenticated,
+	"POST /bookings/:id/refund":    authenticated,
+	"POST /favorites/:room_id":     authenticated,
+	"GET /favorites":               authenticated,
+	"DELETE /favorites/:room_id":   authenticated,
+	"POST /auth/send-verification": authenticated,
+	"POST /hotels/:hotel_id/rate":  authenticated,
+	"POST /rooms/:room_id/rate":    authenticated,
+	"POST /booking/offline":        hotelStaff,
+
+	"POST /owners/hotels":                           ownerOnly,
+	"POST /owners/rooms":                            ownerOnly,
+	"GET /owners/hotels":                            ownerOnly,
+	"GET /owners/hotels/:id/staff":                  ownerOnly,
+	"POST /owners/hotels/:id/staff":                 ownerOnly,
+	"DELETE /owners/hotels/:id/staff/:user_id":      ownerOnly,
+	"GET /owners/bookings":                          hotelStaff,
+	"PUT /owners/bookings/:id/status":               hotelStaff,
+	"PUT /owners/:id/room":                          hotelStaff,
+	"DELETE /owners/:id/room":                       ownerOnly,
+	"GET /owners/rooms":                             hotelStaff,
+	"POST /owners/rooms/:id/images":                 hotelStaff,
+	"DELETE /owners/rooms/:id/images/:image_id":     hotelStaff,
+	"POST /owners/rooms/:id/price-rules":            ownerOnly,
+	"GET /owners/rooms/:id/price-rules":             ownerOnly,
+	"DELETE /owners/rooms/:id/price-rules/:rule_id": ownerOnly,
+	"PUT /owners/rooms/:id/prices":                  ownerOnly,
+	"GET /owners/rooms/:id/prices":                  ownerOnly,
+	"DELETE /owners/rooms/:id/prices/:date":         ownerOnly,
+	"PUT /owners/hotels/:id/cancellation-policy":    ownerOnly,
+	"DELETE /owners/hotels/:id/cancellation-policy": ownerOnly,
+	"PUT /owners/rooms/:id/cancellation-policy":     ownerOnly,
+	"DELETE /owners/rooms/:id/cancellation-policy":  ownerOnly,
+
+	"GET /admin/users":              adminOnly,
+	"PUT /admin/users/:id/role":     adminOnly,
+	"GET /admin/outbox":             adminOnly,
+	"POST /admin/outbox/:id/resend": adminOnly,
+	"POST /admin/mail/test":         adminOnly,
+	"GET /admin/mail/status":        adminOnly,
+}
+
+// testAuthMiddleware заменяет проверку токена: роль берётся из заголовка X-Test-Role
+func testAuthMiddleware(c *gin.Context) {
+	role := c.GetHeader(testRoleHeader)
+	if role == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": testUnauthorized})
+		return
+	}
+	c.Set("user_id", uint(1))
+	c.Set("role", role)
+	c.Next()
+}
+
+// newTestHandlers собирает обработчики поверх пустых репозиториев в памяти
+func newTestHandlers() apiHandlers {
+	userRepo := users.NewMemoryUserRepo()
+	store := hotels.NewMemory()
+	bookingRepo := bookings.NewMemoryBookingRepo(store.Rooms(), userRepo)
+	priceRepo := pricing.NewMemoryPriceRepo()
+	quote := pricing.Quoter(priceRepo, store.Hotels())
+	return apiHandlers{
+		auth:     auth.NewHandler(userRepo, auth.NewMemorySessionRepo(userRepo)),
+		users:    users.NewHandler(userRepo),
+		hotels:   hotels.NewHandler(store.Hotels(), store.Rooms(), store.Ratings(), userRepo),
+		bookings: bookings.NewHandler(bookingRepo, store.Hotels(), store.Rooms(), userRepo, quote),
+		payments: payments.NewHandler(bookingRepo, store.Rooms(), payments.NewMemoryEventRepo(bookingRepo), payments.NewFakeProvider("http://pay.test"), payments.Config{}),
+		pricing:  pricing.NewHandler(priceRepo, store.Hotels(), store.Rooms()),
+		outbox:   outbox.NewHandler(outbox.NewMemoryRepo()),
+		health:   health.NewChecker(time.Second),
+	}
+}
+
+// newTestRouter собирает маршруты без базы данных: обработчики на репозиториях работают
+// с пустыми данными в памяти, остальные падают на обращении к базе, и паника превращается в 500
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, _ interface{}) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	registerRoutes(r, testAuthMiddleware, newTestHandlers())
+	return r
+}
+
+// concretePath подставляет значения вместо параметров маршрута
+func concretePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			segments[i] = "1"
+		case strings.HasPrefix(segment, "*"):
+			segments[i] = "index.html"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func serve(r *gin.Engine, method, path, role string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	if role != "" {
+		req.Header.Set(testRoleHeader, role)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestEveryRouteHasExpectedAccess(t *testing.T) {
+	r := newTestRouter()
+
+	registered := map[string]bool{}
+	for _, route := range r.Routes() {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if _, ok := expectedAccess[key]; !ok {
+			t.Errorf("маршрут %s не описан в expectedAccess", key)
+		}
+	}
+	for key := range expectedAccess {
+		if !registered[key] {
+			t.Errorf("маршрут %s из expectedAccess не зарегистрирован", key)
+		}
+	}
+}
+
+func TestRouteAccessMatrix(t *testing.T) {
+	r := newTestRouter()
+
+	for _, route := range r.Routes() {
+		access, ok := expectedAccess[route.Method+" "+route.Path]
+		if !ok {
+			continue
+		}
+		path := concretePath(route.Path)
+
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			anonymous := serve(r, route.Method, path, "")
+			deniedAnonymously := anonymous.Code == http.StatusUnauthorized && strings.Contains(anonymous.Body.String(), testUnauthorized)
+			if access.public == deniedAnonymously {
+				t.Errorf("без входа: код %d, публичный маршрут: %v", anonymous.Code, access.public)
+			}
+			if access.public {
+				return
+			}
+
+			allowed := map[string]bool{}
+			for _, role := range access.roles {
+				allowed[role] = true
+			}
+			for _, role := range allRoles {
+				w := serve(r, route.Method, path, role)
+				forbidden := w.Code == http.StatusForbidden && strings.Contains(w.Body.String(), forbiddenMessage)
+				if allowed[role] && forbidden {
+					t.Errorf("роль %s: доступ запрещён, ожидался доступ", role)
+				}
+				if !allowed[role] && !forbidden {
+					t.Errorf("роль %s: код %d, ожидался отказ 403", role, w.Code)
+				}
+			}
+		})
+	}
+}